@@ -1,14 +1,27 @@
 package main
 
 import (
+    "context"
+    "flag"
     "github.com/gin-gonic/gin"
     "github.com/joho/godotenv"
     "log"
     "os"
+    "strings"
+    "time"
+    "api_compiladores/src/auth"
     "api_compiladores/src/config"
+    "api_compiladores/src/controllers"
+    "api_compiladores/src/domains"
+    "api_compiladores/src/jobs"
+    "api_compiladores/src/middleware"
+    "api_compiladores/src/pagination"
+    "api_compiladores/src/phone"
+    "api_compiladores/src/reports"
     "api_compiladores/src/routes"
     "api_compiladores/src/utils"
 	"github.com/gin-contrib/cors"
+    "go.mongodb.org/mongo-driver/mongo"
 )
 
 func validationENV(env string, envDefault string) string {
@@ -34,20 +47,269 @@ func main() {
 
     dbName := validationENV(os.Getenv("DB_NAME"), "lexicodb")
 
+    // País que se asume para los celulares sin clave +CC
+    if err := phone.SetDefaultRegion(validationENV(os.Getenv("PHONE_DEFAULT_COUNTRY"), "MX")); err != nil {
+        log.Fatalf("PHONE_DEFAULT_COUNTRY inválido: %v", err)
+    }
+
+    // Tabla de ladas más reciente que la integrada (opcional); se carga antes
+    // de las reglas porque éstas pueden filtrar por estado
+    if ladasFile := os.Getenv("LADAS_FILE"); ladasFile != "" {
+        if err := phone.LoadPlanFile(ladasFile); err != nil {
+            log.Fatalf("Error cargando tabla de ladas: %v", err)
+        }
+    }
+
+    // Comandos que no necesitan la base de datos; import-domains va antes de
+    // cargar las listas para poder crear el archivo la primera vez
+    if len(os.Args) > 1 && os.Args[1] == "import-domains" {
+        importDomains(os.Args[2:])
+        return
+    }
+
+    // Base de dominios local (sufijos públicos y desechables) más reciente que
+    // la integrada (opcional); se actualiza con el comando import-domains
+    for kind, env := range domainFileEnv {
+        path := os.Getenv(env)
+        if path == "" {
+            continue
+        }
+        if err := domains.LoadFile(kind, path); err != nil {
+            log.Fatalf("Error cargando lista de dominios %s: %v", kind, err)
+        }
+    }
+
+    // Reglas de validación declarativas (opcional), se recargan al cambiar el archivo
+    if rulesFile := os.Getenv("VALIDATION_RULES_FILE"); rulesFile != "" {
+        if err := utils.ApplyRulesFile(rulesFile); err != nil {
+            log.Fatalf("Error cargando reglas de validación: %v", err)
+        }
+        reloadInterval, err := time.ParseDuration(validationENV(os.Getenv("VALIDATION_RULES_RELOAD"), "5s"))
+        if err != nil {
+            log.Fatalf("VALIDATION_RULES_RELOAD inválido: %v", err)
+        }
+        utils.WatchRulesFile(rulesFile, reloadInterval)
+    }
+
     config.ConnectDB(uri)
 
 	// Primero obtener la colección
 	clienteCollection := config.GetCollection(dbName, "users")
 
+    // Comandos administrativos: go run app.go <comando>
+    if len(os.Args) > 1 {
+        runCommand(os.Args[1], os.Args[2:], clienteCollection)
+        return
+    }
+
 	// Luego pasarla a la función que carga los usuarios falsos
 	utils.AddManyClientes(clienteCollection)
 
+    // Política para clientes inválidos: store, reject o quarantine
+    if err := controllers.SetDefaultValidationPolicy(validationENV(os.Getenv("VALIDATION_POLICY"), "store")); err != nil {
+        log.Fatal(err)
+    }
+    // PUT crea los clientes inexistentes si PUT_UPSERT=true (o con ?upsert=true)
+    if err := controllers.SetDefaultPutUpsert(validationENV(os.Getenv("PUT_UPSERT"), "false")); err != nil {
+        log.Fatalf("PUT_UPSERT inválido: %v", err)
+    }
+
+    quarantineName := validationENV(os.Getenv("QUARANTINE_COLLECTION"), "users_quarantine")
+    controllers.SetQuarantineCollection(config.GetCollection(dbName, quarantineName))
+
+    // Auditoría de altas, cambios y bajas de clientes
+    auditName := validationENV(os.Getenv("AUDIT_COLLECTION"), "users_audit")
+    controllers.SetAuditCollection(config.GetCollection(dbName, auditName))
+
+    // Trabajos de revalidación de la colección completa
+    jobsName := validationENV(os.Getenv("JOBS_COLLECTION"), "users_jobs")
+    revalidator := jobs.NewRevalidator(clienteCollection, config.GetCollection(dbName, jobsName), config.GetCollection(dbName, auditName))
+    controllers.SetRevalidator(revalidator)
+
+    // Retención de las bajas lógicas antes de que la purga las borre (720h o 30d)
+    if err := controllers.SetPurgeRetention(validationENV(os.Getenv("SOFT_DELETE_RETENTION"), "30d")); err != nil {
+        log.Fatalf("SOFT_DELETE_RETENTION inválido: %v", err)
+    }
+
     r := gin.Default()
 
-    // Habilitar CORS
-    r.Use(cors.Default())
+    // Habilitar CORS; los navegadores necesitan permiso para enviar las
+    // credenciales y las precondiciones, y para leer el ETag y los enlaces de
+    // paginación de la respuesta
+    corsConfig := cors.DefaultConfig()
+    corsConfig.AllowAllOrigins = true
+    corsConfig.AddAllowHeaders("Authorization", middleware.APIKeyHeader, "If-Match", "If-None-Match", controllers.ValidationPolicyHeader)
+    corsConfig.AddExposeHeaders("ETag", "Link")
+    r.Use(cors.New(corsConfig))
 
-    routes.ClienteRoute(r, clienteCollection)
+    // Rechazar operadores de MongoDB, HTML y caracteres de control en la entrada
+    r.Use(middleware.InputSafety())
+
+    // Llave de los cursores de paginación; sin CURSOR_SECRET se genera una
+    // aleatoria y los cursores emitidos dejan de valer al reiniciar
+    cursorSecret := os.Getenv("CURSOR_SECRET")
+    if cursorSecret == "" {
+        log.Println("CURSOR_SECRET no configurado: los cursores de paginación no sobreviven a un reinicio")
+    }
+    cursorSigner, err := pagination.NewSigner([]byte(cursorSecret))
+    if err != nil {
+        log.Fatal(err)
+    }
+    controllers.SetCursorSigner(cursorSigner)
+
+    authenticator := loadAuthenticator()
+    routes.ClienteRoute(r, clienteCollection, authenticator)
+    routes.AdminRoute(r, authenticator)
+    routes.ReportRoute(r, authenticator)
+
+    // Índices de la auditoría, de las bajas lógicas, de los listados y de los
+    // filtros por Errores (después de montar las rutas, que asignan las
+    // colecciones a los controllers)
+    indexCtx, cancelIndex := context.WithTimeout(context.Background(), 30*time.Second)
+    if err := controllers.EnsureAuditIndexes(indexCtx); err != nil {
+        log.Printf("No se pudo crear el índice de auditoría: %v", err)
+    }
+    if err := controllers.EnsureClienteIndexes(indexCtx); err != nil {
+        log.Printf("No se pudieron crear los índices de clientes: %v", err)
+    }
+    if err := revalidator.EnsureIndexes(indexCtx); err != nil {
+        log.Printf("No se pudieron crear los índices de revalidaciones: %v", err)
+    }
+    cancelIndex()
+
+    // Las revalidaciones que quedaron a medias al detenerse el proceso
+    // continúan desde su checkpoint
+    go revalidator.WatchInterrupted(context.Background())
 
     r.Run(":" + port)
-}
\ No newline at end of file
+}
+
+// runCommand ejecuta un comando administrativo en lugar de levantar el servidor
+func runCommand(command string, args []string, clienteCollection *mongo.Collection) {
+    switch command {
+    case "migrate-errores":
+        migrados, err := utils.MigrateErrores(clienteCollection)
+        if err != nil {
+            log.Fatalf("Error migrando Errores: %v", err)
+        }
+        log.Printf("Migración completada: %d documentos actualizados", migrados)
+    case "quality-report":
+        qualityReport(args, clienteCollection)
+    default:
+        log.Fatalf("Comando desconocido: %s (disponibles: migrate-errores, quality-report, import-domains)", command)
+    }
+}
+
+// qualityReport genera el reporte de calidad y lo escribe en CSV o Markdown:
+// go run app.go quality-report [-format csv|markdown] [-top 20] [-o archivo]
+// Siempre se recalcula, y el resultado también renueva el de /api/reports/quality
+func qualityReport(args []string, clienteCollection *mongo.Collection) {
+    flags := flag.NewFlagSet("quality-report", flag.ExitOnError)
+    format := flags.String("format", reports.FormatMarkdown, "formato de salida: csv o markdown")
+    top := flags.Int("top", reports.DefaultTop, "elementos de cada lista del reporte")
+    output := flags.String("o", "", "archivo de salida (por omisión, la salida estándar)")
+    flags.Parse(args)
+
+    if err := reports.CheckFormat(*format); err != nil {
+        log.Fatal(err)
+    }
+    if *top < 1 || *top > reports.MaxTop {
+        log.Fatalf("-top debe ser un número entero del 1 al %d", reports.MaxTop)
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+    defer cancel()
+    report, _, err := reports.LoadQuality(ctx, clienteCollection, *top, true)
+    if err != nil {
+        log.Fatalf("Error generando reporte de calidad: %v", err)
+    }
+
+    if *output == "" {
+        if err := reports.Write(os.Stdout, report, *format); err != nil {
+            log.Fatalf("Error escribiendo reporte: %v", err)
+        }
+        return
+    }
+    file, err := os.Create(*output)
+    if err != nil {
+        log.Fatalf("Error creando %s: %v", *output, err)
+    }
+    if err := reports.Write(file, report, *format); err != nil {
+        file.Close()
+        log.Fatalf("Error escribiendo reporte: %v", err)
+    }
+    if err := file.Close(); err != nil {
+        log.Fatalf("Error escribiendo reporte: %v", err)
+    }
+    log.Printf("Reporte de calidad escrito en %s", *output)
+}
+
+// loadAuthenticator configura los métodos de autenticación:
+//   - JWT_HS256_SECRET_FILE / JWT_RS256_PUBLIC_KEY_FILE: llaves locales para
+//     verificar tokens (JWT_ISSUER y JWT_AUDIENCE son opcionales)
+//   - API_KEYS: llaves estáticas "nombre:rol:llave" separadas por comas
+//   - ADMIN_API_KEYS: llaves con rol admin separadas por comas
+func loadAuthenticator() *auth.Authenticator {
+    var verifier *auth.JWTVerifier
+    hsFile, rsFile := os.Getenv("JWT_HS256_SECRET_FILE"), os.Getenv("JWT_RS256_PUBLIC_KEY_FILE")
+    if hsFile != "" || rsFile != "" {
+        var err error
+        verifier, err = auth.NewJWTVerifier(hsFile, rsFile)
+        if err != nil {
+            log.Fatalf("Error cargando llaves JWT: %v", err)
+        }
+        verifier.Issuer = os.Getenv("JWT_ISSUER")
+        verifier.Audience = os.Getenv("JWT_AUDIENCE")
+    }
+
+    keys, err := auth.ParseAPIKeys(os.Getenv("API_KEYS"))
+    if err != nil {
+        log.Fatalf("API_KEYS inválido: %v", err)
+    }
+    for _, value := range strings.Split(os.Getenv("ADMIN_API_KEYS"), ",") {
+        if strings.TrimSpace(value) == "" {
+            continue
+        }
+        key, err := auth.NewAPIKey("admin", auth.RoleAdmin, value)
+        if err != nil {
+            log.Fatalf("ADMIN_API_KEYS inválido: %v", err)
+        }
+        keys = append(keys, key)
+    }
+
+    // Sin ningún método todas las rutas protegidas responderían 503; es mejor
+    // que el despliegue falle al arrancar (ver .env.example)
+    authenticator := auth.NewAuthenticator(verifier, keys)
+    if !authenticator.Configured() {
+        log.Fatal("No hay métodos de autenticación configurados: define JWT_HS256_SECRET_FILE, JWT_RS256_PUBLIC_KEY_FILE, API_KEYS o ADMIN_API_KEYS")
+    }
+    return authenticator
+}
+
+// Variable de entorno con la ruta de cada lista de la base de dominios
+var domainFileEnv = map[domains.Kind]string{
+    domains.KindSuffixes:   "PUBLIC_SUFFIX_FILE",
+    domains.KindDisposable: "DISPOSABLE_DOMAINS_FILE",
+}
+
+// importDomains valida una lista nueva y la copia a la ruta configurada:
+// go run app.go import-domains <suffixes|disposable> <archivo>
+func importDomains(args []string) {
+    if len(args) != 2 {
+        log.Fatalf("Uso: import-domains <%s|%s> <archivo>", domains.KindSuffixes, domains.KindDisposable)
+    }
+    kind, err := domains.ParseKind(args[0])
+    if err != nil {
+        log.Fatal(err)
+    }
+    destination := os.Getenv(domainFileEnv[kind])
+    if destination == "" {
+        log.Fatalf("Configura %s para importar la lista %s", domainFileEnv[kind], kind)
+    }
+
+    count, err := domains.Import(kind, args[1], destination)
+    if err != nil {
+        log.Fatalf("Error importando lista de dominios: %v", err)
+    }
+    log.Printf("Lista %s importada en %s: %d entradas", kind, destination, count)
+}
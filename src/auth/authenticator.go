@@ -0,0 +1,104 @@
+// auth/authenticator.go
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidAPIKey indica que la llave no coincide con ninguna configurada
+var ErrInvalidAPIKey = errors.New("llave de API inválida")
+
+// APIKey es una llave estática con el nombre que se reporta como Subject
+type APIKey struct {
+	Name string
+	Role Role
+	key  []byte
+}
+
+// NewAPIKey crea una llave; las vacías se rechazan
+func NewAPIKey(name string, role Role, key string) (APIKey, error) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return APIKey{}, fmt.Errorf("la llave %q está vacía", name)
+	}
+	return APIKey{Name: name, Role: role, key: []byte(key)}, nil
+}
+
+// ParseAPIKeys lee llaves con el formato "nombre:rol:llave" separadas por
+// comas, p. ej. "reportes:reader:abc123,backoffice:editor:def456". Las
+// entradas vacías se ignoran.
+func ParseAPIKeys(spec string) ([]APIKey, error) {
+	var keys []APIKey
+	for i, entry := range strings.Split(spec, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		fields := strings.SplitN(entry, ":", 3)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("llave %d: se esperaba nombre:rol:llave", i+1)
+		}
+		role, err := ParseRole(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("llave %d: %w", i+1, err)
+		}
+		key, err := NewAPIKey(strings.TrimSpace(fields[0]), role, fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("llave %d: %w", i+1, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Authenticator resuelve el Principal de una petición con un token JWT o con
+// una llave de API estática. Cualquiera de los dos métodos puede faltar.
+type Authenticator struct {
+	jwt  *JWTVerifier
+	keys []APIKey
+}
+
+// NewAuthenticator recibe el verificador de JWT (nil si no se usa) y las llaves
+func NewAuthenticator(jwt *JWTVerifier, keys []APIKey) *Authenticator {
+	return &Authenticator{jwt: jwt, keys: keys}
+}
+
+// Configured indica si hay al menos un método de autenticación disponible
+func (a *Authenticator) Configured() bool {
+	return a != nil && (a.jwt != nil || len(a.keys) > 0)
+}
+
+// AcceptsJWT indica si hay llaves para verificar tokens
+func (a *Authenticator) AcceptsJWT() bool {
+	return a != nil && a.jwt != nil
+}
+
+// AuthenticateToken verifica un JWT; sin verificador configurado se rechaza
+func (a *Authenticator) AuthenticateToken(token string) (Principal, error) {
+	if !a.AcceptsJWT() {
+		return Principal{}, ErrUnsupportedAlg
+	}
+	claims, err := a.jwt.Verify(token)
+	if err != nil {
+		return Principal{}, err
+	}
+	return Principal{Subject: claims.Subject, Role: claims.HighestRole(), Method: MethodJWT}, nil
+}
+
+// AuthenticateAPIKey busca la llave recibida. Se comparan todas las llaves
+// para no revelar cuál coincidió por el tiempo de respuesta.
+func (a *Authenticator) AuthenticateAPIKey(provided string) (Principal, error) {
+	match := -1
+	for i, key := range a.keys {
+		if subtle.ConstantTimeCompare([]byte(provided), key.key) == 1 {
+			match = i
+		}
+	}
+	if match < 0 {
+		return Principal{}, ErrInvalidAPIKey
+	}
+	key := a.keys[match]
+	return Principal{Subject: key.Name, Role: key.Role, Method: MethodAPIKey}, nil
+}
@@ -0,0 +1,79 @@
+// auth/authenticator_test.go
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	keys, err := ParseAPIKeys("reportes:reader:llave-reader, backoffice:editor:llave-editor,,ops:admin:llave-admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := NewAuthenticator(nil, keys)
+
+	tests := []struct {
+		name        string
+		key         string
+		wantErr     error
+		wantSubject string
+		wantRole    Role
+	}{
+		{"reader", "llave-reader", nil, "reportes", RoleReader},
+		{"editor", "llave-editor", nil, "backoffice", RoleEditor},
+		{"admin", "llave-admin", nil, "ops", RoleAdmin},
+		{"desconocida", "llave-otra", ErrInvalidAPIKey, "", RoleNone},
+		{"prefijo de una válida", "llave-read", ErrInvalidAPIKey, "", RoleNone},
+		{"vacía", "", ErrInvalidAPIKey, "", RoleNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := a.AuthenticateAPIKey(tt.key)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, se esperaba %v", err, tt.wantErr)
+			}
+			if principal.Subject != tt.wantSubject || principal.Role != tt.wantRole {
+				t.Errorf("principal = %s, se esperaba %s/%s", principal, tt.wantSubject, tt.wantRole)
+			}
+			if err == nil && principal.Method != MethodAPIKey {
+				t.Errorf("método = %s, se esperaba %s", principal.Method, MethodAPIKey)
+			}
+		})
+	}
+}
+
+func TestParseAPIKeysErrors(t *testing.T) {
+	for _, spec := range []string{
+		"sin-rol",
+		"nombre:superuser:llave",
+		"nombre:reader:   ",
+		"nombre:none:llave",
+	} {
+		if _, err := ParseAPIKeys(spec); err == nil {
+			t.Errorf("ParseAPIKeys(%q) no devolvió error", spec)
+		}
+	}
+}
+
+func TestAuthenticateToken(t *testing.T) {
+	keys := newTestKeys(t)
+
+	if _, err := NewAuthenticator(nil, nil).AuthenticateToken("a.b.c"); !errors.Is(err, ErrUnsupportedAlg) {
+		t.Errorf("sin verificador: error = %v, se esperaba %v", err, ErrUnsupportedAlg)
+	}
+
+	a := NewAuthenticator(keys.verifier(t, true, false), nil)
+	token := signHS256(t, keys.secret, map[string]interface{}{
+		"sub": "ana", "roles": []string{"reader", "admin", "desconocido"}, "exp": testNow.Add(time.Hour).Unix(),
+	})
+	principal, err := a.AuthenticateToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if principal.Subject != "ana" || principal.Role != RoleAdmin || principal.Method != MethodJWT {
+		t.Errorf("principal = %s, se esperaba ana (admin, jwt)", principal)
+	}
+}
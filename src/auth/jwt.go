@@ -0,0 +1,257 @@
+// auth/jwt.go
+package auth
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Tamaños mínimos de llave; uno menor se rechaza al cargarla
+const (
+	MinHS256SecretBytes = 32
+	MinRSABits          = 2048
+)
+
+// Errores de verificación de un token
+var (
+	ErrMalformedToken   = errors.New("token mal formado")
+	ErrUnsupportedAlg   = errors.New("algoritmo de firma no permitido")
+	ErrInvalidSignature = errors.New("firma inválida")
+	ErrTokenExpired     = errors.New("token expirado")
+	ErrTokenNotYetValid = errors.New("token aún no válido")
+	ErrMissingExpiry    = errors.New("el token no tiene expiración (exp)")
+	ErrInvalidIssuer    = errors.New("emisor (iss) no aceptado")
+	ErrInvalidAudience  = errors.New("audiencia (aud) no aceptada")
+)
+
+// Claims son los campos del token que usa la API. El rol se lee de "role" o,
+// si viene una lista en "roles", se toma el mayor de los reconocidos.
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf"`
+	IssuedAt  int64    `json:"iat"`
+	Role      string   `json:"role"`
+	Roles     []string `json:"roles"`
+}
+
+// audience acepta "aud" como cadena o como arreglo (RFC 7519, sección 4.1.3)
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+// HighestRole devuelve el mayor rol reconocido entre role y roles
+func (c Claims) HighestRole() Role {
+	best := RoleNone
+	for _, name := range append([]string{c.Role}, c.Roles...) {
+		if role, err := ParseRole(name); err == nil && role > best {
+			best = role
+		}
+	}
+	return best
+}
+
+// JWTVerifier valida tokens HS256 y RS256 con llaves locales. Solo se aceptan
+// los algoritmos que tienen llave configurada: el "alg" del encabezado elige
+// la llave, pero nunca permite "none" ni usar la llave pública RSA como
+// secreto HMAC.
+type JWTVerifier struct {
+	secret    []byte
+	publicKey *rsa.PublicKey
+	// Issuer y Audience, si no están vacíos, deben coincidir con el token
+	Issuer   string
+	Audience string
+	// Leeway es la tolerancia de reloj al revisar exp y nbf
+	Leeway time.Duration
+	now    func() time.Time
+}
+
+// NewJWTVerifier carga las llaves desde archivo; cualquiera de las dos rutas
+// puede ir vacía, pero no ambas
+func NewJWTVerifier(hs256SecretFile, rs256PublicKeyFile string) (*JWTVerifier, error) {
+	if hs256SecretFile == "" && rs256PublicKeyFile == "" {
+		return nil, errors.New("se requiere una llave HS256 o RS256")
+	}
+	v := &JWTVerifier{Leeway: 30 * time.Second, now: time.Now}
+
+	if hs256SecretFile != "" {
+		secret, err := LoadHS256Secret(hs256SecretFile)
+		if err != nil {
+			return nil, err
+		}
+		v.secret = secret
+	}
+	if rs256PublicKeyFile != "" {
+		key, err := LoadRS256PublicKey(rs256PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		v.publicKey = key
+	}
+	return v, nil
+}
+
+// LoadHS256Secret lee el secreto compartido; se ignora el salto de línea final
+func LoadHS256Secret(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("leyendo secreto HS256: %w", err)
+	}
+	secret := bytes.TrimRight(data, "\r\n")
+	if len(secret) < MinHS256SecretBytes {
+		return nil, fmt.Errorf("el secreto HS256 de %s debe tener al menos %d bytes", path, MinHS256SecretBytes)
+	}
+	return secret, nil
+}
+
+// LoadRS256PublicKey lee una llave pública RSA en PEM: "PUBLIC KEY" (PKIX),
+// "RSA PUBLIC KEY" (PKCS #1) o un certificado
+func LoadRS256PublicKey(path string) (*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("leyendo llave pública RS256: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s no contiene un bloque PEM", path)
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+			key = cert.PublicKey
+		}
+	default:
+		return nil, fmt.Errorf("%s: bloque PEM %q no soportado", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s no es una llave RSA", path)
+	}
+	if rsaKey.N.BitLen() < MinRSABits {
+		return nil, fmt.Errorf("la llave RSA de %s debe tener al menos %d bits", path, MinRSABits)
+	}
+	return rsaKey, nil
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+}
+
+// Verify revisa la firma y las fechas del token y devuelve sus claims
+func (v *JWTVerifier) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrMalformedToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	switch {
+	case header.Alg == "HS256" && v.secret != nil:
+		mac := hmac.New(sha256.New, v.secret)
+		mac.Write(signed)
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return nil, ErrInvalidSignature
+		}
+	case header.Alg == "RS256" && v.publicKey != nil:
+		digest := sha256.Sum256(signed)
+		if rsa.VerifyPKCS1v15(v.publicKey, crypto.SHA256, digest[:], signature) != nil {
+			return nil, ErrInvalidSignature
+		}
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAlg, header.Alg)
+	}
+
+	// Los claims se leen solo después de verificar la firma
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrMalformedToken
+	}
+	if err := v.validateClaims(&claims); err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
+
+func (v *JWTVerifier) validateClaims(claims *Claims) error {
+	now := v.now()
+	if claims.ExpiresAt == 0 {
+		return ErrMissingExpiry
+	}
+	if now.After(time.Unix(claims.ExpiresAt, 0).Add(v.Leeway)) {
+		return ErrTokenExpired
+	}
+	if claims.NotBefore != 0 && now.Add(v.Leeway).Before(time.Unix(claims.NotBefore, 0)) {
+		return ErrTokenNotYetValid
+	}
+	if v.Issuer != "" && claims.Issuer != v.Issuer {
+		return ErrInvalidIssuer
+	}
+	if v.Audience != "" {
+		found := false
+		for _, aud := range claims.Audience {
+			if aud == v.Audience {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return ErrInvalidAudience
+		}
+	}
+	return nil
+}
+
+func decodeSegment(segment string, target interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
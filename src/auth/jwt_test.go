@@ -0,0 +1,230 @@
+// auth/jwt_test.go
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Instante fijo de las pruebas
+var testNow = time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+
+// testKeys son llaves generadas localmente para firmar tokens
+type testKeys struct {
+	secret    []byte
+	private   *rsa.PrivateKey
+	publicPEM []byte
+}
+
+func newTestKeys(t *testing.T) testKeys {
+	t.Helper()
+	secret := make([]byte, MinHS256SecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		t.Fatal(err)
+	}
+	private, err := rsa.GenerateKey(rand.Reader, MinRSABits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testKeys{
+		secret:    secret,
+		private:   private,
+		publicPEM: pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}),
+	}
+}
+
+// verifier escribe las llaves indicadas en archivos y carga el verificador
+func (k testKeys) verifier(t *testing.T, hs256, rs256 bool) *JWTVerifier {
+	t.Helper()
+	dir := t.TempDir()
+	var hsFile, rsFile string
+	if hs256 {
+		hsFile = filepath.Join(dir, "hs256.key")
+		if err := os.WriteFile(hsFile, append(k.secret, '\n'), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if rs256 {
+		rsFile = filepath.Join(dir, "rs256.pem")
+		if err := os.WriteFile(rsFile, k.publicPEM, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	v, err := NewJWTVerifier(hsFile, rsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.now = func() time.Time { return testNow }
+	return v
+}
+
+func encodeSegment(t *testing.T, value interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// signHS256 firma con HMAC-SHA256 y el secreto dado
+func signHS256(t *testing.T, secret []byte, claims map[string]interface{}) string {
+	t.Helper()
+	signed := encodeSegment(t, jwtHeader{Alg: "HS256", Typ: "JWT"}) + "." + encodeSegment(t, claims)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// signRS256 firma con RSASSA-PKCS1-v1_5 y SHA-256
+func signRS256(t *testing.T, key *rsa.PrivateKey, claims map[string]interface{}) string {
+	t.Helper()
+	signed := encodeSegment(t, jwtHeader{Alg: "RS256", Typ: "JWT"}) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// unsigned arma un token con alg "none" y sin firma
+func unsigned(t *testing.T, claims map[string]interface{}) string {
+	t.Helper()
+	return encodeSegment(t, jwtHeader{Alg: "none", Typ: "JWT"}) + "." + encodeSegment(t, claims) + "."
+}
+
+func claimsFor(role string, exp time.Time) map[string]interface{} {
+	claims := map[string]interface{}{"sub": "ana", "role": role}
+	if !exp.IsZero() {
+		claims["exp"] = exp.Unix()
+	}
+	return claims
+}
+
+func TestJWTVerify(t *testing.T) {
+	keys := newTestKeys(t)
+	valid := claimsFor("editor", testNow.Add(time.Hour))
+
+	tests := []struct {
+		name     string
+		hs256    bool
+		rs256    bool
+		token    string
+		wantErr  error
+		wantRole Role
+	}{
+		{"HS256 válido", true, false, signHS256(t, keys.secret, valid), nil, RoleEditor},
+		{"RS256 válido", false, true, signRS256(t, keys.private, valid), nil, RoleEditor},
+		{"RS256 con ambas llaves", true, true, signRS256(t, keys.private, valid), nil, RoleEditor},
+		{"HS256 con otro secreto", true, false, signHS256(t, []byte("otro-secreto-de-al-menos-32-bytes!"), valid), ErrInvalidSignature, RoleNone},
+		{"RS256 con otra llave", false, true, signRS256(t, newTestKeys(t).private, valid), ErrInvalidSignature, RoleNone},
+
+		// Confusión de algoritmos: un token HS256 firmado con la llave
+		// pública RSA como secreto no se acepta
+		{"HS256 contra llave RS256", false, true, signHS256(t, keys.publicPEM, valid), ErrUnsupportedAlg, RoleNone},
+		{"HS256 firmado con la llave pública", true, true, signHS256(t, keys.publicPEM, valid), ErrInvalidSignature, RoleNone},
+		{"RS256 sin llave RS256", true, false, signRS256(t, keys.private, valid), ErrUnsupportedAlg, RoleNone},
+		{"alg none", true, true, unsigned(t, valid), ErrUnsupportedAlg, RoleNone},
+
+		{"sin exp", true, false, signHS256(t, keys.secret, claimsFor("editor", time.Time{})), ErrMissingExpiry, RoleNone},
+		{"expirado", true, false, signHS256(t, keys.secret, claimsFor("editor", testNow.Add(-time.Minute))), ErrTokenExpired, RoleNone},
+		{"expirado dentro de la tolerancia", true, false, signHS256(t, keys.secret, claimsFor("reader", testNow.Add(-10*time.Second))), nil, RoleReader},
+		{"mal formado", true, true, "abc.def", ErrMalformedToken, RoleNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := keys.verifier(t, tt.hs256, tt.rs256).Verify(tt.token)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, se esperaba %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if claims.Subject != "ana" || claims.HighestRole() != tt.wantRole {
+				t.Errorf("claims = %s/%s, se esperaba ana/%s", claims.Subject, claims.HighestRole(), tt.wantRole)
+			}
+		})
+	}
+}
+
+func TestJWTVerifyIssuerAudience(t *testing.T) {
+	keys := newTestKeys(t)
+	v := keys.verifier(t, true, false)
+	v.Issuer, v.Audience = "https://auth.example.com", "clientes-api"
+
+	base := func() map[string]interface{} {
+		return map[string]interface{}{
+			"sub": "ana", "role": "reader", "exp": testNow.Add(time.Hour).Unix(),
+			"iss": "https://auth.example.com", "aud": []string{"otra-api", "clientes-api"},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		change  func(map[string]interface{})
+		wantErr error
+	}{
+		{"coinciden", func(map[string]interface{}) {}, nil},
+		{"aud como cadena", func(c map[string]interface{}) { c["aud"] = "clientes-api" }, nil},
+		{"otro emisor", func(c map[string]interface{}) { c["iss"] = "https://evil.example.com" }, ErrInvalidIssuer},
+		{"otra audiencia", func(c map[string]interface{}) { c["aud"] = "otra-api" }, ErrInvalidAudience},
+		{"aún no válido", func(c map[string]interface{}) { c["nbf"] = testNow.Add(time.Hour).Unix() }, ErrTokenNotYetValid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := base()
+			tt.change(claims)
+			_, err := v.Verify(signHS256(t, keys.secret, claims))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, se esperaba %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadKeysRejectsWeakKeys(t *testing.T) {
+	dir := t.TempDir()
+
+	short := filepath.Join(dir, "short.key")
+	if err := os.WriteFile(short, []byte("corto"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadHS256Secret(short); err == nil {
+		t.Error("se aceptó un secreto HS256 corto")
+	}
+
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	weakFile := filepath.Join(dir, "weak.pem")
+	pemData := pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&weak.PublicKey)})
+	if err := os.WriteFile(weakFile, pemData, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadRS256PublicKey(weakFile); err == nil {
+		t.Error("se aceptó una llave RSA de 1024 bits")
+	}
+}
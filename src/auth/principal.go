@@ -0,0 +1,71 @@
+// auth/principal.go
+package auth
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Role es el nivel de acceso de un usuario; cada rol incluye los permisos de
+// los anteriores (admin puede todo lo de editor y editor todo lo de reader)
+type Role int
+
+const (
+	RoleNone   Role = iota // autenticado pero sin rol reconocido
+	RoleReader             // consultas de clientes
+	RoleEditor             // alta, modificación y baja de clientes
+	RoleAdmin              // rutas administrativas
+)
+
+var roleNames = map[Role]string{
+	RoleNone:   "none",
+	RoleReader: "reader",
+	RoleEditor: "editor",
+	RoleAdmin:  "admin",
+}
+
+func (r Role) String() string {
+	if name, ok := roleNames[r]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// MarshalText hace que el rol se serialice por nombre en las respuestas JSON
+func (r Role) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// Allows indica si el rol alcanza el nivel requerido
+func (r Role) Allows(required Role) bool {
+	return r >= required
+}
+
+// ParseRole convierte el nombre de un rol; "none" no es un rol asignable
+func ParseRole(value string) (Role, error) {
+	name := strings.ToLower(strings.TrimSpace(value))
+	for role, roleName := range roleNames {
+		if role != RoleNone && roleName == name {
+			return role, nil
+		}
+	}
+	return RoleNone, fmt.Errorf("rol desconocido %q (reader, editor o admin)", value)
+}
+
+// Método con el que se autenticó la petición
+const (
+	MethodJWT    = "jwt"
+	MethodAPIKey = "api_key"
+)
+
+// Principal es el usuario autenticado de la petición. Subject es el "sub"
+// del token o el nombre de la llave de API.
+type Principal struct {
+	Subject string `json:"subject"`
+	Role    Role   `json:"role"`
+	Method  string `json:"method"`
+}
+
+func (p Principal) String() string {
+	return fmt.Sprintf("%s (%s, %s)", p.Subject, p.Role, p.Method)
+}
@@ -0,0 +1,57 @@
+// controllers/admin.controller.go
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"api_compiladores/src/utils"
+)
+
+// Momento en que arrancó el proceso, para reportar el uptime
+var startedAt = time.Now()
+
+// Liveness - El proceso responde; no revisa dependencias para que el
+// orquestador no reinicie el servicio por una caída de MongoDB o Redis
+func Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":    "alive",
+		"uptime":    time.Since(startedAt).Round(time.Second).String(),
+		"timestamp": time.Now().Unix(),
+	})
+}
+
+// Readiness - El servicio puede atender peticiones: MongoDB es obligatorio y
+// Redis solo se reporta, porque sin él se trabaja sin caché
+func Readiness(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	services := map[string]interface{}{}
+	statusCode, status := http.StatusOK, "ready"
+
+	if clienteCollection == nil {
+		services["mongodb"] = map[string]interface{}{"status": "down", "error": "colección no configurada"}
+		statusCode, status = http.StatusServiceUnavailable, "not_ready"
+	} else if err := clienteCollection.Database().Client().Ping(ctx, nil); err != nil {
+		services["mongodb"] = map[string]interface{}{"status": "down", "error": err.Error()}
+		statusCode, status = http.StatusServiceUnavailable, "not_ready"
+	} else {
+		services["mongodb"] = map[string]interface{}{"status": "up"}
+	}
+
+	if err := utils.CheckRedisHealth(); err != nil {
+		services["redis"] = map[string]interface{}{"status": "down", "error": err.Error()}
+	} else {
+		services["redis"] = map[string]interface{}{"status": "up"}
+	}
+
+	c.JSON(statusCode, gin.H{
+		"status":    status,
+		"services":  services,
+		"timestamp": time.Now().Unix(),
+	})
+}
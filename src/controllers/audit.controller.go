@@ -0,0 +1,144 @@
+// controllers/audit.controller.go
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"api_compiladores/src/middleware"
+	"api_compiladores/src/models"
+)
+
+// Registros de historial por petición
+const (
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 500
+)
+
+var auditCollection *mongo.Collection
+
+func SetAuditCollection(c *mongo.Collection) {
+	auditCollection = c
+}
+
+// EnsureAuditIndexes crea el índice con el que se consulta el historial de
+// un cliente, del cambio más reciente al más antiguo
+func EnsureAuditIndexes(ctx context.Context) error {
+	_, err := auditCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "Clave_Cliente", Value: 1}, {Key: "fecha", Value: -1}},
+	})
+	return err
+}
+
+// newAudit arma el registro de un cambio con el actor de la petición
+func newAudit(c *gin.Context, operacion, claveCliente string, antes, despues *models.Cliente) models.Auditoria {
+	return models.Auditoria{
+		Clave_Cliente: claveCliente,
+		Operacion:     operacion,
+		Fecha:         time.Now().UTC(),
+		Antes:         antes,
+		Despues:       despues,
+		Cambios:       models.DiffClientes(antes, despues),
+		Actor:         currentActor(c),
+	}
+}
+
+// currentActor identifica a quien hace la petición; vacío sin autenticación
+func currentActor(c *gin.Context) models.Actor {
+	principal, ok := middleware.CurrentPrincipal(c)
+	if !ok {
+		return models.Actor{}
+	}
+	return models.Actor{
+		Subject: principal.Subject,
+		Role:    principal.Role.String(),
+		Method:  principal.Method,
+	}
+}
+
+// recordAudit guarda el cambio con el actor de la petición. La modificación
+// ya está hecha cuando se llama, así que un fallo se registra en el log pero
+// no cambia la respuesta.
+func recordAudit(c *gin.Context, operacion, claveCliente string, antes, despues *models.Cliente) {
+	insertAudits(newAudit(c, operacion, claveCliente, antes, despues))
+}
+
+func insertAudits(registros ...models.Auditoria) {
+	if auditCollection == nil || len(registros) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	docs := make([]interface{}, len(registros))
+	for i, registro := range registros {
+		docs[i] = registro
+	}
+	if _, err := auditCollection.InsertMany(ctx, docs); err != nil {
+		first := registros[0]
+		log.Printf("Error guardando %d registro(s) de auditoría (%s %s por %s): %v",
+			len(registros), first.Operacion, first.Clave_Cliente, first.Actor.Subject, err)
+	}
+}
+
+// GetClienteHistory - Historial de cambios de un cliente, del más reciente al
+// más antiguo; ?limit= acota los registros (1 a 500)
+func GetClienteHistory(c *gin.Context) {
+	claveCliente := c.Param("Clave_Cliente")
+	if auditCollection == nil {
+		sendErrorResponse(c, http.StatusServiceUnavailable, "La colección de auditoría no está configurada", nil, nil)
+		return
+	}
+
+	limit := defaultHistoryLimit
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxHistoryLimit {
+			sendErrorResponse(c, http.StatusBadRequest, "limit debe ser un número entero del 1 al 500", nil, "50")
+			return
+		}
+		limit = parsed
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "fecha", Value: -1}}).
+		SetLimit(int64(limit))
+	cursor, err := auditCollection.Find(ctx, bson.M{"Clave_Cliente": claveCliente}, findOptions)
+	if err != nil {
+		log.Printf("Error obteniendo historial de %s: %v", claveCliente, err)
+		sendErrorResponse(c, http.StatusInternalServerError, "Error al obtener historial", nil, nil)
+		return
+	}
+	defer cursor.Close(ctx)
+
+	historial := []models.Auditoria{}
+	if err := cursor.All(ctx, &historial); err != nil {
+		log.Printf("Error decodificando historial de %s: %v", claveCliente, err)
+		sendErrorResponse(c, http.StatusInternalServerError, "Error al obtener historial", nil, nil)
+		return
+	}
+
+	if len(historial) == 0 {
+		sendErrorResponse(c, http.StatusNotFound, "El cliente no tiene historial", nil, nil)
+		return
+	}
+
+	meta := &MetaInfo{
+		Limit:  limit,
+		Total:  int64(len(historial)),
+		Source: "database",
+	}
+	sendSuccessResponse(c, http.StatusOK, "Historial obtenido", historial, meta)
+}
@@ -0,0 +1,151 @@
+// controllers/concurrency.controller.go
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"api_compiladores/src/models"
+	"api_compiladores/src/utils"
+)
+
+// clienteETag es la etiqueta fuerte de una versión del cliente
+func clienteETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// setClienteETag agrega el header ETag de la versión del cliente
+func setClienteETag(c *gin.Context, cliente models.Cliente) {
+	c.Header("ETag", clienteETag(cliente.Version))
+}
+
+// ifMatchVersions lee las versiones del header If-Match. any es true si el
+// header no viene o es "*" (basta con que el cliente exista); las etiquetas
+// débiles o ajenas nunca coinciden (RFC 9110, comparación fuerte).
+func ifMatchVersions(c *gin.Context) (versions []int64, any bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return nil, true
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+		if err != nil || version < 0 {
+			continue
+		}
+		versions = append(versions, version)
+	}
+	return versions, false
+}
+
+// versionCondition traduce el header If-Match a una condición sobre
+// "version"; es nil si cualquier versión es válida
+func versionCondition(c *gin.Context) interface{} {
+	versions, any := ifMatchVersions(c)
+	if any {
+		return nil
+	}
+	return versionIn(versions...)
+}
+
+// ifMatchAllows indica si If-Match acepta la versión indicada
+func ifMatchAllows(c *gin.Context, version int64) bool {
+	versions, any := ifMatchVersions(c)
+	if any {
+		return true
+	}
+	for _, v := range versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// versionIn es la condición de "version" para las versiones indicadas
+func versionIn(versions ...int64) bson.M {
+	values := []interface{}{}
+	for _, version := range versions {
+		values = append(values, version)
+		// Los documentos anteriores al contador no tienen el campo (versión 0)
+		if version == 0 {
+			values = append(values, nil)
+		}
+	}
+	return bson.M{"$in": values}
+}
+
+// withVersion agrega la condición de If-Match al filtro de una escritura
+func withVersion(filter bson.M, condition interface{}) bson.M {
+	if condition != nil {
+		filter["version"] = condition
+	}
+	return filter
+}
+
+// respondNoMatch responde a una escritura condicionada que no encontró el
+// documento: 404 si el cliente no existe, 412 con la versión vigente si la
+// condición de If-Match falló y 409 si, sin If-Match, otros editores lo
+// siguieron cambiando en todos los intentos
+func respondNoMatch(ctx context.Context, c *gin.Context, claveCliente string) {
+	var actual models.Cliente
+	err := clienteCollection.FindOne(ctx, activeFilter(bson.M{"Clave_Cliente": claveCliente})).Decode(&actual)
+	if err == mongo.ErrNoDocuments {
+		sendErrorResponse(c, http.StatusNotFound, "Cliente no encontrado", nil, nil)
+		return
+	}
+	if err != nil {
+		log.Printf("Error verificando versión del cliente %s: %v", claveCliente, err)
+		sendErrorResponse(c, http.StatusInternalServerError, "Error interno del servidor", nil, nil)
+		return
+	}
+
+	// La versión que tenía el cliente pudo venir de una entrada de caché
+	// vieja; se descarta para que el siguiente GET lea la vigente
+	if err := utils.InvalidateClienteCache(claveCliente); err != nil {
+		log.Printf("Error invalidando caché para cliente %s: %v", claveCliente, err)
+	}
+
+	setClienteETag(c, actual)
+	if c.GetHeader("If-Match") == "" {
+		sendErrorResponse(c, http.StatusConflict,
+			fmt.Sprintf("El cliente %s cambió durante la escritura; vuelve a intentarlo", claveCliente),
+			map[string]interface{}{"etag": clienteETag(actual.Version)}, nil)
+		return
+	}
+	sendErrorResponse(c, http.StatusPreconditionFailed,
+		fmt.Sprintf("El cliente %s cambió desde que se leyó; vuelve a consultarlo", claveCliente),
+		map[string]interface{}{
+			"if_match": c.GetHeader("If-Match"),
+			"etag":     clienteETag(actual.Version),
+		}, nil)
+}
+
+// notModified indica si If-None-Match ya tiene la versión vigente
+func notModified(c *gin.Context, cliente models.Cliente) bool {
+	header := c.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+	etag := clienteETag(cliente.Version)
+	for _, tag := range strings.Split(header, ",") {
+		// If-None-Match usa comparación débil: W/"3" equivale a "3"
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
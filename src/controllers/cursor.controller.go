@@ -0,0 +1,224 @@
+// controllers/cursor.controller.go
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"api_compiladores/src/models"
+	"api_compiladores/src/pagination"
+	"api_compiladores/src/utils"
+)
+
+// Clientes por página en la paginación por cursor
+const defaultCursorLimit = 100
+
+var cursorSigner *pagination.Signer
+
+// SetCursorSigner fija la llave con la que se firman los cursores
+func SetCursorSigner(signer *pagination.Signer) {
+	cursorSigner = signer
+}
+
+// ListClientes - Recorrer los clientes por cursor (keyset): ?after=<cursor>
+// devuelve los que siguen y ?before=<cursor> los anteriores. A diferencia de
+// /page/:page no usa skip, así que cualquier página cuesta lo mismo. Los
+// enlaces a la página siguiente y anterior van en el header Link (RFC 8288).
+// Acepta además ?sort= y ?fields=, y filtra por el estado de validación con
+// ?valid=, ?error_field= y ?error_code=; cada cursor solo vale para el orden
+// con el que se emitió.
+func ListClientes(c *gin.Context) {
+	after, before := c.Query("after"), c.Query("before")
+	if after != "" && before != "" {
+		sendErrorResponse(c, http.StatusBadRequest, "Usa after o before, no ambos", nil, nil)
+		return
+	}
+
+	opts, err := parseListOptions(c, defaultCursorLimit)
+	if err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, err.Error(), nil, exampleListOptions)
+		return
+	}
+	validation, err := parseValidationQuery(c)
+	if err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, err.Error(), nil, exampleValidationQuery)
+		return
+	}
+
+	direction, token := "after", after
+	if before != "" {
+		direction, token = "before", before
+	}
+	forward := direction == "after"
+
+	var cursor *pagination.Cursor
+	if token != "" {
+		decoded, err := cursorSigner.Decode(token)
+		if err != nil {
+			sendErrorResponse(c, http.StatusBadRequest,
+				"Cursor inválido o expirado; vuelve a empezar desde /api/clientes", nil, nil)
+			return
+		}
+		if decoded.Order != opts.Order.String() {
+			sendErrorResponse(c, http.StatusBadRequest,
+				fmt.Sprintf("El cursor se emitió para sort=%s; usa el mismo orden o vuelve a empezar", decoded.Order), nil, nil)
+			return
+		}
+		cursor = &decoded
+	}
+
+	// Intentar obtener desde caché primero; la llave es el cursor recibido
+	// junto con las opciones y el filtro del listado
+	cacheKey := utils.CursorCacheKey(direction, token, opts.cacheKey()+"|"+validation.cacheKey())
+	if cached, found, err := utils.GetCachedCursorPage(cacheKey); found && err == nil {
+		utils.UpdateCacheStats("hit")
+		data, err := opts.present(cached.Clientes)
+		if err == nil {
+			setPageLinks(c, cached.Next, cached.Prev)
+			meta := &MetaInfo{Limit: opts.Limit, CacheHit: true, Source: "cache"}
+			sendSuccessResponse(c, http.StatusOK, "Clientes obtenidos desde caché", data, meta)
+			return
+		}
+		log.Printf("Error proyectando página por cursor desde caché: %v", err)
+	}
+	utils.UpdateCacheStats("miss")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	filter := activeFilter(validation.filter())
+	if cursor != nil {
+		for key, value := range cursor.Filter(opts.Order, forward) {
+			filter[key] = value
+		}
+	}
+	// Un documento de más indica si hay otra página en esa dirección
+	findOptions := options.Find().SetSort(opts.Order.Sort(!forward)).SetLimit(int64(opts.Limit + 1))
+	if projection := opts.projection(); projection != nil {
+		findOptions.SetProjection(projection)
+	}
+
+	result, err := clienteCollection.Find(ctx, filter, findOptions)
+	if err != nil {
+		log.Printf("Error al obtener clientes por cursor: %v", err)
+		sendErrorResponse(c, http.StatusInternalServerError, "Error al obtener clientes", nil, nil)
+		return
+	}
+	defer result.Close(ctx)
+
+	// Las claves del cursor se leen del documento crudo: un campo ausente se
+	// ordena como null, no como el texto vacío del modelo
+	clientes := []models.Cliente{}
+	var keys [][]interface{}
+	for result.Next(ctx) {
+		var cliente models.Cliente
+		if err := result.Decode(&cliente); err != nil {
+			log.Printf("Error decodificando clientes por cursor: %v", err)
+			sendErrorResponse(c, http.StatusInternalServerError, "Error decodificando clientes", nil, nil)
+			return
+		}
+		clientes = append(clientes, cliente)
+		keys = append(keys, sortKeys(result.Current, opts.Order))
+	}
+	if err := result.Err(); err != nil {
+		log.Printf("Cursor error: %v", err)
+		sendErrorResponse(c, http.StatusInternalServerError, "Error en cursor de clientes", nil, nil)
+		return
+	}
+
+	hasMore := len(clientes) > opts.Limit
+	if hasMore {
+		clientes, keys = clientes[:opts.Limit], keys[:opts.Limit]
+	}
+	if !forward {
+		slices.Reverse(clientes)
+		slices.Reverse(keys)
+	}
+
+	// Hay página siguiente si quedan documentos hacia adelante (o si se
+	// llegó con before) y anterior si quedan hacia atrás (o si se llegó con after)
+	page := utils.CursorPage{Clientes: clientes}
+	if len(clientes) > 0 {
+		last := len(clientes) - 1
+		if (forward && hasMore) || (!forward && cursor != nil) {
+			page.Next = encodeCursor(opts.Order, keys[last], clientes[last])
+		}
+		if (!forward && hasMore) || (forward && cursor != nil) {
+			page.Prev = encodeCursor(opts.Order, keys[0], clientes[0])
+		}
+	}
+
+	data, err := opts.present(clientes)
+	if err != nil {
+		log.Printf("Error proyectando clientes por cursor: %v", err)
+		sendErrorResponse(c, http.StatusInternalServerError, "Error preparando la respuesta", nil, nil)
+		return
+	}
+
+	go func() {
+		if err := utils.CacheCursorPage(cacheKey, page, utils.DefaultTTL); err != nil {
+			log.Printf("Error guardando página por cursor en caché: %v", err)
+		}
+		utils.UpdateCacheStats("set")
+	}()
+
+	setPageLinks(c, page.Next, page.Prev)
+	meta := &MetaInfo{Limit: opts.Limit, Source: "database"}
+	sendSuccessResponse(c, http.StatusOK, "Clientes obtenidos desde base de datos", data, meta)
+}
+
+// sortKeys devuelve el valor de cada campo del orden en el documento; los
+// ausentes quedan en nil
+func sortKeys(raw bson.Raw, order pagination.Order) []interface{} {
+	keys := make([]interface{}, len(order))
+	for i, key := range order {
+		value, err := raw.LookupErr(key.Field)
+		if err != nil {
+			continue
+		}
+		if err := value.Unmarshal(&keys[i]); err != nil {
+			log.Printf("Error leyendo %s para el cursor: %v", key.Field, err)
+		}
+	}
+	return keys
+}
+
+func encodeCursor(order pagination.Order, keys []interface{}, cliente models.Cliente) string {
+	token, err := cursorSigner.Encode(pagination.Cursor{Order: order.String(), Keys: keys, ID: cliente.ID})
+	if err != nil {
+		log.Printf("Error generando cursor para %v: %v", cliente.Clave_Cliente, err)
+		return ""
+	}
+	return token
+}
+
+// setPageLinks agrega el header Link con las páginas vecinas, conservando los
+// demás parámetros de la petición
+func setPageLinks(c *gin.Context, next, prev string) {
+	var links []string
+	for _, link := range []struct{ rel, param, token string }{
+		{"next", "after", next},
+		{"prev", "before", prev},
+	} {
+		if link.token == "" {
+			continue
+		}
+		query := c.Request.URL.Query()
+		query.Del("after")
+		query.Del("before")
+		query.Set(link.param, link.token)
+		links = append(links, fmt.Sprintf(`<%s?%s>; rel="%s"`, c.Request.URL.Path, query.Encode(), link.rel))
+	}
+	if len(links) > 0 {
+		c.Header("Link", strings.Join(links, ", "))
+	}
+}
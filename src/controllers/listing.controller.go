@@ -0,0 +1,165 @@
+// controllers/listing.controller.go
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"api_compiladores/src/models"
+	"api_compiladores/src/pagination"
+)
+
+// Límite máximo de clientes por página en los listados
+const maxListLimit = 1000
+
+// Campos por los que se puede ordenar un listado
+var sortableFields = []string{
+	"Clave_Cliente", "Nombre", "Celular", "Email",
+	"NombreNormalizado", "ApellidoPaterno", "CelularE164", "version",
+}
+
+// Campos que se pueden pedir con fields, con su nombre en el JSON del cliente
+var selectableFields = []string{
+	"id", "Clave_Cliente", "Nombre", "Celular", "Email",
+	"NombreNormalizado", "NombrePila", "ApellidoPaterno", "ApellidoMaterno",
+	"CelularNormalizado", "CelularE164", "CelularNacional", "CelularPais", "Region",
+	"Errores", "Mensajes", "version",
+}
+
+// Orden de los listados cuando no se indica sort
+var defaultOrder = pagination.Order{{Field: "Clave_Cliente"}}
+
+var exampleListOptions = map[string]string{
+	"limit":  "25",
+	"sort":   "Nombre,-Email",
+	"fields": "Nombre,Celular",
+}
+
+// listOptions son el tamaño de página, el orden y los campos de un listado
+type listOptions struct {
+	Limit int
+	Order pagination.Order
+	// Campos pedidos, ordenados y sin repetir; vacío devuelve el documento completo
+	Fields []string
+}
+
+// parseListOptions lee ?limit=, ?sort= y ?fields= contra las listas de
+// campos permitidos
+func parseListOptions(c *gin.Context, defaultLimit int) (listOptions, error) {
+	opts := listOptions{Order: defaultOrder}
+
+	limit, err := parseLimit(c, defaultLimit)
+	if err != nil {
+		return opts, err
+	}
+	opts.Limit = limit
+
+	if value := c.Query("sort"); value != "" {
+		if opts.Order, err = pagination.ParseOrder(value, sortableFields); err != nil {
+			return opts, err
+		}
+	}
+
+	if value := c.Query("fields"); value != "" {
+		seen := make(map[string]bool)
+		for _, field := range strings.Split(value, ",") {
+			field = strings.TrimSpace(field)
+			if !isSelectable(field) {
+				return opts, fmt.Errorf("el campo %q no se puede pedir (permitidos: %s)",
+					field, strings.Join(selectableFields, ", "))
+			}
+			if !seen[field] {
+				seen[field] = true
+				opts.Fields = append(opts.Fields, field)
+			}
+		}
+		sort.Strings(opts.Fields)
+	}
+	return opts, nil
+}
+
+// parseLimit lee ?limit=, del 1 al máximo de los listados
+func parseLimit(c *gin.Context, defaultLimit int) (int, error) {
+	value := c.Query("limit")
+	if value == "" {
+		return defaultLimit, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > maxListLimit {
+		return 0, fmt.Errorf("limit debe ser un número entero del 1 al %d", maxListLimit)
+	}
+	return limit, nil
+}
+
+func isSelectable(field string) bool {
+	for _, selectable := range selectableFields {
+		if field == selectable {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheKey es la forma canónica de las opciones; dos peticiones con las
+// mismas opciones comparten la entrada de caché
+func (o listOptions) cacheKey() string {
+	return fmt.Sprintf("limit=%d|sort=%s|fields=%s", o.Limit, o.Order, strings.Join(o.Fields, ","))
+}
+
+// projection es la proyección de MongoDB de los campos pedidos. Incluye
+// también Clave_Cliente y los campos del orden, con los que se arman los
+// cursores. nil devuelve el documento completo.
+func (o listOptions) projection() bson.M {
+	if len(o.Fields) == 0 {
+		return nil
+	}
+	projection := bson.M{"Clave_Cliente": 1}
+	for _, field := range o.Fields {
+		switch field {
+		case "id":
+			projection["_id"] = 1
+		case "Mensajes":
+			// Mensajes se deriva de Errores al serializar
+			projection["Errores"] = 1
+		default:
+			projection[field] = 1
+		}
+	}
+	for _, field := range o.Order.Fields() {
+		projection[field] = 1
+	}
+	return projection
+}
+
+// present reduce cada cliente a los campos pedidos más Clave_Cliente, que
+// identifica la fila. Sin fields devuelve los clientes completos.
+func (o listOptions) present(clientes []models.Cliente) (interface{}, error) {
+	if len(o.Fields) == 0 {
+		return clientes, nil
+	}
+	rows := make([]map[string]json.RawMessage, 0, len(clientes))
+	for _, cliente := range clientes {
+		data, err := json.Marshal(cliente)
+		if err != nil {
+			return nil, err
+		}
+		var full map[string]json.RawMessage
+		if err := json.Unmarshal(data, &full); err != nil {
+			return nil, err
+		}
+		row := map[string]json.RawMessage{"Clave_Cliente": full["Clave_Cliente"]}
+		for _, field := range o.Fields {
+			if value, ok := full[field]; ok {
+				row[field] = value
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -0,0 +1,313 @@
+// controllers/patch.controller.go
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"api_compiladores/src/models"
+	"api_compiladores/src/patch"
+	"api_compiladores/src/utils"
+)
+
+// Tipos de contenido de PATCH; application/json se trata como merge patch
+const (
+	MergePatchContentType = "application/merge-patch+json"
+	JSONPatchContentType  = "application/json-patch+json"
+)
+
+// Intentos de PATCH sin If-Match cuando otro editor cambia el cliente entre
+// la lectura y la escritura
+const patchMaxAttempts = 3
+
+// Campos que se pueden modificar con PATCH; los derivados (NombrePila,
+// CelularE164, Errores...) se recalculan al revalidar
+var patchableFields = []string{"Nombre", "Celular", "Email"}
+
+var examplePatch = map[string]interface{}{
+	MergePatchContentType: map[string]string{"Celular": "9613214782"},
+	JSONPatchContentType: []map[string]string{
+		{"op": "test", "path": "/Celular", "value": "9613214782"},
+		{"op": "replace", "path": "/Celular", "value": "9617654321"},
+	},
+}
+
+// Campos derivados que se guardan junto con cada campo revalidado
+var derivedFields = map[string]func(cliente *models.Cliente) bson.M{
+	"Nombre": func(cliente *models.Cliente) bson.M {
+		return bson.M{
+			"NombreBusqueda":    cliente.NombreBusqueda,
+			"NombreNormalizado": cliente.NombreNormalizado,
+			"NombrePila":        cliente.NombrePila,
+			"ApellidoPaterno":   cliente.ApellidoPaterno,
+			"ApellidoMaterno":   cliente.ApellidoMaterno,
+		}
+	},
+	"Celular": func(cliente *models.Cliente) bson.M {
+		return bson.M{
+			"CelularNormalizado": cliente.CelularNormalizado,
+			"CelularE164":        cliente.CelularE164,
+			"CelularNacional":    cliente.CelularNacional,
+			"CelularPais":        cliente.CelularPais,
+			"Region":             cliente.Region,
+		}
+	},
+	"Email": func(cliente *models.Cliente) bson.M {
+		return bson.M{"EmailBusqueda": cliente.EmailBusqueda}
+	},
+}
+
+// PatchCliente - Modificar solo los campos enviados, con JSON Merge Patch
+// (RFC 7396) o JSON Patch (RFC 6902). Solo se revalidan los campos que
+// cambian (y los que dependen de ellos); los Errores de los demás se
+// conservan.
+func PatchCliente(c *gin.Context) {
+	claveCliente := c.Param("Clave_Cliente")
+
+	policy, err := resolveValidationPolicy(c)
+	if err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, err.Error(), nil, nil)
+		return
+	}
+
+	contentType := c.ContentType()
+	if contentType != MergePatchContentType && contentType != JSONPatchContentType && contentType != "application/json" {
+		c.Header("Accept-Patch", MergePatchContentType+", "+JSONPatchContentType)
+		sendErrorResponse(c, http.StatusUnsupportedMediaType,
+			fmt.Sprintf("Content-Type %q no soportado para PATCH", contentType), nil, examplePatch)
+		return
+	}
+
+	body, err := c.GetRawData()
+	if err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "No se pudo leer el cuerpo de la petición", err.Error(), examplePatch)
+		return
+	}
+
+	// Se valida el formato antes de consultar la base de datos
+	var mergeDoc interface{}
+	var ops []patch.Operation
+	if contentType == JSONPatchContentType {
+		ops, err = patch.ParseOperations(body)
+	} else {
+		mergeDoc, err = patch.Decode(body)
+		if err == nil {
+			err = checkMergePatch(mergeDoc)
+		}
+	}
+	if err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "Parche inválido", err.Error(), examplePatch)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for attempt := 1; ; attempt++ {
+		var anterior models.Cliente
+		err := clienteCollection.FindOne(ctx, activeFilter(bson.M{"Clave_Cliente": claveCliente})).Decode(&anterior)
+		if err == mongo.ErrNoDocuments {
+			sendErrorResponse(c, http.StatusNotFound, "Cliente no encontrado", nil, nil)
+			return
+		}
+		if err != nil {
+			log.Printf("Error obteniendo cliente %s para PATCH: %v", claveCliente, err)
+			sendErrorResponse(c, http.StatusInternalServerError, "Error interno del servidor", nil, nil)
+			return
+		}
+		if !ifMatchAllows(c, anterior.Version) {
+			respondNoMatch(ctx, c, claveCliente)
+			return
+		}
+
+		// Aplicar el parche sobre los campos modificables
+		var patched interface{}
+		if ops != nil {
+			patched, err = patch.Apply(patchableDocument(anterior), ops)
+		} else {
+			patched = patch.MergePatch(patchableDocument(anterior), mergeDoc)
+		}
+		if err != nil {
+			respondPatchError(c, err)
+			return
+		}
+
+		actualizado := anterior
+		changed, err := applyPatchedFields(&actualizado, patched)
+		if err != nil {
+			sendErrorResponse(c, http.StatusUnprocessableEntity, err.Error(), nil, examplePatch)
+			return
+		}
+		if len(changed) == 0 {
+			setClienteETag(c, anterior)
+			sendSuccessResponse(c, http.StatusOK, "El parche no modifica el cliente", anterior, nil)
+			return
+		}
+
+		revalidated := utils.ValidateClienteFields(&actualizado, changed...)
+
+		// Aplicar la política para clientes inválidos (store, reject o quarantine)
+		if applyValidationPolicy(c, policy, "patch", claveCliente, actualizado) {
+			return
+		}
+
+		set := bson.M{
+			"Errores":  actualizado.Errores,
+			"Mensajes": actualizado.Mensajes,
+		}
+		for _, field := range changed {
+			set[field] = fieldValue(&actualizado, field)
+		}
+		for _, field := range revalidated {
+			if derived, ok := derivedFields[field]; ok {
+				for key, value := range derived(&actualizado) {
+					set[key] = value
+				}
+			}
+		}
+
+		// La escritura exige la versión leída para no perder un cambio
+		// concurrente entre la lectura y el $set
+		filter := activeFilter(bson.M{"Clave_Cliente": claveCliente, "version": versionIn(anterior.Version)})
+		result, err := clienteCollection.UpdateOne(ctx, filter, bson.M{"$set": set, "$inc": bson.M{"version": 1}})
+		if err != nil {
+			log.Printf("Error al aplicar PATCH al cliente %s: %v", claveCliente, err)
+			sendErrorResponse(c, http.StatusInternalServerError, "Error al actualizar cliente", nil, nil)
+			return
+		}
+		if result.MatchedCount == 0 {
+			// Con If-Match el cliente pidió esa versión exacta; sin él se
+			// vuelve a aplicar el parche sobre la versión nueva
+			if c.GetHeader("If-Match") != "" || attempt == patchMaxAttempts {
+				respondNoMatch(ctx, c, claveCliente)
+				return
+			}
+			continue
+		}
+
+		actualizado.Version = anterior.Version + 1
+		recordAudit(c, models.AuditUpdate, claveCliente, &anterior, &actualizado)
+
+		// Síncrono, igual que en UpdateCliente, para no servir un ETag viejo
+		if err := utils.InvalidateClienteCache(claveCliente); err != nil {
+			log.Printf("Error invalidando caché para cliente %s: %v", claveCliente, err)
+		}
+		utils.UpdateCacheStats("invalidate")
+		go utils.CacheSingleCliente(claveCliente, actualizado, utils.DefaultTTL)
+
+		setClienteETag(c, actualizado)
+		sendSuccessResponse(c, http.StatusOK, "Cliente actualizado exitosamente", actualizado, nil)
+		return
+	}
+}
+
+// patchableDocument es la vista JSON del cliente sobre la que se aplica el parche
+func patchableDocument(cliente models.Cliente) map[string]interface{} {
+	doc := make(map[string]interface{}, len(patchableFields))
+	for _, field := range patchableFields {
+		doc[field] = fieldValue(&cliente, field)
+	}
+	return doc
+}
+
+func fieldValue(cliente *models.Cliente, field string) string {
+	switch field {
+	case "Nombre":
+		return cliente.Nombre
+	case "Celular":
+		return cliente.Celular
+	case "Email":
+		return cliente.Email
+	}
+	return ""
+}
+
+func isPatchable(field string) bool {
+	for _, patchable := range patchableFields {
+		if field == patchable {
+			return true
+		}
+	}
+	return false
+}
+
+// checkMergePatch rechaza los merge patches que no son objeto o que tocan
+// campos no modificables
+func checkMergePatch(doc interface{}) error {
+	object, ok := doc.(map[string]interface{})
+	if !ok {
+		return errors.New("un merge patch debe ser un objeto JSON")
+	}
+	for field := range object {
+		if !isPatchable(field) {
+			return fmt.Errorf("el campo %q no se puede modificar (modificables: %v)", field, patchableFields)
+		}
+	}
+	return nil
+}
+
+// applyPatchedFields copia al cliente los campos del documento parchado y
+// devuelve los que cambiaron. Un campo eliminado queda vacío, como al
+// omitirlo en PUT.
+func applyPatchedFields(cliente *models.Cliente, patched interface{}) ([]string, error) {
+	doc, ok := patched.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("el parche debe dejar un objeto JSON")
+	}
+	for field := range doc {
+		if !isPatchable(field) {
+			return nil, fmt.Errorf("el campo %q no se puede modificar (modificables: %v)", field, patchableFields)
+		}
+	}
+
+	var changed []string
+	for _, field := range patchableFields {
+		var value string
+		switch v := doc[field].(type) {
+		case nil:
+		case string:
+			value = v
+		default:
+			return nil, fmt.Errorf("el campo %s debe ser texto", field)
+		}
+		if value == fieldValue(cliente, field) {
+			continue
+		}
+		switch field {
+		case "Nombre":
+			cliente.Nombre = value
+		case "Celular":
+			cliente.Celular = value
+		case "Email":
+			cliente.Email = value
+		}
+		changed = append(changed, field)
+	}
+	return changed, nil
+}
+
+// respondPatchError traduce los errores de JSON Patch: una prueba fallida es
+// un conflicto con el estado actual (409) y los demás, un parche que no se
+// puede aplicar a este documento (422, RFC 5789)
+func respondPatchError(c *gin.Context, err error) {
+	status := http.StatusUnprocessableEntity
+	message := "No se pudo aplicar el parche"
+	if errors.Is(err, patch.ErrTestFailed) {
+		status = http.StatusConflict
+		message = "Una operación test del parche no coincidió con el cliente"
+	}
+	var opErr *patch.OpError
+	if errors.As(err, &opErr) {
+		sendErrorResponse(c, status, message, opErr, nil)
+		return
+	}
+	sendErrorResponse(c, status, message, err.Error(), nil)
+}
@@ -0,0 +1,123 @@
+// controllers/policy.controller.go
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"api_compiladores/src/models"
+)
+
+// ValidationPolicy define qué hacer con un cliente que no pasa la validación
+type ValidationPolicy string
+
+const (
+	// Guardar el cliente con sus Errores (comportamiento histórico)
+	PolicyStore ValidationPolicy = "store"
+	// Rechazar la petición con 422 y los errores estructurados
+	PolicyReject ValidationPolicy = "reject"
+	// Guardar el cliente en la colección de cuarentena
+	PolicyQuarantine ValidationPolicy = "quarantine"
+)
+
+// Header y query param para sobrescribir la política en una petición
+const (
+	ValidationPolicyHeader = "X-Validation-Policy"
+	ValidationPolicyQuery  = "policy"
+)
+
+var (
+	defaultValidationPolicy = PolicyStore
+	quarantineCollection    *mongo.Collection
+)
+
+func SetQuarantineCollection(c *mongo.Collection) {
+	quarantineCollection = c
+}
+
+// ParseValidationPolicy convierte el texto recibido en una política válida
+func ParseValidationPolicy(value string) (ValidationPolicy, error) {
+	switch policy := ValidationPolicy(strings.ToLower(strings.TrimSpace(value))); policy {
+	case PolicyStore, PolicyReject, PolicyQuarantine:
+		return policy, nil
+	}
+	return "", fmt.Errorf("política de validación inválida: %q (valores permitidos: store, reject, quarantine)", value)
+}
+
+// SetDefaultValidationPolicy fija la política global (variable VALIDATION_POLICY)
+func SetDefaultValidationPolicy(value string) error {
+	policy, err := ParseValidationPolicy(value)
+	if err != nil {
+		return err
+	}
+	defaultValidationPolicy = policy
+	return nil
+}
+
+// resolveValidationPolicy obtiene la política de la petición: query param,
+// luego header y por último la política global
+func resolveValidationPolicy(c *gin.Context) (ValidationPolicy, error) {
+	if value := c.Query(ValidationPolicyQuery); value != "" {
+		return ParseValidationPolicy(value)
+	}
+	if value := c.GetHeader(ValidationPolicyHeader); value != "" {
+		return ParseValidationPolicy(value)
+	}
+	return defaultValidationPolicy, nil
+}
+
+// applyValidationPolicy aplica la política a un cliente ya validado. Devuelve
+// true si la respuesta ya se envió y el handler no debe persistir el cliente.
+func applyValidationPolicy(c *gin.Context, policy ValidationPolicy, operacion, claveCliente string, cliente models.Cliente) bool {
+	if !cliente.Errores.HasErrors() {
+		return false
+	}
+
+	switch policy {
+	case PolicyReject:
+		sendErrorResponse(c, http.StatusUnprocessableEntity, "El cliente tiene errores de validación",
+			map[string]interface{}{
+				"Errores":  cliente.Errores,
+				"Mensajes": cliente.Errores.Messages(),
+			}, nil)
+		return true
+
+	case PolicyQuarantine:
+		if quarantineCollection == nil {
+			sendErrorResponse(c, http.StatusServiceUnavailable, "La colección de cuarentena no está configurada", nil, nil)
+			return true
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		registro := models.ClienteCuarentena{
+			Clave_Cliente: claveCliente,
+			Operacion:     operacion,
+			Cliente:       cliente,
+			Fecha:         time.Now(),
+		}
+		result, err := quarantineCollection.InsertOne(ctx, registro)
+		if err != nil {
+			log.Printf("Error al guardar cliente %s en cuarentena: %v", claveCliente, err)
+			sendErrorResponse(c, http.StatusInternalServerError, "Error al guardar cliente en cuarentena", nil, nil)
+			return true
+		}
+		if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+			registro.ID = id
+		}
+
+		sendSuccessResponse(c, http.StatusAccepted, "Cliente enviado a cuarentena por errores de validación", registro, nil)
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,310 @@
+// controllers/quality.controller.go
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/sync/singleflight"
+
+	"api_compiladores/src/auth"
+	"api_compiladores/src/middleware"
+	"api_compiladores/src/models"
+	"api_compiladores/src/utils"
+)
+
+// Tiempo máximo del conteo de errores por campo y código
+const errorSummaryMaxTime = 30 * time.Second
+
+var errorCodeRegex = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+var exampleValidationQuery = map[string]string{
+	"valid":       "false",
+	"error_field": "Celular",
+	"error_code":  "CELULAR_BAD_PREFIX",
+}
+
+// validationQuery filtra los clientes por el contenido de Errores. Cada
+// parámetro es una condición independiente:
+//   - valid=false: algún campo tiene un error con severidad "error"
+//     (valid=true: ninguno; las advertencias no invalidan). Solo valid=false
+//     usa los índices de severidad
+//   - error_field=Celular: el campo tiene algún error o advertencia
+//   - error_code=X: algún campo (o error_field, si viene) tiene el código X
+type validationQuery struct {
+	Valid *bool
+	Field string
+	Code  string
+}
+
+func parseValidationQuery(c *gin.Context) (validationQuery, error) {
+	var query validationQuery
+	if value := c.Query("valid"); value != "" {
+		valid, err := strconv.ParseBool(value)
+		if err != nil {
+			return query, fmt.Errorf("valid debe ser true o false")
+		}
+		query.Valid = &valid
+	}
+	if query.Field = c.Query("error_field"); query.Field != "" && !isValidatedField(query.Field) {
+		return query, fmt.Errorf("error_field %q no es un campo validado (campos: %s)",
+			query.Field, strings.Join(utils.ValidatedFields(), ", "))
+	}
+	if query.Code = c.Query("error_code"); query.Code != "" && !errorCodeRegex.MatchString(query.Code) {
+		return query, fmt.Errorf("error_code %q no es un código de validación", query.Code)
+	}
+	return query, nil
+}
+
+func isValidatedField(field string) bool {
+	for _, validated := range utils.ValidatedFields() {
+		if field == validated {
+			return true
+		}
+	}
+	return false
+}
+
+// filter devuelve las condiciones en un $and, que se combina sin choques con
+// el $or del cursor; nil si no hay filtro
+func (q validationQuery) filter() bson.M {
+	var conditions bson.A
+	if q.Valid != nil {
+		invalid := invalidFilter(utils.ValidatedFields()...)
+		if *q.Valid {
+			// La negación no puede usar los índices de severidad: valid=true
+			// recorre el índice del orden de la página descartando los
+			// inválidos, que pueden ser muchos antes de llenarla
+			invalid = bson.M{"$nor": invalid["$or"]}
+		}
+		conditions = append(conditions, invalid)
+	}
+
+	switch {
+	case q.Code != "" && q.Field != "":
+		conditions = append(conditions, bson.M{"Errores." + q.Field + ".code": q.Code})
+	case q.Code != "":
+		var fields bson.A
+		for _, field := range utils.ValidatedFields() {
+			fields = append(fields, bson.M{"Errores." + field + ".code": q.Code})
+		}
+		conditions = append(conditions, bson.M{"$or": fields})
+	case q.Field != "":
+		conditions = append(conditions, bson.M{"Errores." + q.Field + ".code": bson.M{"$exists": true}})
+	}
+
+	if len(conditions) == 0 {
+		return nil
+	}
+	return bson.M{"$and": conditions}
+}
+
+// cacheKey es la forma canónica del filtro para las llaves de caché
+func (q validationQuery) cacheKey() string {
+	valid := ""
+	if q.Valid != nil {
+		valid = strconv.FormatBool(*q.Valid)
+	}
+	return fmt.Sprintf("valid=%s|error_field=%s|error_code=%s", valid, q.Field, q.Code)
+}
+
+// invalidFilter es la condición de los clientes con algún error (no
+// advertencia) en alguno de los campos
+func invalidFilter(fields ...string) bson.M {
+	var branches bson.A
+	for _, field := range fields {
+		branches = append(branches, bson.M{"Errores." + field + ".severity": models.SeverityError})
+	}
+	return bson.M{"$or": branches}
+}
+
+// errorIndexes son los índices multikey con los que se filtra por severidad
+// y por código de cada campo validado
+func errorIndexes() []mongo.IndexModel {
+	var indexes []mongo.IndexModel
+	for _, field := range utils.ValidatedFields() {
+		indexes = append(indexes,
+			mongo.IndexModel{Keys: bson.D{{Key: "Errores." + field + ".severity", Value: 1}}},
+			mongo.IndexModel{Keys: bson.D{{Key: "Errores." + field + ".code", Value: 1}}},
+		)
+	}
+	return indexes
+}
+
+// ErrorCount es el número de clientes con un código de error en un campo
+type ErrorCount struct {
+	Code     string `json:"code" bson:"code"`
+	Severity string `json:"severity" bson:"severity"`
+	Clientes int64  `json:"clientes" bson:"clientes"`
+}
+
+// FieldErrorSummary resume los errores de un campo validado
+type FieldErrorSummary struct {
+	Field   string       `json:"field"`
+	Invalid int64        `json:"invalid"`
+	Codes   []ErrorCount `json:"codes"`
+}
+
+// ErrorSummary es el conteo de clientes inválidos por campo y por código
+type ErrorSummary struct {
+	GeneratedAt     time.Time           `json:"generated_at"`
+	TotalClientes   int64               `json:"total_clientes"`
+	InvalidClientes int64               `json:"invalid_clientes"`
+	Fields          []FieldErrorSummary `json:"fields"`
+}
+
+// Nombre del resumen en caché, bajo el prefijo stats: igual que el reporte
+// de calidad
+const errorSummaryCacheName = "report:error_summary"
+
+// Cálculo del resumen en curso; las peticiones que llegan mientras se
+// calcula esperan ese resultado
+var errorSummaryBuilds singleflight.Group
+
+// GetErrorSummary - Contar los clientes inválidos por campo y por regla
+// (código de error), para priorizar la limpieza sin exportar la colección.
+// El conteo recorre toda la colección, así que se guarda en caché;
+// ?refresh=true lo recalcula y requiere rol admin.
+func GetErrorSummary(c *gin.Context) {
+	refresh, err := strconv.ParseBool(c.DefaultQuery("refresh", "false"))
+	if err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "refresh debe ser true o false", nil, "true")
+		return
+	}
+	if principal, _ := middleware.CurrentPrincipal(c); refresh && !principal.Role.Allows(auth.RoleAdmin) {
+		sendErrorResponse(c, http.StatusForbidden, "refresh=true requiere el rol admin", nil, nil)
+		return
+	}
+
+	meta := &MetaInfo{Source: "cache", CacheHit: true, Timestamp: time.Now().Unix()}
+	var summary ErrorSummary
+	if found, err := utils.GetCachedStats(errorSummaryCacheName, &summary); refresh || !found || err != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*errorSummaryMaxTime)
+		defer cancel()
+
+		built, err, _ := errorSummaryBuilds.Do(errorSummaryCacheName, func() (interface{}, error) {
+			summary, err := buildErrorSummary(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if err := utils.CacheStats(errorSummaryCacheName, summary, utils.LongTTL); err != nil {
+				log.Printf("Error guardando resumen de errores en caché: %v", err)
+			}
+			return summary, nil
+		})
+		if mongo.IsTimeout(err) {
+			sendErrorResponse(c, http.StatusServiceUnavailable, "El resumen de errores tardó demasiado; intenta más tarde", nil, nil)
+			return
+		}
+		if err != nil {
+			log.Printf("Error obteniendo resumen de errores: %v", err)
+			sendErrorResponse(c, http.StatusInternalServerError, "Error obteniendo resumen de errores", nil, nil)
+			return
+		}
+		summary = *built.(*ErrorSummary)
+		meta.Source, meta.CacheHit = "database", false
+		utils.UpdateCacheStats("miss")
+	} else {
+		utils.UpdateCacheStats("hit")
+	}
+
+	meta.Total = summary.TotalClientes
+	sendSuccessResponse(c, http.StatusOK, "Resumen de errores obtenido exitosamente", summary, meta)
+}
+
+// buildErrorSummary cuenta los clientes activos, los inválidos en total y
+// por campo, y los que tiene cada código de cada campo
+func buildErrorSummary(ctx context.Context) (*ErrorSummary, error) {
+	fields := utils.ValidatedFields()
+	total, err := clienteCollection.CountDocuments(ctx, activeFilter(nil))
+	if err != nil {
+		return nil, fmt.Errorf("error contando clientes: %w", err)
+	}
+	invalid, err := clienteCollection.CountDocuments(ctx, activeFilter(invalidFilter(fields...)))
+	if err != nil {
+		return nil, fmt.Errorf("error contando clientes inválidos: %w", err)
+	}
+
+	summaries := make([]FieldErrorSummary, 0, len(fields))
+	byField := make(map[string]*FieldErrorSummary, len(fields))
+	for _, field := range fields {
+		count, err := clienteCollection.CountDocuments(ctx, activeFilter(invalidFilter(field)))
+		if err != nil {
+			return nil, fmt.Errorf("error contando clientes inválidos en %s: %w", field, err)
+		}
+		summaries = append(summaries, FieldErrorSummary{Field: field, Invalid: count, Codes: []ErrorCount{}})
+	}
+	for i := range summaries {
+		byField[summaries[i].Field] = &summaries[i]
+	}
+
+	// Un cliente cuenta una sola vez por código aunque lo repita en varias
+	// posiciones del campo
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: activeFilter(bson.M{"Errores": bson.M{"$type": "object"}})}},
+		{{Key: "$project", Value: bson.M{"errores": bson.M{"$objectToArray": "$Errores"}}}},
+		{{Key: "$unwind", Value: "$errores"}},
+		{{Key: "$unwind", Value: "$errores.v"}},
+		{{Key: "$group", Value: bson.M{"_id": bson.M{
+			"field":    "$errores.k",
+			"code":     "$errores.v.code",
+			"severity": "$errores.v.severity",
+			"cliente":  "$_id",
+		}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":      bson.M{"field": "$_id.field", "code": "$_id.code", "severity": "$_id.severity"},
+			"clientes": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "clientes", Value: -1}, {Key: "_id.code", Value: 1}}}},
+	}
+	cursor, err := clienteCollection.Aggregate(ctx, pipeline,
+		options.Aggregate().SetAllowDiskUse(true).SetMaxTime(errorSummaryMaxTime))
+	if err != nil {
+		return nil, fmt.Errorf("error agregando errores por código: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var row struct {
+			ID struct {
+				Field    string `bson:"field"`
+				Code     string `bson:"code"`
+				Severity string `bson:"severity"`
+			} `bson:"_id"`
+			Clientes int64 `bson:"clientes"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			log.Printf("Error decodificando resumen de errores: %v", err)
+			continue
+		}
+		summary, ok := byField[row.ID.Field]
+		if !ok {
+			continue
+		}
+		// Los mensajes históricos sin migrar no tienen código
+		if row.ID.Code == "" {
+			row.ID.Code, row.ID.Severity = models.LegacyCode, models.SeverityError
+		}
+		summary.Codes = append(summary.Codes, ErrorCount{Code: row.ID.Code, Severity: row.ID.Severity, Clientes: row.Clientes})
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("error recorriendo resumen de errores: %w", err)
+	}
+
+	return &ErrorSummary{
+		GeneratedAt:     time.Now().UTC(),
+		TotalClientes:   total,
+		InvalidClientes: invalid,
+		Fields:          summaries,
+	}, nil
+}
@@ -0,0 +1,57 @@
+// controllers/quality.controller_test.go
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"api_compiladores/src/utils"
+)
+
+func TestGetErrorSummary(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("counts by field and code", func(mt *mtest.T) {
+		useMockCollections(mt)
+		r := gin.New()
+		r.GET("/api/clientes/errors", GetErrorSummary)
+
+		ns := mt.DB.Name() + "." + mt.Coll.Name()
+		count := func(n int64) bson.D {
+			return mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{{Key: "n", Value: n}})
+		}
+		// Total, inválidos y un conteo por campo validado
+		responses := []bson.D{count(10), count(4)}
+		for range utils.ValidatedFields() {
+			responses = append(responses, count(2))
+		}
+		responses = append(responses, mtest.CreateCursorResponse(0, ns, mtest.FirstBatch,
+			bson.D{
+				{Key: "_id", Value: bson.D{{Key: "field", Value: "Celular"}, {Key: "code", Value: "CELULAR_BAD_PREFIX"}, {Key: "severity", Value: "error"}}},
+				{Key: "clientes", Value: int64(2)},
+			}))
+		mt.AddMockResponses(responses...)
+
+		w, _ := serve(r, http.MethodGet, "/api/clientes/errors", "", nil)
+		if w.Code != http.StatusOK {
+			mt.Fatalf("status = %d: %s", w.Code, w.Body.String())
+		}
+		var summary ErrorSummary
+		if err := json.Unmarshal(w.Body.Bytes(), &summary); err != nil {
+			mt.Fatal(err)
+		}
+		if summary.TotalClientes != 10 || summary.InvalidClientes != 4 || len(summary.Fields) != len(utils.ValidatedFields()) {
+			mt.Fatalf("resumen inesperado: %s", w.Body.String())
+		}
+		for _, field := range summary.Fields {
+			if field.Field == "Celular" && (len(field.Codes) != 1 || field.Codes[0].Code != "CELULAR_BAD_PREFIX") {
+				mt.Errorf("códigos de Celular = %v", field.Codes)
+			}
+		}
+	})
+}
@@ -0,0 +1,68 @@
+// controllers/report.controller.go
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"api_compiladores/src/auth"
+	"api_compiladores/src/middleware"
+	"api_compiladores/src/reports"
+	"api_compiladores/src/utils"
+)
+
+// GetQualityReport - Reporte de calidad de datos: porcentaje de válidos,
+// errores más frecuentes, ladas, dominios, duplicados, longitud de nombres y
+// similitud nombre/email. Se guarda en caché; ?refresh=true lo recalcula y
+// requiere rol admin, porque recorre toda la colección.
+func GetQualityReport(c *gin.Context) {
+	top := reports.DefaultTop
+	if value := c.Query("top"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > reports.MaxTop {
+			sendErrorResponse(c, http.StatusBadRequest,
+				fmt.Sprintf("top debe ser un número entero del 1 al %d", reports.MaxTop), nil, "20")
+			return
+		}
+		top = parsed
+	}
+	refresh, err := strconv.ParseBool(c.DefaultQuery("refresh", "false"))
+	if err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "refresh debe ser true o false", nil, "true")
+		return
+	}
+	if principal, _ := middleware.CurrentPrincipal(c); refresh && !principal.Role.Allows(auth.RoleAdmin) {
+		sendErrorResponse(c, http.StatusForbidden, "refresh=true requiere el rol admin", nil, nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	report, cached, err := reports.LoadQuality(ctx, clienteCollection, top, refresh)
+	if err != nil {
+		log.Printf("Error generando reporte de calidad: %v", err)
+		sendErrorResponse(c, http.StatusInternalServerError, "Error generando reporte de calidad", nil, nil)
+		return
+	}
+
+	meta := &MetaInfo{
+		Limit:     top,
+		Total:     report.Total,
+		Source:    "database",
+		Timestamp: time.Now().Unix(),
+	}
+	if cached {
+		utils.UpdateCacheStats("hit")
+		meta.CacheHit, meta.Source = true, "cache"
+	} else {
+		utils.UpdateCacheStats("miss")
+	}
+	sendSuccessResponse(c, http.StatusOK, "Reporte de calidad obtenido exitosamente", report, meta)
+}
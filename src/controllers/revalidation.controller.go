@@ -0,0 +1,170 @@
+// controllers/revalidation.controller.go
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"api_compiladores/src/jobs"
+	"api_compiladores/src/models"
+)
+
+// Trabajos de revalidación por consulta
+const (
+	defaultJobsLimit = 20
+	maxJobsLimit     = 100
+)
+
+var revalidator *jobs.Revalidator
+
+func SetRevalidator(r *jobs.Revalidator) {
+	revalidator = r
+}
+
+// StartRevalidation - Volver a validar todos los clientes con las reglas
+// vigentes en segundo plano (?batch_size=100 a 5000). Responde 202 con el
+// trabajo; su avance se consulta en la URL del encabezado Location.
+func StartRevalidation(c *gin.Context) {
+	batchSize := jobs.DefaultBatchSize
+	if value := c.Query("batch_size"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < jobs.MinBatchSize || parsed > jobs.MaxBatchSize {
+			sendErrorResponse(c, http.StatusBadRequest,
+				fmt.Sprintf("batch_size debe ser un número entero del %d al %d", jobs.MinBatchSize, jobs.MaxBatchSize), nil, "1000")
+			return
+		}
+		batchSize = parsed
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	job, err := revalidator.Start(ctx, batchSize, currentActor(c))
+	if err != nil {
+		sendJobError(c, err, "Error al iniciar la revalidación")
+		return
+	}
+	sendJobAccepted(c, job, "Revalidación iniciada")
+}
+
+// GetRevalidationJobs - Listar los trabajos de revalidación más recientes
+func GetRevalidationJobs(c *gin.Context) {
+	limit := defaultJobsLimit
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxJobsLimit {
+			sendErrorResponse(c, http.StatusBadRequest,
+				fmt.Sprintf("limit debe ser un número entero del 1 al %d", maxJobsLimit), nil, "20")
+			return
+		}
+		limit = parsed
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	trabajos, err := revalidator.List(ctx, int64(limit))
+	if err != nil {
+		sendJobError(c, err, "Error al obtener las revalidaciones")
+		return
+	}
+	meta := &MetaInfo{
+		Limit:     limit,
+		Total:     int64(len(trabajos)),
+		Source:    "database",
+		Timestamp: time.Now().Unix(),
+	}
+	sendSuccessResponse(c, http.StatusOK, "Revalidaciones obtenidas exitosamente", trabajos, meta)
+}
+
+// GetRevalidationJob - Consultar el avance de un trabajo de revalidación
+func GetRevalidationJob(c *gin.Context) {
+	id, ok := jobID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	job, err := revalidator.Get(ctx, id)
+	if err != nil {
+		sendJobError(c, err, "Error al obtener la revalidación")
+		return
+	}
+	sendSuccessResponse(c, http.StatusOK, "Revalidación obtenida exitosamente", job, nil)
+}
+
+// ResumeRevalidation - Reanudar desde su checkpoint un trabajo cancelado,
+// fallido o interrumpido
+func ResumeRevalidation(c *gin.Context) {
+	id, ok := jobID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	job, err := revalidator.Resume(ctx, id)
+	if err != nil {
+		sendJobError(c, err, "Error al reanudar la revalidación")
+		return
+	}
+	sendJobAccepted(c, job, "Revalidación reanudada")
+}
+
+// CancelRevalidation - Detener un trabajo en curso; conserva su checkpoint
+// para reanudarlo después
+func CancelRevalidation(c *gin.Context) {
+	id, ok := jobID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	job, err := revalidator.Cancel(ctx, id)
+	if err != nil {
+		sendJobError(c, err, "Error al cancelar la revalidación")
+		return
+	}
+	sendJobAccepted(c, job, "Cancelación de la revalidación solicitada")
+}
+
+// jobID lee el id del trabajo de la ruta; responde 400 si no es válido
+func jobID(c *gin.Context) (primitive.ObjectID, bool) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "ID de revalidación inválido", nil, "665f1c2e8b3a4d0012345678")
+		return primitive.NilObjectID, false
+	}
+	return id, true
+}
+
+func sendJobAccepted(c *gin.Context, job *models.RevalidationJob, message string) {
+	c.Header("Location", "/api/admin/revalidation/"+job.ID.Hex())
+	sendSuccessResponse(c, http.StatusAccepted, message, job, nil)
+}
+
+// sendJobError traduce los errores del revalidador a su código HTTP
+func sendJobError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, jobs.ErrJobNotFound):
+		sendErrorResponse(c, http.StatusNotFound, err.Error(), nil, nil)
+	case errors.Is(err, jobs.ErrJobRunning), errors.Is(err, jobs.ErrJobNotRunning), errors.Is(err, jobs.ErrJobNotResumable):
+		sendErrorResponse(c, http.StatusConflict, err.Error(), nil, nil)
+	default:
+		log.Printf("%s: %v", message, err)
+		sendErrorResponse(c, http.StatusInternalServerError, message, nil, nil)
+	}
+}
@@ -0,0 +1,246 @@
+// controllers/softdelete.controller.go
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"api_compiladores/src/models"
+	"api_compiladores/src/utils"
+)
+
+// Clientes que se purgan por lote (consulta, borrado y auditoría)
+const purgeBatchSize = 500
+
+// Antigüedad mínima de la baja para purgar un cliente (SOFT_DELETE_RETENTION)
+var purgeRetention = 30 * 24 * time.Hour
+
+// activeFilter agrega a un filtro la condición de cliente sin baja lógica
+func activeFilter(filter bson.M) bson.M {
+	active := bson.M{"deleted_at": bson.M{"$exists": false}}
+	for key, value := range filter {
+		active[key] = value
+	}
+	return active
+}
+
+// ParseRetention acepta una duración de Go (720h) o un número de días (30d)
+func ParseRetention(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	var retention time.Duration
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("retención inválida: %q", value)
+		}
+		retention = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if retention, err = time.ParseDuration(value); err != nil {
+			return 0, fmt.Errorf("retención inválida: %q (usa 720h o 30d)", value)
+		}
+	}
+	if retention <= 0 {
+		return 0, fmt.Errorf("la retención debe ser mayor que cero: %q", value)
+	}
+	return retention, nil
+}
+
+// SetPurgeRetention fija la retención por defecto de la purga
+func SetPurgeRetention(value string) error {
+	retention, err := ParseRetention(value)
+	if err != nil {
+		return err
+	}
+	purgeRetention = retention
+	return nil
+}
+
+// EnsureClienteIndexes crea los índices de la colección de clientes: el
+// único de Clave_Cliente, que impide que dos altas o upserts simultáneos
+// dupliquen la clave, el disperso con el que la purga encuentra las bajas
+// antiguas sin recorrer los clientes activos, los de la búsqueda por nombre
+// y email, el del orden por omisión de los listados y los de los filtros
+// por Errores
+func EnsureClienteIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "Clave_Cliente", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "deleted_at", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		{Keys: bson.D{{Key: "NombreBusqueda", Value: 1}}},
+		{Keys: bson.D{{Key: "EmailBusqueda", Value: 1}}},
+		{Keys: defaultOrder.Sort(false)},
+	}
+	_, err := clienteCollection.Indexes().CreateMany(ctx, append(indexes, errorIndexes()...))
+	return err
+}
+
+// RestoreCliente - Quitar la baja lógica de un cliente
+func RestoreCliente(c *gin.Context) {
+	claveCliente := c.Param("Clave_Cliente")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var anterior models.Cliente
+	err := clienteCollection.FindOneAndUpdate(ctx,
+		bson.M{"Clave_Cliente": claveCliente, "deleted_at": bson.M{"$exists": true}},
+		bson.M{
+			"$unset": bson.M{"deleted_at": "", "deleted_by": ""},
+			"$inc":   bson.M{"version": 1},
+		},
+	).Decode(&anterior)
+	if err == mongo.ErrNoDocuments {
+		sendErrorResponse(c, http.StatusNotFound, "No hay un cliente eliminado con esa Clave_Cliente", nil, nil)
+		return
+	}
+	if err != nil {
+		log.Printf("Error al restaurar cliente %s: %v", claveCliente, err)
+		sendErrorResponse(c, http.StatusInternalServerError, "Error al restaurar cliente", nil, nil)
+		return
+	}
+
+	restaurado := anterior
+	restaurado.DeletedAt = nil
+	restaurado.DeletedBy = ""
+	restaurado.Version = anterior.Version + 1
+	recordAudit(c, models.AuditRestore, claveCliente, &anterior, &restaurado)
+
+	// Síncrono: el cliente debe volver a aparecer en las páginas desde la
+	// siguiente petición
+	if err := utils.InvalidateClienteCache(claveCliente); err != nil {
+		log.Printf("Error invalidando caché para cliente restaurado %s: %v", claveCliente, err)
+	}
+	utils.UpdateCacheStats("invalidate")
+
+	setClienteETag(c, restaurado)
+	sendSuccessResponse(c, http.StatusOK, "Cliente restaurado exitosamente", restaurado, nil)
+}
+
+// PurgeClientes - Borrar definitivamente los clientes con baja lógica más
+// antigua que la retención (?older_than=720h o 30d, por defecto
+// SOFT_DELETE_RETENTION). Cada cliente purgado queda en la auditoría.
+func PurgeClientes(c *gin.Context) {
+	retention := purgeRetention
+	if value := c.Query("older_than"); value != "" {
+		parsed, err := ParseRetention(value)
+		if err != nil {
+			sendErrorResponse(c, http.StatusBadRequest, err.Error(), nil, "30d")
+			return
+		}
+		retention = parsed
+	}
+	cutoff := time.Now().Add(-retention)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	// Cada lote purgado deja de coincidir con el filtro, así que se consulta
+	// hasta que no queden clientes
+	filter := bson.M{"deleted_at": bson.M{"$lt": cutoff}}
+	var purgados int64
+	for {
+		cursor, err := clienteCollection.Find(ctx, filter, options.Find().SetLimit(purgeBatchSize))
+		if err != nil {
+			log.Printf("Error buscando clientes para purgar: %v", err)
+			sendErrorResponse(c, http.StatusInternalServerError, "Error al purgar clientes", gin.H{"purgados": purgados}, nil)
+			return
+		}
+		var lote []models.Cliente
+		if err := cursor.All(ctx, &lote); err != nil {
+			log.Printf("Error leyendo clientes para purgar: %v", err)
+			sendErrorResponse(c, http.StatusInternalServerError, "Error al purgar clientes", gin.H{"purgados": purgados}, nil)
+			return
+		}
+		if len(lote) == 0 {
+			break
+		}
+
+		ids := make([]interface{}, len(lote))
+		for i := range lote {
+			ids[i] = lote[i].ID
+		}
+
+		// La condición de fecha se repite por si el cliente se restauró
+		// entre la consulta y el borrado
+		result, err := clienteCollection.DeleteMany(ctx, bson.M{
+			"_id":        bson.M{"$in": ids},
+			"deleted_at": bson.M{"$lt": cutoff},
+		})
+		if err != nil {
+			log.Printf("Error purgando clientes: %v", err)
+			sendErrorResponse(c, http.StatusInternalServerError, "Error al purgar clientes", gin.H{"purgados": purgados}, nil)
+			return
+		}
+		purgados += result.DeletedCount
+
+		// Solo se auditan los clientes que el borrado eliminó: si algún
+		// cliente se restauró entre la consulta y el borrado, sigue existiendo
+		restantes := map[primitive.ObjectID]bool{}
+		if result.DeletedCount < int64(len(lote)) {
+			restantes, err = existingIDs(ctx, ids)
+			if err != nil {
+				log.Printf("Error revisando clientes purgados; %d purgas quedan sin auditar: %v", result.DeletedCount, err)
+				continue
+			}
+		}
+		registros := make([]models.Auditoria, 0, result.DeletedCount)
+		for i := range lote {
+			if !restantes[lote[i].ID] {
+				registros = append(registros, newAudit(c, models.AuditPurge, fmt.Sprint(lote[i].Clave_Cliente), &lote[i], nil))
+			}
+		}
+		insertAudits(registros...)
+	}
+
+	// Los clientes con baja no se guardan en caché, pero se limpia por si
+	// alguna entrada se escribió antes de la baja
+	if purgados > 0 {
+		if err := utils.InvalidateAllClientesCache(); err != nil {
+			log.Printf("Error invalidando caché tras purgar clientes: %v", err)
+		}
+		utils.UpdateCacheStats("invalidate")
+	}
+
+	sendSuccessResponse(c, http.StatusOK, "Purga completada", gin.H{
+		"purgados":  purgados,
+		"retencion": retention.String(),
+		"corte":     cutoff.UTC(),
+	}, nil)
+}
+
+// existingIDs devuelve cuáles de los _id siguen en la colección
+func existingIDs(ctx context.Context, ids []interface{}) (map[primitive.ObjectID]bool, error) {
+	cursor, err := clienteCollection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}},
+		options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var docs []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	existentes := make(map[primitive.ObjectID]bool, len(docs))
+	for _, doc := range docs {
+		existentes[doc.ID] = true
+	}
+	return existentes, nil
+}
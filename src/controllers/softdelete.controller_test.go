@@ -0,0 +1,65 @@
+// controllers/softdelete.controller_test.go
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"api_compiladores/src/models"
+)
+
+func TestPurgeClientesAuditsOnlyDeleted(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("restored during purge", func(mt *mtest.T) {
+		useMockCollections(mt)
+		r := gin.New()
+		r.DELETE("/api/admin/clientes/purge", PurgeClientes)
+
+		ns := mt.DB.Name() + "." + mt.Coll.Name()
+		deletedAt := time.Now().Add(-60 * 24 * time.Hour)
+		purgado, restaurado := primitive.NewObjectID(), primitive.NewObjectID()
+		mt.AddMockResponses(
+			// Lote con dos clientes dados de baja
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch,
+				bson.D{{Key: "_id", Value: purgado}, {Key: "Clave_Cliente", Value: "101"}, {Key: "deleted_at", Value: deletedAt}},
+				bson.D{{Key: "_id", Value: restaurado}, {Key: "Clave_Cliente", Value: "102"}, {Key: "deleted_at", Value: deletedAt}}),
+			// El 102 se restauró antes del borrado
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{{Key: "_id", Value: restaurado}}),
+			mtest.CreateSuccessResponse(),
+			// Ya no quedan clientes por purgar
+			noDocuments(mt, mt.Coll.Name()),
+		)
+
+		w, _ := serve(r, http.MethodDelete, "/api/admin/clientes/purge", "", nil)
+		if w.Code != http.StatusOK {
+			mt.Fatalf("status = %d: %s", w.Code, w.Body.String())
+		}
+		var response map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			mt.Fatal(err)
+		}
+		if purgados := response["purgados"]; purgados != float64(1) {
+			mt.Errorf("purgados = %v, se esperaba 1", purgados)
+		}
+
+		audits := inserts(mt.GetAllStartedEvents())["audit"]
+		if len(audits) != 1 {
+			mt.Fatalf("se esperaba una auditoría, hubo %d", len(audits))
+		}
+		if clave := audits[0].Lookup("Clave_Cliente").StringValue(); clave != "101" {
+			mt.Errorf("se auditó %s, se esperaba 101", clave)
+		}
+		if op := audits[0].Lookup("operacion").StringValue(); op != models.AuditPurge {
+			mt.Errorf("operación = %s, se esperaba %s", op, models.AuditPurge)
+		}
+	})
+}
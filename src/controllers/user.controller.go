@@ -17,7 +17,9 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"api_compiladores/src/middleware"
 	"api_compiladores/src/models"
+	"api_compiladores/src/search"
 	"api_compiladores/src/utils"
 )
 
@@ -55,13 +57,14 @@ type MetaInfo struct {
 var (
 	// Expresiones regulares reutilizables
 	identRegexNumeric = regexp.MustCompile(`^[0-9]*$`)
-	identRegexNombre  = regexp.MustCompile(`^[a-zA-ZáéíóúÁÉÍÓÚñÑüÜ\s]+$`)
 	identRegexCelular = regexp.MustCompile(`^(91[6-9]|93[24]|96[1-8]|99[24])\d{7}$`)
-	identRegexEmail   = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@(gmail\.com|hotmail\.com|yahoo\.com|outlook\.com|live\.com|icloud\.com|protonmail\.com|aol\.com|msn\.com|gmx\.com|ymail\.com|me\.com|mail\.com|zoho\.com|edu\.mx|edu\.com|edu\.org)$`)
 )
 
 var clienteCollection *mongo.Collection
 
+// Tiempo máximo de ejecución en MongoDB de una búsqueda
+const searchMaxTime = 5 * time.Second
+
 var exampleCreate = ExampleClienteCreate{
 	Clave_Cliente: "001",
 	Nombre:        "Pedro",
@@ -98,6 +101,12 @@ func CreateCliente(c *gin.Context) {
 		return
 	}
 
+	policy, err := resolveValidationPolicy(c)
+	if err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, err.Error(), nil, nil)
+		return
+	}
+
 	cliente.ID = primitive.NewObjectID()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -115,7 +124,13 @@ func CreateCliente(c *gin.Context) {
 	// Verificar en base de datos
 	var existingCliente models.Cliente
 	err = clienteCollection.FindOne(ctx, bson.M{"Clave_Cliente": claveCliente}).Decode(&existingCliente)
-	if err == nil {
+	if err == nil && existingCliente.IsDeleted() {
+		// La clave sigue ocupada por un cliente con baja lógica
+		sendErrorResponse(c, http.StatusBadRequest,
+			fmt.Sprintf("El cliente con Clave_Cliente %s está eliminado; restáuralo con POST /api/clientes/%s/restore", claveCliente, claveCliente),
+			nil, &exampleCreate)
+		return
+	} else if err == nil {
 		// Cliente existe, guardarlo en caché para futuras consultas
 		utils.CacheSingleCliente(claveCliente, existingCliente, utils.DefaultTTL)
 		sendErrorResponse(c, http.StatusBadRequest,
@@ -129,18 +144,34 @@ func CreateCliente(c *gin.Context) {
 	}
 
 	cliente.Clave_Cliente = claveCliente
+	cliente.Version = 1
 	utils.ValidateCliente(&cliente)
 
+	// Aplicar la política para clientes inválidos (store, reject o quarantine)
+	if applyValidationPolicy(c, policy, "create", claveCliente, cliente) {
+		return
+	}
+
 	// Insertar en base de datos
-	if _, err := clienteCollection.InsertOne(ctx, cliente); err != nil {
+	_, err = clienteCollection.InsertOne(ctx, cliente)
+	if mongo.IsDuplicateKeyError(err) {
+		// Otra petición creó la misma clave después de la verificación
+		sendErrorResponse(c, http.StatusBadRequest,
+			fmt.Sprintf("El cliente con Clave_Cliente %s ya existe", claveCliente),
+			nil, &exampleCreate)
+		return
+	}
+	if err != nil {
 		log.Printf("Error al insertar cliente: %v", err)
 		sendErrorResponse(c, http.StatusInternalServerError, "Error al insertar cliente", nil, nil)
 		return
 	}
 
+	recordAudit(c, models.AuditCreate, claveCliente, nil, &cliente)
+
 	// Invalidar caché relacionado (async para no bloquear la respuesta)
 	go func() {
-		if err := utils.InvalidateAllClientesCache(); err != nil {
+		if err := utils.InvalidateClientesCache(claveCliente); err != nil {
 			log.Printf("Error invalidando caché tras crear cliente: %v", err)
 		}
 		utils.UpdateCacheStats("invalidate")
@@ -149,6 +180,7 @@ func CreateCliente(c *gin.Context) {
 	// Cachear el nuevo cliente
 	go utils.CacheSingleCliente(claveCliente, cliente, utils.DefaultTTL)
 
+	setClienteETag(c, cliente)
 	sendSuccessResponse(c, http.StatusCreated, "Cliente creado exitosamente", cliente, nil)
 }
 
@@ -182,11 +214,18 @@ func GetClientes(c *gin.Context) {
 		return
 	}
 
-	limit := int64(100)
-	skip := int64((intPage - 1) * int(limit))
+	// Tamaño de página, orden y campos (?limit=, ?sort=, ?fields=)
+	opts, err := parseListOptions(c, 100)
+	if err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, err.Error(), nil, exampleListOptions)
+		return
+	}
+	limit := int64(opts.Limit)
+	skip := int64(intPage-1) * limit
 
-	// Intentar obtener desde caché primero
-	if cachedClientes, found, err := utils.GetCachedClientesList(intPage); found && err == nil {
+	// Intentar obtener desde caché primero; cada combinación de opciones
+	// tiene su propia llave
+	if cachedClientes, found, err := utils.GetCachedClientesList(intPage, opts.cacheKey()); found && err == nil {
 		utils.UpdateCacheStats("hit")
 
 		meta := &MetaInfo{
@@ -197,8 +236,12 @@ func GetClientes(c *gin.Context) {
 			Timestamp: time.Now().Unix(),
 		}
 
-		sendSuccessResponse(c, http.StatusOK, "Clientes obtenidos desde caché", cachedClientes, meta)
-		return
+		data, err := opts.present(cachedClientes)
+		if err == nil {
+			sendSuccessResponse(c, http.StatusOK, "Clientes obtenidos desde caché", data, meta)
+			return
+		}
+		log.Printf("Error proyectando página %d desde caché: %v", intPage, err)
 	}
 
 	utils.UpdateCacheStats("miss")
@@ -210,7 +253,7 @@ func GetClientes(c *gin.Context) {
 	var total int64 = 0
 	if intPage == 1 {
 		// Contar total documentos sólo para la primera página
-		total, err = clienteCollection.CountDocuments(ctx, bson.M{})
+		total, err = clienteCollection.CountDocuments(ctx, activeFilter(nil))
 		if err != nil {
 			log.Printf("Error contando documentos: %v", err)
 			// Puedes decidir retornar error o continuar con total=0
@@ -221,11 +264,15 @@ func GetClientes(c *gin.Context) {
 	findOptions := options.Find()
 	findOptions.SetLimit(limit)
 	findOptions.SetSkip(skip)
-	// Ordenar por Clave_Cliente para aprovechar el índice
-	findOptions.SetSort(bson.D{{Key: "Clave_Cliente", Value: 1}})
+	// Por omisión se ordena por Clave_Cliente para aprovechar el índice; el
+	// _id desempata para que las páginas no se traslapen
+	findOptions.SetSort(opts.Order.Sort(false))
+	if projection := opts.projection(); projection != nil {
+		findOptions.SetProjection(projection)
+	}
 
 	// Ejecutar consulta
-	cursor, err := clienteCollection.Find(ctx, bson.M{}, findOptions)
+	cursor, err := clienteCollection.Find(ctx, activeFilter(nil), findOptions)
 	if err != nil {
 		log.Printf("Error al obtener clientes: %v", err)
 		sendErrorResponse(c, http.StatusInternalServerError, "Error al obtener clientes", nil, nil)
@@ -251,12 +298,19 @@ func GetClientes(c *gin.Context) {
 
 	// Guardar en caché de forma asíncrona (no bloquea la respuesta)
 	go func(page int, data []models.Cliente) {
-		if err := utils.CacheClientesList(page, data, utils.DefaultTTL); err != nil {
+		if err := utils.CacheClientesList(page, opts.cacheKey(), data, utils.DefaultTTL); err != nil {
 			log.Printf("Error guardando página %d en caché: %v", page, err)
 		}
 		utils.UpdateCacheStats("set")
 	}(intPage, clientes)
 
+	data, err := opts.present(clientes)
+	if err != nil {
+		log.Printf("Error proyectando página %d: %v", intPage, err)
+		sendErrorResponse(c, http.StatusInternalServerError, "Error preparando la respuesta", nil, nil)
+		return
+	}
+
 	meta := &MetaInfo{
 		Page:      intPage,
 		Limit:     int(limit),
@@ -266,7 +320,7 @@ func GetClientes(c *gin.Context) {
 		Timestamp: time.Now().Unix(),
 	}
 
-	sendSuccessResponse(c, http.StatusOK, "Clientes obtenidos desde base de datos", clientes, meta)
+	sendSuccessResponse(c, http.StatusOK, "Clientes obtenidos desde base de datos", data, meta)
 }
 
 
@@ -274,9 +328,22 @@ func GetClientes(c *gin.Context) {
 func GetCliente(c *gin.Context) {
 	claveCliente := c.Param("Clave_Cliente")
 	
-	// Intentar obtener desde caché primero
-	if cachedCliente, found, err := utils.GetCachedSingleCliente(claveCliente); found && err == nil {
+	// Intentar obtener desde caché primero; una entrada de un cliente ya
+	// eliminado se descarta y se consulta la base de datos
+	cachedCliente, found, err := utils.GetCachedSingleCliente(claveCliente)
+	if found && err == nil && cachedCliente.IsDeleted() {
+		utils.InvalidateClienteCache(claveCliente)
+		found = false
+	}
+	if found && err == nil {
 		utils.UpdateCacheStats("hit")
+
+		// La versión viaja en el documento cacheado, así que el ETag es el mismo
+		setClienteETag(c, *cachedCliente)
+		if notModified(c, *cachedCliente) {
+			c.Status(http.StatusNotModified)
+			return
+		}
 		
 		meta := &MetaInfo{
 			CacheHit:  true,
@@ -295,7 +362,7 @@ func GetCliente(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	err := clienteCollection.FindOne(ctx, bson.M{"Clave_Cliente": claveCliente}).Decode(&cliente)
+	err = clienteCollection.FindOne(ctx, activeFilter(bson.M{"Clave_Cliente": claveCliente})).Decode(&cliente)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			sendErrorResponse(c, http.StatusNotFound, "Cliente no encontrado", nil, nil)
@@ -314,6 +381,12 @@ func GetCliente(c *gin.Context) {
 		utils.UpdateCacheStats("set")
 	}()
 
+	setClienteETag(c, cliente)
+	if notModified(c, cliente) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
 	meta := &MetaInfo{
 		CacheHit:  false,
 		Source:    "database",
@@ -323,7 +396,42 @@ func GetCliente(c *gin.Context) {
 	sendSuccessResponse(c, http.StatusOK, "Cliente obtenido desde base de datos", cliente, meta)
 }
 
-// UpdateCliente - Actualizar cliente con invalidación de caché
+// Query param que permite a PUT crear el cliente si no existe
+const UpsertQuery = "upsert"
+
+// Intentos de PUT sin If-Match cuando otro editor cambia el cliente entre la
+// lectura y la escritura
+const putMaxAttempts = 3
+
+// Si PUT crea por defecto los clientes inexistentes (variable PUT_UPSERT)
+var defaultPutUpsert = false
+
+// SetDefaultPutUpsert fija si PUT hace upsert cuando la petición no lo indica
+func SetDefaultPutUpsert(value string) error {
+	upsert, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("valor de upsert inválido: %q (true o false)", value)
+	}
+	defaultPutUpsert = upsert
+	return nil
+}
+
+// resolveUpsert lee ?upsert=true|false; sin el parámetro se usa PUT_UPSERT
+func resolveUpsert(c *gin.Context) (bool, error) {
+	value := c.Query(UpsertQuery)
+	if value == "" {
+		return defaultPutUpsert, nil
+	}
+	upsert, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s inválido: %q (true o false)", UpsertQuery, value)
+	}
+	return upsert, nil
+}
+
+// UpdateCliente - Reemplazar los datos de un cliente (PUT). Responde 404 si
+// no existe, salvo con upsert, que lo crea (201). Repetir la misma petición
+// no vuelve a escribir: si nada cambia se devuelve el cliente vigente.
 func UpdateCliente(c *gin.Context) {
 	claveCliente := c.Param("Clave_Cliente")
 	if claveCliente == "" {
@@ -331,6 +439,18 @@ func UpdateCliente(c *gin.Context) {
 		return
 	}
 
+	policy, err := resolveValidationPolicy(c)
+	if err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, err.Error(), nil, nil)
+		return
+	}
+
+	upsert, err := resolveUpsert(c)
+	if err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, err.Error(), nil, nil)
+		return
+	}
+
 	var cliente models.Cliente
 	if err := c.ShouldBindJSON(&cliente); err != nil {
 		sendErrorResponse(c, http.StatusBadRequest, "Datos JSON inválidos", err.Error(), &examplePut)
@@ -348,7 +468,6 @@ func UpdateCliente(c *gin.Context) {
 	clienteResponse.Errores = cliente.Errores
 
 	utils.ValidateCliente(&clienteResponse)
-
 	
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -358,33 +477,139 @@ func UpdateCliente(c *gin.Context) {
 			"Nombre":  clienteResponse.Nombre,
 			"Celular": clienteResponse.Celular,
 			"Email":   clienteResponse.Email,
+			"NombreBusqueda": clienteResponse.NombreBusqueda,
+			"EmailBusqueda": clienteResponse.EmailBusqueda,
+			"NombreNormalizado": clienteResponse.NombreNormalizado,
+			"NombrePila": clienteResponse.NombrePila,
+			"ApellidoPaterno": clienteResponse.ApellidoPaterno,
+			"ApellidoMaterno": clienteResponse.ApellidoMaterno,
 			"Errores": clienteResponse.Errores,
+			"Mensajes": clienteResponse.Mensajes,
+			"CelularNormalizado": clienteResponse.CelularNormalizado,
+			"CelularE164": clienteResponse.CelularE164,
+			"CelularNacional": clienteResponse.CelularNacional,
+			"CelularPais": clienteResponse.CelularPais,
+			"Region": clienteResponse.Region,
 		},
+		// Al crear con upsert, $inc sobre el campo ausente deja la versión en 1
+		"$inc": bson.M{"version": 1},
 	}
 
-	err := clienteCollection.FindOneAndUpdate(ctx, bson.M{"Clave_Cliente": claveCliente}, update).Decode(&cliente)
-	if err != nil {
-		log.Printf("Error al actualizar cliente %s: %v", claveCliente, err)
-		sendErrorResponse(c, http.StatusInternalServerError, "Error al actualizar cliente", nil, nil)
-		return
-	}
+	for attempt := 1; ; attempt++ {
+		// El documento previo decide entre 404, upsert o actualización y queda
+		// en la auditoría
+		var anterior models.Cliente
+		err := clienteCollection.FindOne(ctx, bson.M{"Clave_Cliente": claveCliente}).Decode(&anterior)
+		exists := err == nil
+		if err != nil && err != mongo.ErrNoDocuments {
+			log.Printf("Error obteniendo cliente %s: %v", claveCliente, err)
+			sendErrorResponse(c, http.StatusInternalServerError, "Error interno del servidor", nil, nil)
+			return
+		}
 
-	clienteResponse.ID = cliente.ID
+		switch {
+		case exists && anterior.IsDeleted() && upsert:
+			// La clave sigue ocupada; un upsert crearía un duplicado
+			sendErrorResponse(c, http.StatusConflict,
+				fmt.Sprintf("El cliente con Clave_Cliente %s está eliminado; restáuralo con POST /api/clientes/%s/restore", claveCliente, claveCliente),
+				nil, nil)
+			return
+		case exists && anterior.IsDeleted(), !exists && !upsert:
+			sendErrorResponse(c, http.StatusNotFound, "Cliente no encontrado", nil,
+				map[string]string{"upsert": fmt.Sprintf("PUT /api/clientes/%s?%s=true", claveCliente, UpsertQuery)})
+			return
+		case !exists && c.GetHeader("If-Match") != "":
+			// If-Match exige una versión existente (RFC 9110, sección 13.1.1)
+			sendErrorResponse(c, http.StatusPreconditionFailed, "If-Match no aplica a un cliente inexistente", nil, nil)
+			return
+		case !exists && !identRegexNumeric.MatchString(claveCliente):
+			sendErrorResponse(c, http.StatusBadRequest, "Clave_Cliente debe contener solo números", nil, &exampleCreate)
+			return
+		case exists && !ifMatchAllows(c, anterior.Version):
+			respondNoMatch(ctx, c, claveCliente)
+			return
+		}
 
-	// Invalidar caché de forma asíncrona
-	go func() {
+		operacion := "update"
+		if !exists {
+			operacion = "create"
+		}
+		// Aplicar la política para clientes inválidos (store, reject o quarantine)
+		if applyValidationPolicy(c, policy, operacion, claveCliente, clienteResponse) {
+			return
+		}
+
+		// Sin cambios no se escribe ni cambia la versión, así que repetir el
+		// PUT es idempotente también para el ETag
+		if exists {
+			candidato := clienteResponse
+			candidato.ID, candidato.Version = anterior.ID, anterior.Version
+			if len(models.DiffClientes(&anterior, &candidato)) == 0 {
+				setClienteETag(c, anterior)
+				sendSuccessResponse(c, http.StatusOK, "El cliente no tiene cambios", anterior, nil)
+				return
+			}
+		}
+
+		// La escritura exige la versión leída; al crear, el filtro solo
+		// coincide con clientes activos para no duplicar uno eliminado
+		filter := activeFilter(bson.M{"Clave_Cliente": claveCliente})
+		if exists {
+			filter["version"] = versionIn(anterior.Version)
+		}
+		updateOptions := options.FindOneAndUpdate().
+			SetReturnDocument(options.After).
+			SetUpsert(!exists)
+
+		var actualizado models.Cliente
+		err = clienteCollection.FindOneAndUpdate(ctx, filter, update, updateOptions).Decode(&actualizado)
+		if err == mongo.ErrNoDocuments {
+			// Otro editor cambió el cliente; con If-Match se pidió esa versión
+			// exacta y sin él se vuelve a intentar sobre la nueva
+			if c.GetHeader("If-Match") != "" || attempt == putMaxAttempts {
+				respondNoMatch(ctx, c, claveCliente)
+				return
+			}
+			continue
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			// El índice único de Clave_Cliente rechazó el upsert: otra
+			// petición creó el cliente al mismo tiempo
+			sendErrorResponse(c, http.StatusConflict,
+				fmt.Sprintf("El cliente con Clave_Cliente %s se creó en otra petición; vuelve a consultarlo", claveCliente),
+				nil, nil)
+			return
+		}
+		if err != nil {
+			log.Printf("Error al actualizar cliente %s: %v", claveCliente, err)
+			sendErrorResponse(c, http.StatusInternalServerError, "Error al actualizar cliente", nil, nil)
+			return
+		}
+
+		status, message := http.StatusOK, "Cliente actualizado exitosamente"
+		if exists {
+			recordAudit(c, models.AuditUpdate, claveCliente, &anterior, &actualizado)
+		} else {
+			status, message = http.StatusCreated, "Cliente creado exitosamente"
+			recordAudit(c, models.AuditCreate, claveCliente, nil, &actualizado)
+		}
+
+		// Síncrono: si la caché conservara la versión anterior, el siguiente GET
+		// entregaría un ETag viejo y el If-Match fallaría
 		if err := utils.InvalidateClienteCache(claveCliente); err != nil {
 			log.Printf("Error invalidando caché para cliente %s: %v", claveCliente, err)
 		}
 		utils.UpdateCacheStats("invalidate")
-	}()
 
-	// Cachear el cliente actualizado
-	go utils.CacheSingleCliente(claveCliente, 
-		clienteResponse, 
-		utils.DefaultTTL)
+		// Cachear el cliente actualizado
+		go utils.CacheSingleCliente(claveCliente, 
+			actualizado, 
+			utils.DefaultTTL)
 
-	sendSuccessResponse(c, http.StatusOK, "Cliente actualizado exitosamente", clienteResponse, nil)
+		setClienteETag(c, actualizado)
+		sendSuccessResponse(c, status, message, actualizado, nil)
+		return
+	}
 }
 
 // DeleteCliente - Eliminar cliente con invalidación de caché
@@ -398,25 +623,45 @@ func DeleteCliente(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	result, err := clienteCollection.DeleteOne(ctx, bson.M{"Clave_Cliente": claveCliente})
+	// Baja lógica: el documento se conserva para poder restaurarlo hasta que
+	// la purga lo borre
+	deletedAt := time.Now().UTC()
+	var deletedBy string
+	if principal, ok := middleware.CurrentPrincipal(c); ok {
+		deletedBy = principal.Subject
+	}
+
+	// Con If-Match solo se elimina la versión que el cliente leyó
+	condition := versionCondition(c)
+	var anterior models.Cliente
+	err := clienteCollection.FindOneAndUpdate(ctx,
+		withVersion(activeFilter(bson.M{"Clave_Cliente": claveCliente}), condition),
+		bson.M{
+			"$set": bson.M{"deleted_at": deletedAt, "deleted_by": deletedBy},
+			"$inc": bson.M{"version": 1},
+		},
+	).Decode(&anterior)
+	if err == mongo.ErrNoDocuments {
+		respondNoMatch(ctx, c, claveCliente)
+		return
+	}
 	if err != nil {
 		log.Printf("Error al eliminar cliente %s: %v", claveCliente, err)
 		sendErrorResponse(c, http.StatusInternalServerError, "Error al eliminar cliente", nil, nil)
 		return
 	}
 
-	if result.DeletedCount == 0 {
-		sendErrorResponse(c, http.StatusNotFound, "Cliente no encontrado", nil, nil)
-		return
-	}
+	eliminado := anterior
+	eliminado.DeletedAt = &deletedAt
+	eliminado.DeletedBy = deletedBy
+	eliminado.Version = anterior.Version + 1
+	recordAudit(c, models.AuditDelete, claveCliente, &anterior, &eliminado)
 
-	// Invalidar caché de forma asíncrona
-	go func() {
-		if err := utils.InvalidateClienteCache(claveCliente); err != nil {
-			log.Printf("Error invalidando caché para cliente eliminado %s: %v", claveCliente, err)
-		}
-		utils.UpdateCacheStats("invalidate")
-	}()
+	// Síncrono: una consulta inmediata no debe encontrar el cliente en caché
+	if err := utils.InvalidateClienteCache(claveCliente); err != nil {
+		log.Printf("Error invalidando caché para cliente eliminado %s: %v", claveCliente, err)
+	}
+	utils.UpdateCacheStats("invalidate")
 
 	sendSuccessResponse(c, http.StatusOK, "Cliente eliminado exitosamente", nil, nil)
 }
@@ -520,7 +765,7 @@ func normalizeClaveCliente(claveCliente interface{}) (string, error) {
 		return "", fmt.Errorf("Clave_Cliente debe contener solo números")
 	}
 
-	return "", fmt.Errorf("Clave_Cliente inválida")
+	return claveStr, nil
 }
 
 // sendSuccessResponse - Enviar respuesta exitosa estandarizada
@@ -534,6 +779,7 @@ func sendSuccessResponse(c *gin.Context, statusCode int, message string, data in
 	}
 
 
+
 	c.JSON(statusCode, data)
 }
 
@@ -563,15 +809,19 @@ func GetClientesCount(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	count, err := clienteCollection.CountDocuments(ctx, bson.M{})
+	count, err := clienteCollection.CountDocuments(ctx, activeFilter(nil))
 	if err != nil {
 		log.Printf("Error obteniendo conteo de clientes: %v", err)
 		sendErrorResponse(c, http.StatusInternalServerError, "Error obteniendo conteo", nil, nil)
 		return
 	}
 
-	// Calcular páginas totales
-	limit := 100
+	// Calcular páginas totales con el mismo ?limit= de /page/:page
+	limit, err := parseLimit(c, 100)
+	if err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, err.Error(), nil, "100")
+		return
+	}
 	totalPages := int(math.Ceil(float64(count) / float64(limit)))
 
 	data := map[string]interface{}{
@@ -607,35 +857,66 @@ func SearchClientes(c *gin.Context) {
 		return
 	}
 
-	// Construir filtro de búsqueda
-	filter := bson.M{}
-	if nombre != "" {
-		// Búsqueda case-insensitive con regex
-		filter["Nombre"] = primitive.Regex{
-			Pattern: nombre,
-			Options: "i",
+	// Construir filtro de búsqueda; cada término se compila con el lenguaje
+	// de search (exacto, prefijo, contiene o comodín) y nunca como regex crudo.
+	// Nombre y Email se buscan en su forma plegada, que tiene índice; los
+	// clientes guardados antes de esos campos los obtienen al revalidarse.
+	filter := activeFilter(nil)
+	criterios := []struct {
+		field  string
+		stored string
+		value  string
+		def    search.Kind
+	}{
+		{"Nombre", "NombreBusqueda", search.Fold(nombre), search.Prefix},
+		{"Email", "EmailBusqueda", search.Fold(email), search.Prefix},
+		{"Celular", "Celular", celular, search.Exact},
+	}
+	for _, criterio := range criterios {
+		if criterio.value == "" {
+			continue
 		}
-	}
-	if email != "" {
-		filter["Email"] = primitive.Regex{
-			Pattern: email,
-			Options: "i",
+		term, err := search.Parse(criterio.value, criterio.def)
+		if err != nil {
+			sendErrorResponse(c, http.StatusBadRequest,
+				fmt.Sprintf("Término de búsqueda inválido para %s", criterio.field), err,
+				map[string]string{
+					"exacto":   "=Pedro",
+					"prefijo":  "Ped*",
+					"contiene": "*dro*",
+					"comodin":  "P*ro",
+				})
+			return
 		}
-	}
-	if celular != "" {
-		filter["Celular"] = celular
+		filter[criterio.stored] = term.Filter()
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
-	// Limitar resultados de búsqueda
-	limit := int64(50)
-	options := options.Find()
-	options.SetLimit(limit)
-	options.SetSort(bson.D{{"Clave_Cliente", 1}})
+	// Limitar resultados de búsqueda (?limit=, 50 por omisión), con el orden
+	// y los campos pedidos
+	opts, err := parseListOptions(c, 50)
+	if err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, err.Error(), nil, exampleListOptions)
+		return
+	}
+	limit := int64(opts.Limit)
+	findOptions := options.Find()
+	findOptions.SetLimit(limit)
+	findOptions.SetSort(opts.Order.Sort(false))
+	if projection := opts.projection(); projection != nil {
+		findOptions.SetProjection(projection)
+	}
+	// Las búsquedas por contenido no usan índice; se cortan antes de recorrer toda la colección
+	findOptions.SetMaxTime(searchMaxTime)
 
-	cursor, err := clienteCollection.Find(ctx, filter, options)
+	cursor, err := clienteCollection.Find(ctx, filter, findOptions)
+	if mongo.IsTimeout(err) {
+		sendErrorResponse(c, http.StatusUnprocessableEntity,
+			"La búsqueda tardó demasiado; usa un término exacto o de prefijo", nil, nil)
+		return
+	}
 	if err != nil {
 		log.Printf("Error en búsqueda de clientes: %v", err)
 		sendErrorResponse(c, http.StatusInternalServerError, "Error en búsqueda", nil, nil)
@@ -654,7 +935,7 @@ func SearchClientes(c *gin.Context) {
 	}
 
 	// Obtener conteo total de resultados
-	totalCount, err := clienteCollection.CountDocuments(ctx, filter)
+	totalCount, err := clienteCollection.CountDocuments(ctx, filter, options.Count().SetMaxTime(searchMaxTime))
 	if err != nil {
 		log.Printf("Error obteniendo conteo de búsqueda: %v", err)
 		totalCount = int64(len(clientes))
@@ -667,6 +948,13 @@ func SearchClientes(c *gin.Context) {
 		Timestamp: time.Now().Unix(),
 	}
 
+	data, err := opts.present(clientes)
+	if err != nil {
+		log.Printf("Error proyectando resultados de búsqueda: %v", err)
+		sendErrorResponse(c, http.StatusInternalServerError, "Error preparando la respuesta", nil, nil)
+		return
+	}
+
 	message := fmt.Sprintf("Búsqueda completada: %d resultados encontrados", len(clientes))
-	sendSuccessResponse(c, http.StatusOK, message, clientes, meta)
+	sendSuccessResponse(c, http.StatusOK, message, data, meta)
 }
\ No newline at end of file
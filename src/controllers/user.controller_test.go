@@ -0,0 +1,268 @@
+// controllers/user.controller_test.go
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"api_compiladores/src/models"
+)
+
+// Cliente con errores de validación en Celular y Email
+const invalidClienteJSON = `{"Clave_Cliente":"101","Nombre":"Pedro Pérez","Celular":"123","Email":"no-es-email"}`
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// useMockCollections apunta las colecciones de los controllers al cliente
+// simulado de mtest y las restaura al terminar la prueba
+func useMockCollections(mt *mtest.T) {
+	clientes, audit, quarantine := clienteCollection, auditCollection, quarantineCollection
+	clienteCollection = mt.Coll
+	auditCollection = mt.DB.Collection("audit")
+	quarantineCollection = mt.DB.Collection("quarantine")
+	mt.Cleanup(func() {
+		clienteCollection, auditCollection, quarantineCollection = clientes, audit, quarantine
+	})
+}
+
+func newClienteRouter() *gin.Engine {
+	r := gin.New()
+	r.POST("/api/clientes", CreateCliente)
+	r.PUT("/api/clientes/:Clave_Cliente", UpdateCliente)
+	r.GET("/api/clientes/:Clave_Cliente/history", GetClienteHistory)
+	return r
+}
+
+func serve(r *gin.Engine, method, target, body string, header http.Header) (*httptest.ResponseRecorder, APIResponse) {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var response APIResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return w, response
+}
+
+// noDocuments simula un find sin resultados
+func noDocuments(mt *mtest.T, collection string) bson.D {
+	return mtest.CreateCursorResponse(0, mt.DB.Name()+"."+collection, mtest.FirstBatch)
+}
+
+// inserts devuelve los documentos insertados por colección, en orden
+func inserts(events []*event.CommandStartedEvent) map[string][]bson.Raw {
+	docs := map[string][]bson.Raw{}
+	for _, evt := range events {
+		if evt.CommandName != "insert" {
+			continue
+		}
+		collection := evt.Command.Lookup("insert").StringValue()
+		values, _ := evt.Command.Lookup("documents").Array().Values()
+		for _, value := range values {
+			docs[collection] = append(docs[collection], value.Document())
+		}
+	}
+	return docs
+}
+
+func TestCreateClientePolicies(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	tests := []struct {
+		name       string
+		policy     ValidationPolicy
+		responses  int // respuestas de escritura después del find de existencia
+		wantStatus int
+		// Colección que debe recibir el cliente; vacío si no se guarda
+		wantInsert string
+		wantAudit  bool
+	}{
+		{"store", PolicyStore, 2, http.StatusCreated, "", true},
+		{"reject", PolicyReject, 0, http.StatusUnprocessableEntity, "", false},
+		{"quarantine", PolicyQuarantine, 1, http.StatusAccepted, "quarantine", false},
+	}
+
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			useMockCollections(mt)
+			wantInsert := tt.wantInsert
+			if wantInsert == "" && tt.wantStatus == http.StatusCreated {
+				wantInsert = mt.Coll.Name()
+			}
+
+			responses := []bson.D{noDocuments(mt, mt.Coll.Name())}
+			for i := 0; i < tt.responses; i++ {
+				responses = append(responses, mtest.CreateSuccessResponse())
+			}
+			mt.AddMockResponses(responses...)
+
+			header := http.Header{ValidationPolicyHeader: {string(tt.policy)}}
+			w, response := serve(newClienteRouter(), http.MethodPost, "/api/clientes", invalidClienteJSON, header)
+			if w.Code != tt.wantStatus {
+				mt.Fatalf("status = %d, se esperaba %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if w.Code >= http.StatusBadRequest && response.Message == "" {
+				mt.Errorf("la respuesta de error no trae message: %s", w.Body.String())
+			}
+
+			docs := inserts(mt.GetAllStartedEvents())
+			if wantInsert == "" {
+				if len(docs) != 0 {
+					mt.Errorf("no se esperaban escrituras, hubo %v", docs)
+				}
+			} else if len(docs[wantInsert]) != 1 {
+				mt.Errorf("se esperaba un documento en %s, hubo %v", wantInsert, docs)
+			}
+
+			audits := docs["audit"]
+			if tt.wantAudit {
+				if len(audits) != 1 || audits[0].Lookup("operacion").StringValue() != models.AuditCreate {
+					mt.Errorf("se esperaba una auditoría de create, hubo %v", audits)
+				}
+			} else if len(audits) != 0 {
+				mt.Errorf("no se esperaba auditoría, hubo %v", audits)
+			}
+		})
+	}
+}
+
+func TestCreateClienteHistory(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("create then history", func(mt *mtest.T) {
+		useMockCollections(mt)
+		r := newClienteRouter()
+
+		mt.AddMockResponses(noDocuments(mt, mt.Coll.Name()), mtest.CreateSuccessResponse(), mtest.CreateSuccessResponse())
+		w, _ := serve(r, http.MethodPost, "/api/clientes", invalidClienteJSON, nil)
+		if w.Code != http.StatusCreated {
+			mt.Fatalf("status = %d: %s", w.Code, w.Body.String())
+		}
+
+		// El historial devuelve el registro que escribió el alta
+		audits := inserts(mt.GetAllStartedEvents())["audit"]
+		if len(audits) != 1 {
+			mt.Fatalf("se esperaba una auditoría, hubo %d", len(audits))
+		}
+		var registro bson.D
+		if err := bson.Unmarshal(audits[0], &registro); err != nil {
+			mt.Fatal(err)
+		}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, mt.DB.Name()+".audit", mtest.FirstBatch, registro))
+
+		w, _ = serve(r, http.MethodGet, "/api/clientes/101/history", "", nil)
+		if w.Code != http.StatusOK {
+			mt.Fatalf("status = %d: %s", w.Code, w.Body.String())
+		}
+		var historial []models.Auditoria
+		if err := json.Unmarshal(w.Body.Bytes(), &historial); err != nil {
+			mt.Fatal(err)
+		}
+		if len(historial) != 1 {
+			mt.Fatalf("se esperaba un registro, hubo %d", len(historial))
+		}
+		got := historial[0]
+		if got.Operacion != models.AuditCreate || got.Clave_Cliente != "101" {
+			mt.Errorf("registro = %s %s, se esperaba create 101", got.Operacion, got.Clave_Cliente)
+		}
+		if got.Antes != nil || got.Despues == nil || got.Despues.Nombre != "Pedro Pérez" {
+			mt.Errorf("el alta debe traer solo el cliente creado: antes=%v despues=%v", got.Antes, got.Despues)
+		}
+		if !got.Despues.Errores.HasErrors() {
+			mt.Errorf("el cliente creado debe conservar sus Errores")
+		}
+
+		// La consulta filtra por la clave del cliente
+		events := mt.GetAllStartedEvents()
+		find := events[len(events)-1]
+		if find.CommandName != "find" || find.Command.Lookup("filter", "Clave_Cliente").StringValue() != "101" {
+			mt.Errorf("consulta inesperada: %s %v", find.CommandName, find.Command)
+		}
+	})
+}
+
+func TestUpdateClienteConflicts(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	const body = `{"Nombre":"Pedro Pérez","Celular":"9613214782","Email":"pedro@example.com"}`
+
+	// Versión 3 del cliente, distinta del cuerpo del PUT
+	stored := func(mt *mtest.T) bson.D {
+		return mtest.CreateCursorResponse(0, mt.DB.Name()+"."+mt.Coll.Name(), mtest.FirstBatch, bson.D{
+			{Key: "Clave_Cliente", Value: "101"}, {Key: "Nombre", Value: "Ana López"}, {Key: "version", Value: int64(3)},
+		})
+	}
+	// findAndModify sin documento: otro editor cambió la versión
+	noMatch := mtest.CreateSuccessResponse(bson.E{Key: "value", Value: nil})
+
+	mt.Run("changed on every attempt without If-Match", func(mt *mtest.T) {
+		useMockCollections(mt)
+		var responses []bson.D
+		for i := 0; i < putMaxAttempts; i++ {
+			responses = append(responses, stored(mt), noMatch)
+		}
+		mt.AddMockResponses(append(responses, stored(mt))...)
+
+		w, response := serve(newClienteRouter(), http.MethodPut, "/api/clientes/101", body, nil)
+		if w.Code != http.StatusConflict {
+			mt.Fatalf("status = %d, se esperaba %d: %s", w.Code, http.StatusConflict, w.Body.String())
+		}
+		if _, ok := response.Error.(map[string]interface{})["if_match"]; ok {
+			mt.Errorf("sin If-Match la respuesta no debe traer if_match: %s", w.Body.String())
+		}
+		if got := len(commands(mt.GetAllStartedEvents(), "findAndModify")); got != putMaxAttempts {
+			mt.Errorf("se hicieron %d escrituras, se esperaban %d", got, putMaxAttempts)
+		}
+	})
+
+	mt.Run("changed with If-Match", func(mt *mtest.T) {
+		useMockCollections(mt)
+		mt.AddMockResponses(stored(mt), noMatch, stored(mt))
+
+		w, _ := serve(newClienteRouter(), http.MethodPut, "/api/clientes/101", body, http.Header{"If-Match": {`"3"`}})
+		if w.Code != http.StatusPreconditionFailed {
+			mt.Fatalf("status = %d, se esperaba %d: %s", w.Code, http.StatusPreconditionFailed, w.Body.String())
+		}
+	})
+
+	mt.Run("concurrent upsert", func(mt *mtest.T) {
+		useMockCollections(mt)
+		mt.AddMockResponses(
+			noDocuments(mt, mt.Coll.Name()),
+			mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 11000, Message: "E11000 duplicate key error"}),
+		)
+
+		w, _ := serve(newClienteRouter(), http.MethodPut, "/api/clientes/101?"+UpsertQuery+"=true", body, nil)
+		if w.Code != http.StatusConflict {
+			mt.Fatalf("status = %d, se esperaba %d: %s", w.Code, http.StatusConflict, w.Body.String())
+		}
+		if audits := inserts(mt.GetAllStartedEvents())["audit"]; len(audits) != 0 {
+			mt.Errorf("no se esperaba auditoría, hubo %v", audits)
+		}
+	})
+}
+
+// commands devuelve los comandos enviados con ese nombre
+func commands(events []*event.CommandStartedEvent, name string) []bson.Raw {
+	var found []bson.Raw
+	for _, evt := range events {
+		if evt.CommandName == name {
+			found = append(found, evt.Command)
+		}
+	}
+	return found
+}
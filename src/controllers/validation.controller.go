@@ -0,0 +1,99 @@
+// controllers/validation.controller.go
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"api_compiladores/src/models"
+	"api_compiladores/src/utils"
+)
+
+// Máximo de clientes por petición de validación
+const maxValidateBatch = 1000
+
+// Resultado de validación de un cliente dentro de la petición
+type ValidationResult struct {
+	Index    int                 `json:"index"`
+	Valid    bool                `json:"valid"`
+	Errores  models.Errores      `json:"Errores,omitempty"`
+	Mensajes map[string][]string `json:"Mensajes,omitempty"`
+}
+
+// ValidateClientes - Validar uno o varios clientes sin escribir en base de datos ni caché
+func ValidateClientes(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "No se pudo leer el cuerpo de la petición", err.Error(), &examplePut)
+		return
+	}
+
+	body = bytes.TrimSpace(body)
+	isArray := len(body) > 0 && body[0] == '['
+
+	var clientes []*models.Cliente
+	if isArray {
+		if err := json.Unmarshal(body, &clientes); err != nil {
+			sendErrorResponse(c, http.StatusBadRequest, "Datos JSON inválidos", err.Error(), []ExampleClientePut{examplePut})
+			return
+		}
+		if len(clientes) > maxValidateBatch {
+			sendErrorResponse(c, http.StatusBadRequest,
+				fmt.Sprintf("No se pueden validar más de %d clientes por petición", maxValidateBatch),
+				nil, nil)
+			return
+		}
+	} else {
+		var cliente models.Cliente
+		if err := json.Unmarshal(body, &cliente); err != nil {
+			sendErrorResponse(c, http.StatusBadRequest, "Datos JSON inválidos", err.Error(), &examplePut)
+			return
+		}
+		clientes = []*models.Cliente{&cliente}
+	}
+
+	// Un elemento null dentro del arreglo se valida como cliente vacío
+	for i := range clientes {
+		if clientes[i] == nil {
+			clientes[i] = &models.Cliente{}
+		}
+	}
+
+	todosErrores := utils.ValidateClientes(clientes)
+
+	// Los clientes que solo tienen advertencias siguen siendo válidos
+	conErrores := 0
+	results := make([]ValidationResult, len(clientes))
+	for i := range clientes {
+		errores := todosErrores[i]
+		if errores.HasErrors() {
+			conErrores++
+		}
+		results[i] = ValidationResult{
+			Index:    i,
+			Valid:    !errores.HasErrors(),
+			Errores:  errores,
+			Mensajes: errores.Messages(),
+		}
+	}
+
+	meta := &MetaInfo{
+		Total:     int64(len(clientes)),
+		Source:    "validator",
+		Timestamp: time.Now().Unix(),
+	}
+
+	if !isArray {
+		sendSuccessResponse(c, http.StatusOK, "Validación completada", results[0], meta)
+		return
+	}
+
+	sendSuccessResponse(c, http.StatusOK,
+		fmt.Sprintf("Validación completada: %d de %d clientes con errores", conErrores, len(clientes)),
+		results, meta)
+}
@@ -0,0 +1,142 @@
+// domains/database.go
+package domains
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Listas integradas; se pueden sustituir en tiempo de ejecución con LoadFile
+// o actualizar en disco con Import.
+var (
+	//go:embed data/public_suffix_list.dat
+	suffixData []byte
+
+	//go:embed data/disposable_domains.txt
+	disposableData []byte
+)
+
+// Kind identifica una de las listas de la base de dominios
+type Kind string
+
+const (
+	KindSuffixes   Kind = "suffixes"
+	KindDisposable Kind = "disposable"
+)
+
+// ParseKind valida el nombre de una lista
+func ParseKind(name string) (Kind, error) {
+	switch kind := Kind(name); kind {
+	case KindSuffixes, KindDisposable:
+		return kind, nil
+	}
+	return "", fmt.Errorf("lista de dominios desconocida: %q (disponibles: %s, %s)", name, KindSuffixes, KindDisposable)
+}
+
+var (
+	dbMu       sync.RWMutex
+	suffixes   *SuffixList
+	disposable *DomainSet
+)
+
+func init() {
+	var err error
+	if suffixes, err = ParseSuffixList(bytes.NewReader(suffixData)); err != nil {
+		panic(err)
+	}
+	if disposable, err = ParseDomainSet(bytes.NewReader(disposableData)); err != nil {
+		panic(err)
+	}
+}
+
+// Suffixes devuelve la lista de sufijos públicos en uso
+func Suffixes() *SuffixList {
+	dbMu.RLock()
+	defer dbMu.RUnlock()
+	return suffixes
+}
+
+// Disposable devuelve la lista de dominios desechables en uso
+func Disposable() *DomainSet {
+	dbMu.RLock()
+	defer dbMu.RUnlock()
+	return disposable
+}
+
+// parseFile lee y valida el archivo de una lista
+func parseFile(kind Kind, path string) (interface{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error abriendo lista de dominios: %w", err)
+	}
+	defer file.Close()
+
+	var list interface{}
+	switch kind {
+	case KindSuffixes:
+		list, err = ParseSuffixList(file)
+	case KindDisposable:
+		list, err = ParseDomainSet(file)
+	default:
+		_, err = ParseKind(string(kind))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return list, nil
+}
+
+// LoadFile reemplaza la lista integrada por una versión más reciente
+func LoadFile(kind Kind, path string) error {
+	list, err := parseFile(kind, path)
+	if err != nil {
+		return err
+	}
+
+	dbMu.Lock()
+	defer dbMu.Unlock()
+	switch list := list.(type) {
+	case *SuffixList:
+		suffixes = list
+	case *DomainSet:
+		disposable = list
+	}
+	return nil
+}
+
+// Import valida el archivo origen y lo copia a destino, donde LoadFile lo lee
+// en el siguiente arranque. Devuelve la cantidad de entradas importadas.
+func Import(kind Kind, source, destination string) (int, error) {
+	list, err := parseFile(kind, source)
+	if err != nil {
+		return 0, err
+	}
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return 0, fmt.Errorf("error leyendo %s: %w", source, err)
+	}
+
+	// Se escribe a un temporal y se renombra para no dejar una lista a medias
+	if err := os.MkdirAll(filepath.Dir(destination), 0o755); err != nil {
+		return 0, fmt.Errorf("error creando directorio de %s: %w", destination, err)
+	}
+	tmp := destination + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return 0, fmt.Errorf("error escribiendo %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, destination); err != nil {
+		return 0, fmt.Errorf("error reemplazando %s: %w", destination, err)
+	}
+
+	switch list := list.(type) {
+	case *SuffixList:
+		return list.Len(), nil
+	case *DomainSet:
+		return list.Len(), nil
+	}
+	return 0, nil
+}
@@ -0,0 +1,61 @@
+// domains/disposable.go
+package domains
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DomainSet es una lista de dominios, uno por línea ("#" para comentarios).
+// Un dominio de la lista cubre también a todos sus subdominios.
+type DomainSet struct {
+	domains map[string]bool
+}
+
+// ParseDomainSet lee una lista de dominios
+func ParseDomainSet(r io.Reader) (*DomainSet, error) {
+	set := &DomainSet{domains: make(map[string]bool)}
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		ascii, err := toASCII(strings.TrimSuffix(text, "."))
+		if err != nil || !strings.Contains(ascii, ".") {
+			return nil, fmt.Errorf("dominio inválido en la línea %d: %q", line, text)
+		}
+		set.domains[ascii] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error leyendo lista de dominios: %w", err)
+	}
+	if len(set.domains) == 0 {
+		return nil, fmt.Errorf("la lista de dominios está vacía")
+	}
+	return set, nil
+}
+
+// Match devuelve el dominio de la lista que cubre al dominio dado
+func (s *DomainSet) Match(domain string) (string, bool) {
+	domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+	for {
+		if s.domains[domain] {
+			return domain, true
+		}
+		dot := strings.IndexByte(domain, '.')
+		if dot < 0 {
+			return "", false
+		}
+		domain = domain[dot+1:]
+	}
+}
+
+func (s *DomainSet) Len() int {
+	return len(s.domains)
+}
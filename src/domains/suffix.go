@@ -0,0 +1,106 @@
+// domains/suffix.go
+package domains
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// SuffixList es una lista de sufijos públicos en el formato de publicsuffix.org:
+// una regla por línea, "*." para comodines, "!" para excepciones y "//" para
+// comentarios. Las reglas se guardan en su forma ASCII (punycode).
+type SuffixList struct {
+	rules      map[string]bool
+	wildcards  map[string]bool
+	exceptions map[string]bool
+}
+
+// ParseSuffixList lee una lista de sufijos públicos
+func ParseSuffixList(r io.Reader) (*SuffixList, error) {
+	list := &SuffixList{
+		rules:      make(map[string]bool),
+		wildcards:  make(map[string]bool),
+		exceptions: make(map[string]bool),
+	}
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		// Solo cuenta el texto hasta el primer espacio
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "//") {
+			continue
+		}
+		rule := fields[0]
+
+		target := list.rules
+		switch {
+		case strings.HasPrefix(rule, "!"):
+			rule, target = rule[1:], list.exceptions
+		case strings.HasPrefix(rule, "*."):
+			rule, target = rule[2:], list.wildcards
+		}
+
+		ascii, err := toASCII(rule)
+		if err != nil || ascii == "" || strings.Contains(ascii, "*") {
+			return nil, fmt.Errorf("regla inválida en la línea %d: %q", line, fields[0])
+		}
+		target[ascii] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error leyendo lista de sufijos: %w", err)
+	}
+	if len(list.rules)+len(list.wildcards) == 0 {
+		return nil, fmt.Errorf("la lista de sufijos está vacía")
+	}
+	return list, nil
+}
+
+// PublicSuffix devuelve el sufijo público del dominio según la regla que
+// prevalece (las excepciones primero, luego la regla con más etiquetas).
+// A diferencia del algoritmo de publicsuffix.org no se aplica la regla
+// implícita "*": si ninguna regla coincide el dominio no es plausible.
+func (l *SuffixList) PublicSuffix(domain string) (string, bool) {
+	labels := strings.Split(strings.TrimSuffix(strings.ToLower(domain), "."), ".")
+
+	// Se recorre de la etiqueta más a la izquierda a la derecha, así la
+	// primera coincidencia es la de más etiquetas
+	for i := range labels {
+		name := strings.Join(labels[i:], ".")
+		if l.exceptions[name] {
+			return strings.Join(labels[i+1:], "."), true
+		}
+		if i+1 < len(labels) && l.wildcards[strings.Join(labels[i+1:], ".")] {
+			return name, true
+		}
+		if l.rules[name] {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// RegistrableDomain devuelve el sufijo público más una etiqueta (eTLD+1).
+// Es falso cuando el sufijo no se conoce o el dominio es el sufijo mismo.
+func (l *SuffixList) RegistrableDomain(domain string) (string, bool) {
+	domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+	suffix, ok := l.PublicSuffix(domain)
+	if !ok || suffix == domain || suffix == "" {
+		return "", false
+	}
+	rest := strings.TrimSuffix(domain, "."+suffix)
+	return rest[strings.LastIndex(rest, ".")+1:] + "." + suffix, true
+}
+
+func (l *SuffixList) Len() int {
+	return len(l.rules) + len(l.wildcards) + len(l.exceptions)
+}
+
+func toASCII(domain string) (string, error) {
+	return idna.Lookup.ToASCII(strings.ToLower(domain))
+}
@@ -0,0 +1,585 @@
+// jobs/revalidation.go
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"api_compiladores/src/models"
+	"api_compiladores/src/utils"
+)
+
+// Tamaño de los lotes de la revalidación
+const (
+	DefaultBatchSize = 1000
+	MinBatchSize     = 100
+	MaxBatchSize     = 5000
+)
+
+// Un trabajo "running" sin latido en este tiempo se considera interrumpido
+// (el proceso que lo ejecutaba terminó) y se puede reanudar; es mayor que
+// batchTimeout para que un lote lento no parezca interrumpido
+const staleAfter = 5 * time.Minute
+
+// Tiempo máximo de cada lote (lectura, escritura y checkpoint)
+const batchTimeout = 2 * time.Minute
+
+var (
+	ErrJobRunning      = errors.New("ya hay una revalidación en curso")
+	ErrJobNotFound     = errors.New("trabajo de revalidación no encontrado")
+	ErrJobNotRunning   = errors.New("el trabajo de revalidación no está en curso")
+	ErrJobNotResumable = errors.New("el trabajo de revalidación no se puede reanudar")
+)
+
+// Revalidator ejecuta los trabajos de revalidación en segundo plano. El
+// estado de cada trabajo vive en MongoDB, así que se puede consultar,
+// cancelar y reanudar desde cualquier instancia.
+type Revalidator struct {
+	clientes *mongo.Collection
+	jobs     *mongo.Collection
+	// Auditoría de los clientes reescritos; nil si no se audita
+	audit *mongo.Collection
+
+	mu      sync.Mutex
+	cancels map[primitive.ObjectID]context.CancelFunc
+}
+
+func NewRevalidator(clientes, jobs, audit *mongo.Collection) *Revalidator {
+	return &Revalidator{
+		clientes: clientes,
+		jobs:     jobs,
+		audit:    audit,
+		cancels:  make(map[primitive.ObjectID]context.CancelFunc),
+	}
+}
+
+// EnsureIndexes crea el índice con el que se buscan los trabajos en curso y
+// se listan los más recientes, y el índice único que impide tener dos
+// trabajos en curso a la vez
+func (r *Revalidator) EnsureIndexes(ctx context.Context) error {
+	_, err := r.jobs.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "started_at", Value: -1}}},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}},
+			Options: options.Index().
+				SetName("status_running_unique").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"status": models.JobRunning}),
+		},
+	})
+	return err
+}
+
+// Start crea un trabajo y lo ejecuta en segundo plano. Falla con
+// ErrJobRunning si hay otro trabajo en curso, incluso uno interrumpido: ese
+// se reanuda en lugar de iniciar otro.
+func (r *Revalidator) Start(ctx context.Context, batchSize int, actor models.Actor) (*models.RevalidationJob, error) {
+	total, err := r.clientes.EstimatedDocumentCount(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error contando clientes: %w", err)
+	}
+	now := time.Now().UTC()
+	job := &models.RevalidationJob{
+		Status:      models.JobRunning,
+		BatchSize:   batchSize,
+		Total:       total,
+		RequestedBy: actor,
+		StartedAt:   now,
+		UpdatedAt:   now,
+	}
+	// El índice único sobre los trabajos en curso rechaza el segundo
+	result, err := r.jobs.InsertOne(ctx, job)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, ErrJobRunning
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error creando trabajo de revalidación: %w", err)
+	}
+	job.ID = result.InsertedID.(primitive.ObjectID)
+
+	r.launch(job)
+	return job, nil
+}
+
+// Resume reanuda desde su checkpoint un trabajo cancelado, fallido o
+// interrumpido (en curso pero sin latido). Falla con ErrJobRunning si otro
+// trabajo está en curso.
+func (r *Revalidator) Resume(ctx context.Context, id primitive.ObjectID) (*models.RevalidationJob, error) {
+	filter := bson.M{
+		"_id": id,
+		"$or": bson.A{
+			bson.M{"status": bson.M{"$in": bson.A{models.JobCancelled, models.JobFailed}}},
+			bson.M{"status": models.JobRunning, "updated_at": bson.M{"$lt": time.Now().Add(-staleAfter)}},
+		},
+	}
+	update := bson.M{
+		"$set":   bson.M{"status": models.JobRunning, "cancel_requested": false, "updated_at": time.Now().UTC()},
+		"$unset": bson.M{"error": "", "finished_at": ""},
+	}
+	var job models.RevalidationJob
+	err := r.jobs.FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&job)
+	if err == mongo.ErrNoDocuments {
+		if _, err := r.Get(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrJobNotResumable
+	}
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, ErrJobRunning
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reanudando trabajo de revalidación: %w", err)
+	}
+
+	r.launch(&job)
+	return &job, nil
+}
+
+// ResumeInterrupted reanuda los trabajos que quedaron en curso sin latido,
+// por ejemplo tras reiniciar el proceso. Devuelve cuántos reanudó.
+func (r *Revalidator) ResumeInterrupted(ctx context.Context) (int, error) {
+	stale := bson.M{
+		"status":     models.JobRunning,
+		"updated_at": bson.M{"$lt": time.Now().Add(-staleAfter)},
+	}
+
+	// Los que tenían una cancelación pendiente se dan por cancelados
+	now := time.Now().UTC()
+	_, err := r.jobs.UpdateMany(ctx,
+		bson.M{"$and": bson.A{stale, bson.M{"cancel_requested": true}}},
+		bson.M{"$set": bson.M{"status": models.JobCancelled, "updated_at": now, "finished_at": now}})
+	if err != nil {
+		return 0, fmt.Errorf("error cerrando trabajos cancelados: %w", err)
+	}
+
+	cursor, err := r.jobs.Find(ctx, stale)
+	if err != nil {
+		return 0, fmt.Errorf("error buscando trabajos interrumpidos: %w", err)
+	}
+	var interrupted []models.RevalidationJob
+	if err := cursor.All(ctx, &interrupted); err != nil {
+		return 0, fmt.Errorf("error leyendo trabajos interrumpidos: %w", err)
+	}
+
+	resumed := 0
+	for _, job := range interrupted {
+		if _, err := r.Resume(ctx, job.ID); err != nil {
+			log.Printf("No se pudo reanudar la revalidación %s: %v", job.ID.Hex(), err)
+			continue
+		}
+		resumed++
+	}
+	return resumed, nil
+}
+
+// WatchInterrupted reanuda los trabajos interrumpidos al iniciar y luego
+// periódicamente, porque un trabajo interrumpido por un reinicio rápido
+// todavía tiene un latido reciente. Termina cuando se cancela ctx.
+func (r *Revalidator) WatchInterrupted(ctx context.Context) {
+	ticker := time.NewTicker(staleAfter)
+	defer ticker.Stop()
+	for {
+		resumeCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		resumed, err := r.ResumeInterrupted(resumeCtx)
+		cancel()
+		if err != nil {
+			log.Printf("No se pudieron reanudar las revalidaciones: %v", err)
+		} else if resumed > 0 {
+			log.Printf("🔁 %d revalidaciones reanudadas", resumed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Cancel pide detener un trabajo en curso; se detiene al terminar el lote
+// actual (o de inmediato si corre en esta instancia)
+func (r *Revalidator) Cancel(ctx context.Context, id primitive.ObjectID) (*models.RevalidationJob, error) {
+	var job models.RevalidationJob
+	err := r.jobs.FindOneAndUpdate(ctx,
+		bson.M{"_id": id, "status": models.JobRunning},
+		bson.M{"$set": bson.M{"cancel_requested": true}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&job)
+	if err == mongo.ErrNoDocuments {
+		if _, err := r.Get(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrJobNotRunning
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error cancelando trabajo de revalidación: %w", err)
+	}
+
+	r.mu.Lock()
+	if cancel, ok := r.cancels[id]; ok {
+		cancel()
+	}
+	r.mu.Unlock()
+	return &job, nil
+}
+
+// Get devuelve el estado de un trabajo
+func (r *Revalidator) Get(ctx context.Context, id primitive.ObjectID) (*models.RevalidationJob, error) {
+	var job models.RevalidationJob
+	err := r.jobs.FindOne(ctx, bson.M{"_id": id}).Decode(&job)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo trabajo de revalidación: %w", err)
+	}
+	return &job, nil
+}
+
+// List devuelve los trabajos más recientes
+func (r *Revalidator) List(ctx context.Context, limit int64) ([]models.RevalidationJob, error) {
+	cursor, err := r.jobs.Find(ctx, bson.M{},
+		options.Find().SetSort(bson.D{{Key: "started_at", Value: -1}}).SetLimit(limit))
+	if err != nil {
+		return nil, fmt.Errorf("error listando trabajos de revalidación: %w", err)
+	}
+	jobs := []models.RevalidationJob{}
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return nil, fmt.Errorf("error leyendo trabajos de revalidación: %w", err)
+	}
+	return jobs, nil
+}
+
+func (r *Revalidator) launch(job *models.RevalidationJob) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.mu.Lock()
+	r.cancels[job.ID] = cancel
+	r.mu.Unlock()
+
+	go func() {
+		defer func() {
+			r.mu.Lock()
+			delete(r.cancels, job.ID)
+			r.mu.Unlock()
+			cancel()
+		}()
+		r.run(ctx, *job)
+	}()
+}
+
+// run recorre la colección por _id desde el checkpoint hasta terminar, ser
+// cancelado o fallar, y deja el estado final en el trabajo
+func (r *Revalidator) run(ctx context.Context, job models.RevalidationJob) {
+	log.Printf("🔁 Revalidación %s: inicia después de %s", job.ID.Hex(), checkpointLabel(job.Checkpoint))
+
+	status, message := models.JobCompleted, ""
+	for {
+		if ctx.Err() != nil {
+			status = models.JobCancelled
+			break
+		}
+		done, cancelled, err := r.runBatch(ctx, &job)
+		if err != nil {
+			if ctx.Err() != nil {
+				status = models.JobCancelled
+			} else {
+				status, message = models.JobFailed, err.Error()
+			}
+			break
+		}
+		if cancelled {
+			status = models.JobCancelled
+			break
+		}
+		if done {
+			break
+		}
+	}
+
+	// El contexto del trabajo puede estar cancelado; el estado final se
+	// escribe con uno propio
+	finishCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	now := time.Now().UTC()
+	set := bson.M{"status": status, "updated_at": now, "finished_at": now}
+	if message != "" {
+		set["error"] = message
+	}
+	if _, err := r.jobs.UpdateOne(finishCtx, bson.M{"_id": job.ID}, bson.M{"$set": set}); err != nil {
+		log.Printf("Error guardando el estado final de la revalidación %s: %v", job.ID.Hex(), err)
+	}
+	log.Printf("🔁 Revalidación %s: %s (%d procesados, %d cambiados, %d omitidos, %d fallidos) %s",
+		job.ID.Hex(), status, job.Processed, job.Changed, job.Skipped, job.Failed, message)
+}
+
+// runBatch revalida el siguiente lote, escribe los cambios y avanza el
+// checkpoint. done indica que ya no quedan clientes y cancelled que se pidió
+// cancelar el trabajo.
+func (r *Revalidator) runBatch(ctx context.Context, job *models.RevalidationJob) (done, cancelled bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, batchTimeout)
+	defer cancel()
+
+	// Se incluyen los clientes con baja lógica: al restaurarlos deben tener
+	// los Errores de las reglas vigentes
+	filter := bson.M{}
+	if job.Checkpoint != nil {
+		filter, err = afterID(job.Checkpoint)
+		if err != nil {
+			return false, false, fmt.Errorf("checkpoint inválido: %w", err)
+		}
+	}
+	cursor, err := r.clientes.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(job.BatchSize)))
+	if err != nil {
+		return false, false, fmt.Errorf("error leyendo lote: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var processed, failed int64
+	var last bson.RawValue
+	var writes []mongo.WriteModel
+	var pending []revalidation
+	var claves []string
+	for cursor.Next(ctx) {
+		processed++
+		// El _id puede ser de cualquier tipo; se copia porque el cursor
+		// reutiliza su memoria
+		id := cursor.Current.Lookup("_id")
+		last = bson.RawValue{Type: id.Type, Value: append([]byte(nil), id.Value...)}
+
+		var antes models.Cliente
+		if err := cursor.Decode(&antes); err != nil {
+			log.Printf("Revalidación %s: error decodificando %v: %v", job.ID.Hex(), cursor.Current.Lookup("_id"), err)
+			failed++
+			continue
+		}
+		despues := antes
+		utils.ValidateCliente(&despues)
+		if len(models.DiffClientes(&antes, &despues)) == 0 {
+			continue
+		}
+		despues.Version = antes.Version + 1
+
+		// La escritura exige la versión leída para no pisar un cambio
+		// concurrente; ese cambio ya revalidó al cliente
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": antes.ID, "version": versionFilter(antes.Version)}).
+			SetUpdate(bson.M{"$set": revalidatedSet(&despues), "$inc": bson.M{"version": 1}}))
+		pending = append(pending, revalidation{antes: antes, despues: despues})
+		claves = append(claves, fmt.Sprint(antes.Clave_Cliente))
+	}
+	if err := cursor.Err(); err != nil {
+		return false, false, fmt.Errorf("error recorriendo lote: %w", err)
+	}
+	if processed == 0 {
+		return true, false, nil
+	}
+
+	var changed, skipped int64
+	if len(writes) > 0 {
+		result, err := r.clientes.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+		if err != nil {
+			return false, false, fmt.Errorf("error escribiendo lote: %w", err)
+		}
+		changed = result.ModifiedCount
+		skipped = int64(len(writes)) - result.MatchedCount
+
+		// Lo escrito ya no se deshace, así que la auditoría usa un contexto
+		// que no se cancela con el trabajo
+		auditCtx, cancelAudit := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+		r.auditBatch(auditCtx, job, pending, skipped > 0)
+		cancelAudit()
+
+		if err := utils.InvalidateClientesCache(claves...); err != nil {
+			log.Printf("Error invalidando caché de la revalidación %s: %v", job.ID.Hex(), err)
+		}
+		utils.UpdateCacheStats("invalidate")
+	}
+
+	// Checkpoint y latido; de paso se lee si se pidió cancelar
+	var updated models.RevalidationJob
+	err = r.jobs.FindOneAndUpdate(ctx,
+		bson.M{"_id": job.ID},
+		bson.M{
+			"$set": bson.M{"checkpoint": last, "updated_at": time.Now().UTC()},
+			"$inc": bson.M{"processed": processed, "changed": changed, "skipped": skipped, "failed": failed},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err != nil {
+		return false, false, fmt.Errorf("error guardando checkpoint: %w", err)
+	}
+	*job = updated
+	return processed < int64(job.BatchSize), updated.CancelRequested, nil
+}
+
+// revalidation es un cliente que el lote reescribe, antes y después de validar
+type revalidation struct {
+	antes, despues models.Cliente
+}
+
+// auditBatch registra un cambio "revalidate" por cliente reescrito, con el
+// actor que inició el trabajo. Si la escritura omitió clientes (los cambió
+// otra petición entre la lectura y la escritura), solo se auditan los que
+// quedaron como los dejó el lote.
+func (r *Revalidator) auditBatch(ctx context.Context, job *models.RevalidationJob, pending []revalidation, partial bool) {
+	if r.audit == nil || len(pending) == 0 {
+		return
+	}
+	if partial {
+		applied, err := r.applied(ctx, pending)
+		if err != nil {
+			log.Printf("Revalidación %s: no se pudo revisar qué clientes se escribieron; %d cambios quedan sin auditar: %v",
+				job.ID.Hex(), len(pending), err)
+			return
+		}
+		pending = applied
+	}
+
+	now := time.Now().UTC()
+	docs := make([]interface{}, 0, len(pending))
+	for i := range pending {
+		antes, despues := &pending[i].antes, &pending[i].despues
+		docs = append(docs, models.Auditoria{
+			Clave_Cliente: fmt.Sprint(antes.Clave_Cliente),
+			Operacion:     models.AuditRevalidate,
+			Actor:         job.RequestedBy,
+			Fecha:         now,
+			Antes:         antes,
+			Despues:       despues,
+			Cambios:       models.DiffClientes(antes, despues),
+		})
+	}
+	if len(docs) == 0 {
+		return
+	}
+	if _, err := r.audit.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false)); err != nil {
+		log.Printf("Revalidación %s: error guardando %d registro(s) de auditoría: %v", job.ID.Hex(), len(docs), err)
+	}
+}
+
+// applied devuelve los clientes cuyo documento guardado es el que escribió
+// el lote
+func (r *Revalidator) applied(ctx context.Context, pending []revalidation) ([]revalidation, error) {
+	ids := make([]primitive.ObjectID, len(pending))
+	for i := range pending {
+		ids[i] = pending[i].antes.ID
+	}
+	cursor, err := r.clientes.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	var stored []models.Cliente
+	if err := cursor.All(ctx, &stored); err != nil {
+		return nil, err
+	}
+	byID := make(map[primitive.ObjectID]*models.Cliente, len(stored))
+	for i := range stored {
+		byID[stored[i].ID] = &stored[i]
+	}
+
+	var applied []revalidation
+	for _, p := range pending {
+		if actual, ok := byID[p.antes.ID]; ok && len(models.DiffClientes(actual, &p.despues)) == 0 {
+			applied = append(applied, p)
+		}
+	}
+	return applied, nil
+}
+
+// revalidatedSet es el $set de los campos que recalcula la validación
+func revalidatedSet(cliente *models.Cliente) bson.M {
+	return bson.M{
+		"Errores":            cliente.Errores,
+		"Mensajes":           cliente.Mensajes,
+		"NombreBusqueda":     cliente.NombreBusqueda,
+		"EmailBusqueda":      cliente.EmailBusqueda,
+		"NombreNormalizado":  cliente.NombreNormalizado,
+		"NombrePila":         cliente.NombrePila,
+		"ApellidoPaterno":    cliente.ApellidoPaterno,
+		"ApellidoMaterno":    cliente.ApellidoMaterno,
+		"CelularNormalizado": cliente.CelularNormalizado,
+		"CelularE164":        cliente.CelularE164,
+		"CelularNacional":    cliente.CelularNacional,
+		"CelularPais":        cliente.CelularPais,
+		"Region":             cliente.Region,
+	}
+}
+
+// versionFilter es la condición de la versión leída; los clientes anteriores
+// al versionado no tienen el campo y cuentan como versión 0
+func versionFilter(version int64) interface{} {
+	if version == 0 {
+		return bson.M{"$in": bson.A{0, nil}}
+	}
+	return version
+}
+
+// Orden de los tipos BSON al ordenar por _id (un _id no puede ser arreglo);
+// los tipos de un mismo grupo se comparan entre sí
+var idTypeOrder = [][]bsontype.Type{
+	{bsontype.Null},
+	{bsontype.Int32, bsontype.Int64, bsontype.Double, bsontype.Decimal128},
+	{bsontype.String, bsontype.Symbol},
+	{bsontype.EmbeddedDocument},
+	{bsontype.Binary},
+	{bsontype.ObjectID},
+	{bsontype.Boolean},
+	{bsontype.DateTime},
+	{bsontype.Timestamp},
+	{bsontype.Regex},
+}
+
+// afterID es el filtro de los _id que van después del checkpoint al ordenar.
+// $gt solo compara con valores del mismo tipo, así que también se incluyen
+// los _id de los tipos que se ordenan después.
+func afterID(checkpoint interface{}) (bson.M, error) {
+	t, _, err := bson.MarshalValue(checkpoint)
+	if err != nil {
+		return nil, err
+	}
+	var later bson.A
+	seen := false
+	for _, group := range idTypeOrder {
+		if seen {
+			for _, next := range group {
+				later = append(later, int32(next))
+			}
+			continue
+		}
+		for _, current := range group {
+			seen = seen || current == t
+		}
+	}
+	if !seen {
+		return nil, fmt.Errorf("tipo de _id no soportado: %s", t)
+	}
+
+	greater := bson.M{"_id": bson.M{"$gt": checkpoint}}
+	if len(later) == 0 {
+		return greater, nil
+	}
+	return bson.M{"$or": bson.A{greater, bson.M{"_id": bson.M{"$type": later}}}}, nil
+}
+
+func checkpointLabel(checkpoint interface{}) string {
+	switch cp := checkpoint.(type) {
+	case nil:
+		return "el inicio"
+	case primitive.ObjectID:
+		return cp.Hex()
+	default:
+		return fmt.Sprint(cp)
+	}
+}
@@ -0,0 +1,283 @@
+// jobs/revalidation_test.go
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"api_compiladores/src/models"
+	"api_compiladores/src/utils"
+)
+
+func newTestRevalidator(mt *mtest.T) *Revalidator {
+	return NewRevalidator(mt.Coll, mt.DB.Collection("jobs"), mt.DB.Collection("audit"))
+}
+
+func testJob(batchSize int) *models.RevalidationJob {
+	return &models.RevalidationJob{
+		ID:          primitive.NewObjectID(),
+		Status:      models.JobRunning,
+		BatchSize:   batchSize,
+		RequestedBy: models.Actor{Subject: "ops", Role: "admin", Method: "api_key"},
+	}
+}
+
+// jobResponse simula la respuesta de findAndModify con el trabajo actualizado
+func jobResponse(job *models.RevalidationJob) bson.D {
+	doc, err := bson.Marshal(job)
+	if err != nil {
+		panic(err)
+	}
+	return mtest.CreateSuccessResponse(bson.E{Key: "value", Value: bson.Raw(doc)})
+}
+
+// commands devuelve los comandos enviados con ese nombre
+func commands(events []*event.CommandStartedEvent, name string) []bson.Raw {
+	var found []bson.Raw
+	for _, evt := range events {
+		if evt.CommandName == name {
+			found = append(found, evt.Command)
+		}
+	}
+	return found
+}
+
+// Clientes sin Errores guardados: validarlos siempre produce cambios
+func staleCliente(clave string) bson.D {
+	return bson.D{
+		{Key: "_id", Value: primitive.NewObjectID()},
+		{Key: "Clave_Cliente", Value: clave},
+		{Key: "Nombre", Value: "Pedro Pérez"},
+		{Key: "Celular", Value: "123"},
+		{Key: "Email", Value: "no-es-email"},
+		{Key: "version", Value: int64(3)},
+	}
+}
+
+func TestRunBatchAuditsRevalidatedClientes(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("one audit per modified cliente", func(mt *mtest.T) {
+		r := newTestRevalidator(mt)
+		job := testJob(10)
+		ns := mt.DB.Name() + "." + mt.Coll.Name()
+
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, staleCliente("101"), staleCliente("102")),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}, bson.E{Key: "nModified", Value: 2}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}),
+			jobResponse(job),
+		)
+
+		done, cancelled, err := r.runBatch(context.Background(), job)
+		if err != nil {
+			mt.Fatal(err)
+		}
+		if !done || cancelled {
+			mt.Errorf("done=%v cancelled=%v, se esperaba done sin cancelar", done, cancelled)
+		}
+
+		inserts := commands(mt.GetAllStartedEvents(), "insert")
+		if len(inserts) != 1 || inserts[0].Lookup("insert").StringValue() != "audit" {
+			mt.Fatalf("se esperaba un insert en audit, hubo %v", inserts)
+		}
+		docs, _ := inserts[0].Lookup("documents").Array().Values()
+		if len(docs) != 2 {
+			mt.Fatalf("se esperaban 2 auditorías, hubo %d", len(docs))
+		}
+		for _, doc := range docs {
+			registro := doc.Document()
+			if op := registro.Lookup("operacion").StringValue(); op != models.AuditRevalidate {
+				mt.Errorf("operación = %s, se esperaba %s", op, models.AuditRevalidate)
+			}
+			if subject := registro.Lookup("actor", "subject").StringValue(); subject != "ops" {
+				mt.Errorf("actor = %s, se esperaba ops", subject)
+			}
+			if version := registro.Lookup("despues", "version").Int64(); version != 4 {
+				mt.Errorf("versión después = %d, se esperaba 4", version)
+			}
+		}
+	})
+
+	mt.Run("skips clientes changed concurrently", func(mt *mtest.T) {
+		r := newTestRevalidator(mt)
+		job := testJob(10)
+		ns := mt.DB.Name() + "." + mt.Coll.Name()
+		revalidado, concurrente := staleCliente("101"), staleCliente("102")
+
+		// Otra petición cambió el 102 entre la lectura y la escritura
+		movido := append(bson.D{}, concurrente...)
+		movido[3] = bson.E{Key: "Celular", Value: "9613214782"}
+		movido[5] = bson.E{Key: "version", Value: int64(4)}
+
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, revalidado, concurrente),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, revalidated(mt, revalidado), movido),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			jobResponse(job),
+		)
+
+		if _, _, err := r.runBatch(context.Background(), job); err != nil {
+			mt.Fatal(err)
+		}
+		inserts := commands(mt.GetAllStartedEvents(), "insert")
+		if len(inserts) != 1 {
+			mt.Fatalf("se esperaba un insert de auditoría, hubo %d", len(inserts))
+		}
+		docs, _ := inserts[0].Lookup("documents").Array().Values()
+		if len(docs) != 1 || docs[0].Document().Lookup("Clave_Cliente").StringValue() != "101" {
+			mt.Errorf("se esperaba auditar solo el 101, hubo %v", docs)
+		}
+	})
+}
+
+// revalidated es el documento que deja guardado el lote para doc
+func revalidated(mt *mtest.T, doc bson.D) bson.D {
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		mt.Fatal(err)
+	}
+	var cliente models.Cliente
+	if err := bson.Unmarshal(data, &cliente); err != nil {
+		mt.Fatal(err)
+	}
+	utils.ValidateCliente(&cliente)
+	cliente.Version++
+
+	data, err = bson.Marshal(cliente)
+	if err != nil {
+		mt.Fatal(err)
+	}
+	var stored bson.D
+	if err := bson.Unmarshal(data, &stored); err != nil {
+		mt.Fatal(err)
+	}
+	return stored
+}
+
+func TestAfterID(t *testing.T) {
+	id := primitive.NewObjectID()
+	tests := []struct {
+		name       string
+		checkpoint interface{}
+		// Tipos que se incluyen completos por ordenarse después
+		wantLater []bsontype.Type
+	}{
+		{"ObjectID", id, []bsontype.Type{bsontype.Boolean, bsontype.DateTime, bsontype.Timestamp, bsontype.Regex}},
+		{"cadena", "C-0101", []bsontype.Type{bsontype.EmbeddedDocument, bsontype.Binary, bsontype.ObjectID, bsontype.Boolean, bsontype.DateTime, bsontype.Timestamp, bsontype.Regex}},
+		{"entero", int32(101), []bsontype.Type{bsontype.String, bsontype.Symbol, bsontype.EmbeddedDocument, bsontype.Binary, bsontype.ObjectID, bsontype.Boolean, bsontype.DateTime, bsontype.Timestamp, bsontype.Regex}},
+		{"regex", primitive.Regex{Pattern: "^a"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := afterID(tt.checkpoint)
+			if err != nil {
+				t.Fatal(err)
+			}
+			greater := bson.M{"_id": bson.M{"$gt": tt.checkpoint}}
+			if tt.wantLater == nil {
+				if !reflect.DeepEqual(filter, greater) {
+					t.Errorf("filtro = %v, se esperaba %v", filter, greater)
+				}
+				return
+			}
+			var later bson.A
+			for _, next := range tt.wantLater {
+				later = append(later, int32(next))
+			}
+			want := bson.M{"$or": bson.A{greater, bson.M{"_id": bson.M{"$type": later}}}}
+			if !reflect.DeepEqual(filter, want) {
+				t.Errorf("filtro = %v, se esperaba %v", filter, want)
+			}
+		})
+	}
+
+	if _, err := afterID(bson.A{1}); err == nil {
+		t.Error("se aceptó un arreglo como checkpoint")
+	}
+}
+
+func TestRunBatchNonObjectIDCheckpoint(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	// Un _id que no es ObjectID no se puede leer como Cliente y cuenta como
+	// fallido, pero el checkpoint debe avanzar para no releerlo siempre
+	mt.Run("advances past string _id", func(mt *mtest.T) {
+		r := newTestRevalidator(mt)
+		job := testJob(MinBatchSize)
+		job.Checkpoint = "C-0100"
+		ns := mt.DB.Name() + "." + mt.Coll.Name()
+
+		cliente := revalidated(mt, staleCliente("101"))
+		cliente[0] = bson.E{Key: "_id", Value: "C-0101"}
+		updated := *job
+		updated.Checkpoint = "C-0101"
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, cliente),
+			jobResponse(&updated),
+		)
+
+		if _, _, err := r.runBatch(context.Background(), job); err != nil {
+			mt.Fatal(err)
+		}
+		events := mt.GetAllStartedEvents()
+		find := commands(events, "find")[0]
+		filter, err := afterID("C-0100")
+		if err != nil {
+			mt.Fatal(err)
+		}
+		want, _ := bson.Marshal(filter)
+		if !bytes.Equal(find.Lookup("filter").Document(), want) {
+			mt.Errorf("filtro = %s, se esperaba %s", find.Lookup("filter"), bson.Raw(want))
+		}
+
+		update := commands(events, "findAndModify")[0].Lookup("update").Document()
+		if failed := update.Lookup("$inc", "failed").Int64(); failed != 1 {
+			mt.Errorf("fallidos = %d, se esperaba 1", failed)
+		}
+		checkpoint := update.Lookup("$set", "checkpoint")
+		if value, ok := checkpoint.StringValueOK(); !ok || value != "C-0101" {
+			mt.Errorf("checkpoint = %s, se esperaba C-0101", checkpoint)
+		}
+		if job.Checkpoint != "C-0101" {
+			mt.Errorf("checkpoint del trabajo = %v, se esperaba C-0101", job.Checkpoint)
+		}
+	})
+}
+
+func TestSingleRunningJob(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	duplicate := mtest.WriteError{Index: 0, Code: 11000, Message: "E11000 duplicate key error index: status_running_unique"}
+
+	mt.Run("start", func(mt *mtest.T) {
+		r := newTestRevalidator(mt)
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 10}),
+			mtest.CreateWriteErrorsResponse(duplicate),
+		)
+		if _, err := r.Start(context.Background(), MinBatchSize, models.Actor{Subject: "ops"}); !errors.Is(err, ErrJobRunning) {
+			mt.Errorf("error = %v, se esperaba %v", err, ErrJobRunning)
+		}
+	})
+
+	mt.Run("resume", func(mt *mtest.T) {
+		r := newTestRevalidator(mt)
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code: int32(duplicate.Code), Message: duplicate.Message,
+		}))
+		if _, err := r.Resume(context.Background(), primitive.NewObjectID()); !errors.Is(err, ErrJobRunning) {
+			mt.Errorf("error = %v, se esperaba %v", err, ErrJobRunning)
+		}
+	})
+}
@@ -0,0 +1,121 @@
+// lexer/lexer.go
+package lexer
+
+import "unicode"
+
+// Mode indica qué campo se está analizando, ya que cada campo
+// agrupa los caracteres de forma distinta
+type Mode int
+
+const (
+	ModeNombre Mode = iota
+	ModeCelular
+	ModeEmail
+)
+
+// Lexer recorre un campo runa por runa y produce tokens con su posición
+type Lexer struct {
+	input   []rune
+	pos     int
+	mode    Mode
+	afterAt bool
+}
+
+func New(input string, mode Mode) *Lexer {
+	return &Lexer{input: []rune(input), mode: mode}
+}
+
+// Next devuelve el siguiente token; el segundo valor es false al llegar al final
+func (l *Lexer) Next() (Token, bool) {
+	if l.pos >= len(l.input) {
+		return Token{}, false
+	}
+
+	start := l.pos
+	r := l.input[l.pos]
+
+	switch {
+	case unicode.IsSpace(r):
+		l.consume(unicode.IsSpace)
+		return l.emit(Space, start), true
+	case l.mode == ModeEmail && l.afterAt && isDomainRune(r):
+		l.consume(isDomainRune)
+		return l.emit(DomainLabel, start), true
+	case l.mode == ModeEmail && isLetter(r):
+		l.consume(func(r rune) bool { return isLetter(r) || unicode.IsDigit(r) })
+		return l.emit(Word, start), true
+	case isLetter(r):
+		l.consume(isLetter)
+		return l.emit(Word, start), true
+	case unicode.IsDigit(r):
+		l.consume(unicode.IsDigit)
+		return l.emit(DigitRun, start), true
+	}
+
+	l.pos++
+	switch r {
+	case '@':
+		if l.mode == ModeEmail {
+			l.afterAt = true
+		}
+		return l.emit(At, start), true
+	case '.':
+		return l.emit(Dot, start), true
+	case '-':
+		return l.emit(Hyphen, start), true
+	case '_':
+		return l.emit(Underscore, start), true
+	case '+':
+		return l.emit(Plus, start), true
+	}
+	return l.emit(Symbol, start), true
+}
+
+func (l *Lexer) consume(accept func(rune) bool) {
+	for l.pos < len(l.input) && accept(l.input[l.pos]) {
+		l.pos++
+	}
+}
+
+func (l *Lexer) emit(t TokenType, start int) Token {
+	return Token{
+		Type:   t,
+		Value:  string(l.input[start:l.pos]),
+		Offset: start,
+		Length: l.pos - start,
+	}
+}
+
+// Tokenize analiza el campo completo y devuelve todos sus tokens
+func Tokenize(input string, mode Mode) []Token {
+	l := New(input, mode)
+	var tokens []Token
+	for {
+		tok, ok := l.Next()
+		if !ok {
+			return tokens
+		}
+		tokens = append(tokens, tok)
+	}
+}
+
+func TokenizeNombre(input string) []Token {
+	return Tokenize(input, ModeNombre)
+}
+
+func TokenizeCelular(input string) []Token {
+	return Tokenize(input, ModeCelular)
+}
+
+func TokenizeEmail(input string) []Token {
+	return Tokenize(input, ModeEmail)
+}
+
+// Las marcas diacríticas combinables se consideran parte de la palabra
+func isLetter(r rune) bool {
+	return unicode.IsLetter(r) || unicode.Is(unicode.Mn, r)
+}
+
+func isDomainRune(r rune) bool {
+	return isLetter(r) || unicode.IsDigit(r) || r == '-'
+}
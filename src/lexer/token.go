@@ -0,0 +1,55 @@
+// lexer/token.go
+package lexer
+
+// TokenType identifica la categoría léxica de un token
+type TokenType int
+
+const (
+	Illegal     TokenType = iota
+	Word                  // secuencia de letras (o letras y dígitos en la parte local de un email)
+	Space                 // secuencia de espacios en blanco
+	DigitRun              // secuencia de dígitos
+	At                    // símbolo @
+	Dot                   // punto
+	Hyphen                // guión
+	Underscore            // guión bajo
+	Plus                  // signo +
+	DomainLabel           // etiqueta de dominio después del @
+	Symbol                // cualquier otro carácter
+)
+
+var tokenNames = map[TokenType]string{
+	Illegal:     "ILLEGAL",
+	Word:        "WORD",
+	Space:       "SPACE",
+	DigitRun:    "DIGIT_RUN",
+	At:          "AT",
+	Dot:         "DOT",
+	Hyphen:      "HYPHEN",
+	Underscore:  "UNDERSCORE",
+	Plus:        "PLUS",
+	DomainLabel: "DOMAIN_LABEL",
+	Symbol:      "SYMBOL",
+}
+
+func (t TokenType) String() string {
+	if name, ok := tokenNames[t]; ok {
+		return name
+	}
+	return "ILLEGAL"
+}
+
+// Token representa un lexema con su posición dentro del campo.
+// Offset y Length se expresan en runas, no en bytes, para que el
+// frontend pueda subrayar el fragmento exacto.
+type Token struct {
+	Type   TokenType
+	Value  string
+	Offset int
+	Length int
+}
+
+// End devuelve la posición (en runas) inmediatamente después del token
+func (t Token) End() int {
+	return t.Offset + t.Length
+}
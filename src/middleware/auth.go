@@ -0,0 +1,98 @@
+// middleware/auth.go
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"api_compiladores/src/auth"
+)
+
+// APIKeyHeader es el header con la llave de API estática
+const APIKeyHeader = "X-API-Key"
+
+// Llave del Principal en el contexto de gin
+const principalKey = "auth.principal"
+
+// Authenticate resuelve el Principal con "Authorization: Bearer <jwt>" o con
+// una llave de API (header X-API-Key o "Authorization: ApiKey <llave>") y lo
+// deja en el contexto para los handlers. Sin métodos configurados las rutas
+// quedan deshabilitadas en lugar de abiertas.
+func Authenticate(authenticator *auth.Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !authenticator.Configured() {
+			abort(c, http.StatusServiceUnavailable, "La API no tiene métodos de autenticación configurados", nil)
+			return
+		}
+
+		var principal auth.Principal
+		var err error
+		scheme, value, _ := strings.Cut(c.GetHeader("Authorization"), " ")
+		value = strings.TrimSpace(value)
+		switch {
+		case strings.EqualFold(scheme, "Bearer") && value != "":
+			principal, err = authenticator.AuthenticateToken(value)
+		case c.GetHeader(APIKeyHeader) != "":
+			principal, err = authenticator.AuthenticateAPIKey(c.GetHeader(APIKeyHeader))
+		case strings.EqualFold(scheme, "ApiKey") && value != "":
+			principal, err = authenticator.AuthenticateAPIKey(value)
+		default:
+			challenge(c, authenticator)
+			abort(c, http.StatusUnauthorized, "Faltan las credenciales: usa Authorization: Bearer <token> o "+APIKeyHeader, nil)
+			return
+		}
+
+		if err != nil {
+			challenge(c, authenticator)
+			message := "Credenciales inválidas"
+			if errors.Is(err, auth.ErrTokenExpired) {
+				message = "El token expiró"
+			}
+			abort(c, http.StatusUnauthorized, message, err.Error())
+			return
+		}
+
+		c.Set(principalKey, principal)
+		c.Next()
+	}
+}
+
+// RequireRole exige que el Principal tenga al menos el rol indicado; va
+// después de Authenticate
+func RequireRole(required auth.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := CurrentPrincipal(c)
+		if !ok {
+			abort(c, http.StatusUnauthorized, "La petición no está autenticada", nil)
+			return
+		}
+		if !principal.Role.Allows(required) {
+			abort(c, http.StatusForbidden, "Permisos insuficientes", gin.H{
+				"required_role": required,
+				"role":          principal.Role,
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// CurrentPrincipal devuelve el usuario autenticado de la petición
+func CurrentPrincipal(c *gin.Context) (auth.Principal, bool) {
+	value, ok := c.Get(principalKey)
+	if !ok {
+		return auth.Principal{}, false
+	}
+	principal, ok := value.(auth.Principal)
+	return principal, ok
+}
+
+// challenge anuncia en WWW-Authenticate los esquemas aceptados (RFC 6750)
+func challenge(c *gin.Context, authenticator *auth.Authenticator) {
+	if authenticator.AcceptsJWT() {
+		c.Header("WWW-Authenticate", `Bearer realm="api"`)
+	}
+}
@@ -0,0 +1,165 @@
+// middleware/auth_test.go
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"api_compiladores/src/auth"
+)
+
+const testSecret = "secreto-de-pruebas-con-al-menos-32-bytes"
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// hs256Token firma un token con el secreto de pruebas
+func hs256Token(t *testing.T, claims map[string]interface{}) string {
+	t.Helper()
+	segment := func(value interface{}) string {
+		data, err := json.Marshal(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signed := segment(map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + segment(claims)
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func newTestAuthenticator(t *testing.T) *auth.Authenticator {
+	t.Helper()
+	secretFile := filepath.Join(t.TempDir(), "hs256.key")
+	if err := os.WriteFile(secretFile, []byte(testSecret), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := auth.NewJWTVerifier(secretFile, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := auth.ParseAPIKeys("lector:reader:llave-reader,capturista:editor:llave-editor,ops:admin:llave-admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return auth.NewAuthenticator(verifier, keys)
+}
+
+// newRoleRouter monta una ruta por rol requerido
+func newRoleRouter(authenticator *auth.Authenticator) *gin.Engine {
+	r := gin.New()
+	group := r.Group("/", Authenticate(authenticator))
+	ok := func(c *gin.Context) {
+		principal, _ := CurrentPrincipal(c)
+		c.String(http.StatusOK, principal.Subject)
+	}
+	group.GET("/reader", RequireRole(auth.RoleReader), ok)
+	group.GET("/editor", RequireRole(auth.RoleEditor), ok)
+	group.GET("/admin", RequireRole(auth.RoleAdmin), ok)
+	return r
+}
+
+func request(r *gin.Engine, path string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuthenticate(t *testing.T) {
+	r := newRoleRouter(newTestAuthenticator(t))
+	exp := time.Now().Add(time.Hour).Unix()
+
+	tests := []struct {
+		name        string
+		header      http.Header
+		wantStatus  int
+		wantSubject string
+		// Texto que debe aparecer en la respuesta de error
+		wantMessage string
+	}{
+		{"bearer", http.Header{"Authorization": {"Bearer " + hs256Token(t, map[string]interface{}{"sub": "ana", "role": "reader", "exp": exp})}}, http.StatusOK, "ana", ""},
+		{"header de llave", http.Header{APIKeyHeader: {"llave-reader"}}, http.StatusOK, "lector", ""},
+		{"esquema ApiKey", http.Header{"Authorization": {"ApiKey llave-reader"}}, http.StatusOK, "lector", ""},
+		{"sin credenciales", nil, http.StatusUnauthorized, "", "Faltan las credenciales"},
+		{"llave inválida", http.Header{APIKeyHeader: {"llave-otra"}}, http.StatusUnauthorized, "", "Credenciales inválidas"},
+		{"token expirado", http.Header{"Authorization": {"Bearer " + hs256Token(t, map[string]interface{}{"sub": "ana", "role": "reader", "exp": time.Now().Add(-time.Hour).Unix()})}}, http.StatusUnauthorized, "", "El token expiró"},
+		{"token sin exp", http.Header{"Authorization": {"Bearer " + hs256Token(t, map[string]interface{}{"sub": "ana", "role": "reader"})}}, http.StatusUnauthorized, "", "Credenciales inválidas"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := request(r, "/reader", tt.header)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, se esperaba %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantSubject != "" && w.Body.String() != tt.wantSubject {
+				t.Errorf("subject = %q, se esperaba %q", w.Body.String(), tt.wantSubject)
+			}
+			if tt.wantMessage != "" && !strings.Contains(w.Body.String(), tt.wantMessage) {
+				t.Errorf("respuesta %s no contiene %q", w.Body.String(), tt.wantMessage)
+			}
+			if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("falta el encabezado WWW-Authenticate")
+			}
+		})
+	}
+}
+
+func TestAuthenticateNotConfigured(t *testing.T) {
+	r := newRoleRouter(auth.NewAuthenticator(nil, nil))
+	if w := request(r, "/reader", http.Header{APIKeyHeader: {"llave-reader"}}); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, se esperaba %d", w.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	r := newRoleRouter(newTestAuthenticator(t))
+	keys := map[string]string{"reader": "llave-reader", "editor": "llave-editor", "admin": "llave-admin"}
+
+	// Estado esperado por rol del usuario y rol requerido por la ruta
+	tests := []struct {
+		role string
+		want map[string]int
+	}{
+		{"reader", map[string]int{"/reader": http.StatusOK, "/editor": http.StatusForbidden, "/admin": http.StatusForbidden}},
+		{"editor", map[string]int{"/reader": http.StatusOK, "/editor": http.StatusOK, "/admin": http.StatusForbidden}},
+		{"admin", map[string]int{"/reader": http.StatusOK, "/editor": http.StatusOK, "/admin": http.StatusOK}},
+	}
+
+	for _, tt := range tests {
+		for path, want := range tt.want {
+			t.Run(tt.role+path, func(t *testing.T) {
+				w := request(r, path, http.Header{APIKeyHeader: {keys[tt.role]}})
+				if w.Code != want {
+					t.Errorf("status = %d, se esperaba %d: %s", w.Code, want, w.Body.String())
+				}
+			})
+		}
+	}
+
+	// Sin Authenticate antes no hay Principal
+	bare := gin.New()
+	bare.GET("/", RequireRole(auth.RoleReader), func(c *gin.Context) { c.Status(http.StatusOK) })
+	if w := request(bare, "/", nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("sin Principal: status = %d, se esperaba %d", w.Code, http.StatusUnauthorized)
+	}
+}
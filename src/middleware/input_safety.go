@@ -0,0 +1,67 @@
+// middleware/input_safety.go
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"api_compiladores/src/sanitize"
+)
+
+// Tamaño máximo del cuerpo que se revisa (y que se acepta)
+const maxBodyBytes = 8 << 20
+
+// InputSafety revisa cada petición antes de llegar al controller: los
+// parámetros de ruta y de query no pueden traer operadores de MongoDB,
+// etiquetas HTML, esquemas ejecutables ni caracteres de control, y los
+// cuerpos no pueden traer claves de operador ni objetos en Clave_Cliente.
+// Se revisa todo cuerpo sin importar su Content-Type, porque los handlers lo
+// decodifican como JSON aunque llegue como text/plain o sin encabezado.
+// El contenido de los campos del cliente lo revisan las reglas de validación
+// (nombre.injection, email.injection) para reportarlo con su posición.
+func InputSafety() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		findings := make(map[string][]sanitize.Finding)
+
+		for _, param := range c.Params {
+			if found := sanitize.ScanParam(param.Value); len(found) > 0 {
+				findings["path."+param.Key] = found
+			}
+		}
+		for key, values := range c.Request.URL.Query() {
+			// Claves como nombre[$ne] intentan formar un objeto en el filtro
+			if strings.Contains(key, "$") {
+				findings["query."+key] = append(findings["query."+key],
+					sanitize.Finding{Kind: sanitize.KindOperator, Length: len([]rune(key)), Token: key})
+			}
+			for _, value := range values {
+				if found := sanitize.ScanParam(value); len(found) > 0 {
+					findings["query."+key] = append(findings["query."+key], found...)
+				}
+			}
+		}
+
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodyBytes))
+			if err != nil {
+				abort(c, http.StatusRequestEntityTooLarge, "No se pudo leer el cuerpo de la petición", err.Error())
+				return
+			}
+			// El controller vuelve a leer el cuerpo completo
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+			if found := sanitize.ScanJSON(body); len(found) > 0 {
+				findings["body"] = found
+			}
+		}
+
+		if len(findings) > 0 {
+			abort(c, http.StatusBadRequest, "La petición contiene datos no permitidos", findings)
+			return
+		}
+		c.Next()
+	}
+}
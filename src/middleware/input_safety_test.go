@@ -0,0 +1,59 @@
+// middleware/input_safety_test.go
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestInputSafetyBody(t *testing.T) {
+	r := gin.New()
+	r.Use(InputSafety())
+	// El handler devuelve el cuerpo que recibió
+	r.POST("/", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, string(body))
+	})
+
+	const clean = `{"Clave_Cliente":"101","Nombre":"Pedro"}`
+	const operator = `{"Clave_Cliente":{"$gt":""},"$where":"sleep(1000)"}`
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  int
+	}{
+		{"json limpio", "application/json", clean, http.StatusOK},
+		{"operador en json", "application/json", operator, http.StatusBadRequest},
+		{"operador en json-patch", "application/json-patch+json", `[{"op":"add","path":"/x","value":{"$ne":1}}]`, http.StatusBadRequest},
+		{"operador en text/plain", "text/plain", operator, http.StatusBadRequest},
+		{"operador sin Content-Type", "", operator, http.StatusBadRequest},
+		{"operador en formulario", "application/x-www-form-urlencoded", operator, http.StatusBadRequest},
+		{"texto que no es JSON", "text/plain", "hola", http.StatusOK},
+		{"sin cuerpo", "", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, se esperaba %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			// El handler recibe el cuerpo completo después de la revisión
+			if w.Code == http.StatusOK && w.Body.String() != tt.body {
+				t.Errorf("cuerpo recibido = %q, se esperaba %q", w.Body.String(), tt.body)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+// middleware/response.go
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// abort corta la petición con el mismo formato de error que usan los controllers
+func abort(c *gin.Context, statusCode int, message string, errorData interface{}) {
+	body := gin.H{
+		"success": false,
+		"message": message,
+		"meta":    gin.H{"timestamp": time.Now().Unix()},
+	}
+	if errorData != nil {
+		body["error"] = errorData
+	}
+	c.AbortWithStatusJSON(statusCode, body)
+}
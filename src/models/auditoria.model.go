@@ -0,0 +1,115 @@
+package models
+
+import (
+    "encoding/json"
+    "reflect"
+    "sort"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Operaciones que quedan en la auditoría
+const (
+    AuditCreate  = "create"
+    AuditUpdate  = "update"
+    AuditDelete  = "delete"
+    AuditRestore = "restore"
+    // Borrado definitivo de un cliente con baja lógica
+    AuditPurge = "purge"
+    // Reescritura de Errores y datos derivados por un trabajo de revalidación
+    AuditRevalidate = "revalidate"
+)
+
+// Actor es quien hizo el cambio (el Principal autenticado de la petición)
+type Actor struct {
+    Subject string `json:"subject" bson:"subject"`
+    Role    string `json:"role" bson:"role"`
+    Method  string `json:"method" bson:"method"`
+}
+
+// CambioCampo es el valor de un campo antes y después del cambio; Antes o
+// Despues es nil cuando el campo no existía
+type CambioCampo struct {
+    Campo   string      `json:"campo" bson:"campo"`
+    Antes   interface{} `json:"antes" bson:"antes"`
+    Despues interface{} `json:"despues" bson:"despues"`
+}
+
+// Auditoria registra una modificación de un cliente con las fotos completas
+// del documento antes y después; en create Antes es nil y en purge Despues
+type Auditoria struct {
+    ID            primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+    Clave_Cliente string             `json:"Clave_Cliente" bson:"Clave_Cliente"`
+    Operacion     string             `json:"operacion" bson:"operacion"`
+    Actor         Actor              `json:"actor" bson:"actor"`
+    Fecha         time.Time          `json:"fecha" bson:"fecha"`
+    Antes         *Cliente           `json:"antes" bson:"antes"`
+    Despues       *Cliente           `json:"despues" bson:"despues"`
+    Cambios       []CambioCampo      `json:"cambios" bson:"cambios"`
+}
+
+// Campos que no se comparan: el _id no cambia y Mensajes se deriva de Errores
+var diffIgnorados = map[string]bool{"id": true, "Mensajes": true}
+
+// DiffClientes compara dos versiones de un cliente campo por campo, en orden
+// alfabético. Los valores se comparan en su forma JSON, la misma que ve el
+// consumidor de la API.
+func DiffClientes(antes, despues *Cliente) []CambioCampo {
+    a, b := clienteFields(antes), clienteFields(despues)
+
+    campos := make(map[string]bool, len(a)+len(b))
+    for campo := range a {
+        campos[campo] = true
+    }
+    for campo := range b {
+        campos[campo] = true
+    }
+    nombres := make([]string, 0, len(campos))
+    for campo := range campos {
+        if !diffIgnorados[campo] {
+            nombres = append(nombres, campo)
+        }
+    }
+    sort.Strings(nombres)
+
+    cambios := []CambioCampo{}
+    for _, campo := range nombres {
+        if !reflect.DeepEqual(a[campo], b[campo]) {
+            cambios = append(cambios, CambioCampo{Campo: campo, Antes: a[campo], Despues: b[campo]})
+        }
+    }
+    return cambios
+}
+
+func clienteFields(cliente *Cliente) map[string]interface{} {
+    fields := map[string]interface{}{}
+    if cliente == nil {
+        return fields
+    }
+    data, err := json.Marshal(cliente)
+    if err != nil {
+        return fields
+    }
+    json.Unmarshal(data, &fields)
+    // Un valor vacío (o Errores sin errores) equivale a un campo ausente
+    for campo, valor := range fields {
+        switch v := valor.(type) {
+        case nil:
+            delete(fields, campo)
+        case string:
+            if v == "" {
+                delete(fields, campo)
+            }
+        case map[string]interface{}:
+            if len(v) == 0 {
+                delete(fields, campo)
+            }
+        case []interface{}:
+            if len(v) == 0 {
+                delete(fields, campo)
+            }
+        }
+    }
+    return fields
+}
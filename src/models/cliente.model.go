@@ -1,6 +1,11 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+    "encoding/json"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 type Cliente struct {
     ID           primitive.ObjectID         `json:"id,omitempty" bson:"_id,omitempty"`
@@ -8,5 +13,49 @@ type Cliente struct {
     Nombre       string                     `json:"Nombre" bson:"Nombre"`
     Celular      string                     `json:"Celular" bson:"Celular"`
     Email        string                     `json:"Email" bson:"Email"`
-    Errores      map[string][]string        `json:"Errores" bson:"Errores"`
+    // Nombre en NFC separado en nombres de pila y apellidos (se calcula al validar)
+    NombreNormalizado string                `json:"NombreNormalizado,omitempty" bson:"NombreNormalizado,omitempty"`
+    NombrePila   string                     `json:"NombrePila,omitempty" bson:"NombrePila,omitempty"`
+    ApellidoPaterno string                  `json:"ApellidoPaterno,omitempty" bson:"ApellidoPaterno,omitempty"`
+    ApellidoMaterno string                  `json:"ApellidoMaterno,omitempty" bson:"ApellidoMaterno,omitempty"`
+    // Formas normalizadas del celular y la región de su lada (se calculan al validar)
+    CelularNormalizado string               `json:"CelularNormalizado,omitempty" bson:"CelularNormalizado,omitempty"`
+    CelularE164  string                     `json:"CelularE164,omitempty" bson:"CelularE164,omitempty"`
+    CelularNacional string                  `json:"CelularNacional,omitempty" bson:"CelularNacional,omitempty"`
+    CelularPais  string                     `json:"CelularPais,omitempty" bson:"CelularPais,omitempty"`
+    Region       *Region                    `json:"Region,omitempty" bson:"Region,omitempty"`
+    // Nombre y Email plegados (NFC y minúsculas) para buscar sin distinguir
+    // mayúsculas con sus índices (se calculan al validar)
+    NombreBusqueda string                   `json:"NombreBusqueda,omitempty" bson:"NombreBusqueda,omitempty"`
+    EmailBusqueda string                    `json:"EmailBusqueda,omitempty" bson:"EmailBusqueda,omitempty"`
+    Errores      Errores                    `json:"Errores" bson:"Errores"`
+    // Vista en texto de Errores, se conserva para consumidores existentes
+    Mensajes     map[string][]string        `json:"Mensajes,omitempty" bson:"Mensajes,omitempty"`
+    // Versión del documento; aumenta en cada escritura y es el ETag del cliente
+    Version      int64                      `json:"version" bson:"version"`
+    // Baja lógica: el cliente eliminado se oculta de las consultas hasta que
+    // se restaura o se purga
+    DeletedAt    *time.Time                 `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+    DeletedBy    string                     `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
+}
+
+// IsDeleted indica si el cliente tiene baja lógica
+func (c Cliente) IsDeleted() bool {
+    return c.DeletedAt != nil
+}
+
+// Region es la zona a la que pertenece la lada del celular
+type Region struct {
+    Lada      string `json:"lada" bson:"lada"`
+    Estado    string `json:"estado" bson:"estado"`
+    Municipio string `json:"municipio" bson:"municipio"`
+}
+
+// MarshalJSON deriva siempre Mensajes de Errores, incluso en documentos
+// que todavía no fueron migrados
+func (c Cliente) MarshalJSON() ([]byte, error) {
+    type alias Cliente
+    a := alias(c)
+    a.Mensajes = c.Errores.Messages()
+    return json.Marshal(a)
 }
@@ -0,0 +1,16 @@
+package models
+
+import (
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ClienteCuarentena guarda un cliente inválido fuera de la colección principal
+type ClienteCuarentena struct {
+    ID            primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+    Clave_Cliente string             `json:"Clave_Cliente" bson:"Clave_Cliente"`
+    Operacion     string             `json:"operacion" bson:"operacion"`
+    Cliente       Cliente            `json:"cliente" bson:"cliente"`
+    Fecha         time.Time          `json:"fecha" bson:"fecha"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+    "encoding/json"
+    "math"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Estados de un trabajo de revalidación
+const (
+    JobRunning   = "running"
+    JobCompleted = "completed"
+    JobCancelled = "cancelled"
+    JobFailed    = "failed"
+)
+
+// RevalidationJob es una pasada del validador vigente sobre toda la colección
+// de clientes. Recorre por _id en lotes y guarda el último _id procesado
+// (Checkpoint), así que un trabajo interrumpido continúa donde se quedó.
+type RevalidationJob struct {
+    ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+    Status    string             `json:"status" bson:"status"`
+    BatchSize int                `json:"batch_size" bson:"batch_size"`
+    // Último _id procesado, del tipo que tenga en la colección (no siempre
+    // es un ObjectID); nil si todavía no termina el primer lote
+    Checkpoint interface{} `json:"checkpoint,omitempty" bson:"checkpoint,omitempty"`
+    // Clientes estimados al iniciar, para calcular el avance
+    Total     int64 `json:"total" bson:"total"`
+    Processed int64 `json:"processed" bson:"processed"`
+    // Clientes cuyos Errores o datos derivados cambiaron y se reescribieron
+    Changed int64 `json:"changed" bson:"changed"`
+    // Clientes modificados por otra petición entre la lectura y la escritura;
+    // esa escritura ya los revalidó
+    Skipped int64 `json:"skipped" bson:"skipped"`
+    // Documentos que no se pudieron leer
+    Failed          int64      `json:"failed" bson:"failed"`
+    CancelRequested bool       `json:"cancel_requested" bson:"cancel_requested"`
+    Error           string     `json:"error,omitempty" bson:"error,omitempty"`
+    RequestedBy     Actor      `json:"requested_by" bson:"requested_by"`
+    StartedAt       time.Time  `json:"started_at" bson:"started_at"`
+    // Latido del trabajo: se actualiza en cada lote
+    UpdatedAt  time.Time  `json:"updated_at" bson:"updated_at"`
+    FinishedAt *time.Time `json:"finished_at,omitempty" bson:"finished_at,omitempty"`
+}
+
+// Percent es el avance estimado (0 a 100); los clientes creados durante el
+// trabajo pueden llevarlo a superar el total inicial
+func (j RevalidationJob) Percent() float64 {
+    if j.Status == JobCompleted {
+        return 100
+    }
+    if j.Total == 0 {
+        return 0
+    }
+    return math.Min(99.99, math.Round(float64(j.Processed)*10000/float64(j.Total))/100)
+}
+
+// MarshalJSON agrega el avance calculado
+func (j RevalidationJob) MarshalJSON() ([]byte, error) {
+    type alias RevalidationJob
+    return json.Marshal(struct {
+        alias
+        Percent float64 `json:"percent"`
+    }{alias(j), j.Percent()})
+}
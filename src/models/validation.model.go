@@ -0,0 +1,149 @@
+package models
+
+import (
+    "encoding/json"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// Severidades posibles de un error de validación
+const (
+    SeverityError   = "error"
+    SeverityWarning = "warning"
+)
+
+// LegacyCode identifica mensajes de registros anteriores a los códigos estructurados
+const LegacyCode = "LEGACY_MESSAGE"
+
+// ValidationError describe un error de validación ubicado dentro del campo.
+// Offset y Length están en runas para poder subrayar el fragmento en el frontend.
+type ValidationError struct {
+    Code      string            `json:"code" bson:"code"`
+    Severity  string            `json:"severity" bson:"severity"`
+    Message   string            `json:"message" bson:"message"`
+    Params    map[string]string `json:"params,omitempty" bson:"params,omitempty"`
+    Offset    int               `json:"offset" bson:"offset"`
+    Length    int               `json:"length" bson:"length"`
+    Token     string            `json:"token,omitempty" bson:"token,omitempty"`
+    TokenType string            `json:"token_type,omitempty" bson:"token_type,omitempty"`
+}
+
+// Errores agrupa los errores de validación por campo
+type Errores map[string][]ValidationError
+
+// Messages genera la vista en texto (la forma histórica de Errores)
+// sin repetir mensajes dentro de un mismo campo
+func (e Errores) Messages() map[string][]string {
+    if len(e) == 0 {
+        return nil
+    }
+
+    mensajes := make(map[string][]string, len(e))
+    for field, lista := range e {
+        vistos := make(map[string]bool)
+        for _, ve := range lista {
+            if vistos[ve.Message] {
+                continue
+            }
+            vistos[ve.Message] = true
+            mensajes[field] = append(mensajes[field], ve.Message)
+        }
+    }
+    return mensajes
+}
+
+// HasErrors indica si hay al menos un error con severidad "error";
+// las advertencias no invalidan al cliente
+func (e Errores) HasErrors() bool {
+    for _, lista := range e {
+        for _, ve := range lista {
+            if ve.Severity != SeverityWarning {
+                return true
+            }
+        }
+    }
+    return false
+}
+
+// Codes devuelve los códigos distintos de un campo en orden de aparición
+func (e Errores) Codes(field string) []string {
+    var codes []string
+    vistos := make(map[string]bool)
+    for _, ve := range e[field] {
+        if !vistos[ve.Code] {
+            vistos[ve.Code] = true
+            codes = append(codes, ve.Code)
+        }
+    }
+    return codes
+}
+
+func legacyError(message string) ValidationError {
+    return ValidationError{
+        Code:     LegacyCode,
+        Severity: SeverityError,
+        Message:  message,
+    }
+}
+
+// UnmarshalBSONValue acepta tanto el formato actual como el histórico
+// (map[string][]string) para poder leer documentos aún no migrados
+func (e *Errores) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+    if t == bsontype.Null || t == bsontype.Undefined {
+        *e = nil
+        return nil
+    }
+
+    var raw map[string][]bson.RawValue
+    if err := (bson.RawValue{Type: t, Value: data}).Unmarshal(&raw); err != nil {
+        return err
+    }
+
+    out := make(Errores, len(raw))
+    for field, items := range raw {
+        for _, item := range items {
+            if item.Type == bsontype.String {
+                out[field] = append(out[field], legacyError(item.StringValue()))
+                continue
+            }
+            var ve ValidationError
+            if err := item.Unmarshal(&ve); err != nil {
+                return err
+            }
+            out[field] = append(out[field], ve)
+        }
+    }
+    *e = out
+    return nil
+}
+
+// UnmarshalJSON acepta también el formato histórico, p. ej. en entradas de caché antiguas
+func (e *Errores) UnmarshalJSON(data []byte) error {
+    var raw map[string][]json.RawMessage
+    if err := json.Unmarshal(data, &raw); err != nil {
+        return err
+    }
+    if raw == nil {
+        *e = nil
+        return nil
+    }
+
+    out := make(Errores, len(raw))
+    for field, items := range raw {
+        for _, item := range items {
+            var message string
+            if err := json.Unmarshal(item, &message); err == nil {
+                out[field] = append(out[field], legacyError(message))
+                continue
+            }
+            var ve ValidationError
+            if err := json.Unmarshal(item, &ve); err != nil {
+                return err
+            }
+            out[field] = append(out[field], ve)
+        }
+    }
+    *e = out
+    return nil
+}
@@ -0,0 +1,136 @@
+// pagination/cursor.go
+package pagination
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrInvalidCursor indica un cursor mal formado, alterado o firmado con otra llave
+var ErrInvalidCursor = errors.New("cursor inválido")
+
+// Versión del formato del cursor; uno de otra versión se rechaza
+const cursorVersion = 2
+
+// Cursor es la posición de un documento en un orden: Keys tiene el valor de
+// cada campo de Order y el _id desempata las claves repetidas. El orden va
+// firmado con el cursor para no aplicarlo a un listado con otro orden.
+type Cursor struct {
+	Order string
+	Keys  []interface{}
+	ID    primitive.ObjectID
+}
+
+type cursorPayload struct {
+	Version int               `json:"v"`
+	Order   string            `json:"s"`
+	Keys    []json.RawMessage `json:"k"`
+	ID      string            `json:"i"`
+}
+
+// Signer codifica y verifica cursores con HMAC-SHA256. El cliente no puede
+// fabricar ni modificar un cursor; solo reenviar los que recibió.
+type Signer struct {
+	key []byte
+}
+
+// NewSigner usa la llave indicada; vacía genera una aleatoria, con la que los
+// cursores dejan de ser válidos al reiniciar el proceso
+func NewSigner(key []byte) (*Signer, error) {
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("generando llave de cursores: %w", err)
+		}
+	}
+	return &Signer{key: key}, nil
+}
+
+// Encode devuelve el cursor opaco: payload y firma en base64url separados por "."
+func (s *Signer) Encode(cursor Cursor) (string, error) {
+	keys := make([]json.RawMessage, len(cursor.Keys))
+	for i, key := range cursor.Keys {
+		raw, err := json.Marshal(key)
+		if err != nil {
+			return "", fmt.Errorf("clave de cursor no serializable: %w", err)
+		}
+		keys[i] = raw
+	}
+	payload, err := json.Marshal(cursorPayload{Version: cursorVersion, Order: cursor.Order, Keys: keys, ID: cursor.ID.Hex()})
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.sign(encoded)), nil
+}
+
+// Decode verifica la firma y devuelve la posición del cursor
+func (s *Signer) Decode(token string) (Cursor, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return Cursor{}, ErrInvalidCursor
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, s.sign(encoded)) {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	var payload cursorPayload
+	if err := json.Unmarshal(data, &payload); err != nil || payload.Version != cursorVersion {
+		return Cursor{}, ErrInvalidCursor
+	}
+	id, err := primitive.ObjectIDFromHex(payload.ID)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	if len(payload.Keys) != len(strings.Split(payload.Order, ",")) {
+		return Cursor{}, ErrInvalidCursor
+	}
+	keys := make([]interface{}, len(payload.Keys))
+	for i, raw := range payload.Keys {
+		if keys[i], err = decodeKey(raw); err != nil {
+			return Cursor{}, ErrInvalidCursor
+		}
+	}
+	return Cursor{Order: payload.Order, Keys: keys, ID: id}, nil
+}
+
+func (s *Signer) sign(encoded string) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
+
+// decodeKey recupera la clave con su tipo: las numéricas (como las
+// Clave_Cliente históricas) se comparan como número y las demás como texto
+func decodeKey(raw json.RawMessage) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	switch v := value.(type) {
+	case nil, string:
+		return v, nil
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n, nil
+		}
+		return v.Float64()
+	}
+	return nil, fmt.Errorf("tipo de clave no soportado: %T", value)
+}
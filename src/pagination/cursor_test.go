@@ -0,0 +1,98 @@
+// pagination/cursor_test.go
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newTestSigner(t *testing.T, key string) *Signer {
+	t.Helper()
+	signer, err := NewSigner([]byte(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	signer := newTestSigner(t, "llave-de-prueba")
+	id := primitive.NewObjectID()
+
+	tests := []struct {
+		name   string
+		cursor Cursor
+	}{
+		{"texto", Cursor{Order: "Nombre", Keys: []interface{}{"Pedro Pérez"}, ID: id}},
+		{"número entero", Cursor{Order: "Clave_Cliente", Keys: []interface{}{int64(1042)}, ID: id}},
+		{"número decimal", Cursor{Order: "Clave_Cliente", Keys: []interface{}{1.5}, ID: id}},
+		{"null", Cursor{Order: "-Email", Keys: []interface{}{nil}, ID: id}},
+		{"varias claves", Cursor{Order: "Nombre,-Clave_Cliente", Keys: []interface{}{"Ana", int64(7)}, ID: id}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := signer.Encode(tt.cursor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := signer.Decode(token)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.cursor) {
+				t.Errorf("Decode(Encode(c)) = %#v, se esperaba %#v", got, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestCursorTampered(t *testing.T) {
+	signer := newTestSigner(t, "llave-de-prueba")
+	token, err := signer.Encode(Cursor{Order: "Nombre", Keys: []interface{}{"Pedro"}, ID: primitive.NewObjectID()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, signature, _ := strings.Cut(token, ".")
+
+	// Un payload con otra posición, válido pero sin la firma correcta
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"v":2,"s":"Nombre","k":["Zzz"],"i":"000000000000000000000000"}`))
+
+	// Una firma correcta de un cursor que no corresponde al orden
+	mismatched, err := signer.Encode(Cursor{Order: "Nombre,Email", Keys: []interface{}{"Pedro"}, ID: primitive.NewObjectID()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"sin firma", payload},
+		{"payload alterado", forged + "." + signature},
+		{"firma alterada", payload + "." + strings.Repeat("A", len(signature))},
+		{"firma que no es base64", payload + ".***"},
+		{"vacío", ""},
+		{"claves que no corresponden al orden", mismatched},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := signer.Decode(tt.token); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("error = %v, se esperaba %v", err, ErrInvalidCursor)
+			}
+		})
+	}
+
+	t.Run("otra llave", func(t *testing.T) {
+		other := newTestSigner(t, "otra-llave")
+		if _, err := other.Decode(token); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("error = %v, se esperaba %v", err, ErrInvalidCursor)
+		}
+	})
+}
@@ -0,0 +1,89 @@
+// pagination/keyset.go
+package pagination
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Orden de los tipos de BSON que puede tener una clave: MongoDB ordena null
+// (o ausente) antes que los números y éstos antes que el texto, pero $gt y
+// $lt solo comparan dentro del mismo tipo
+const (
+	bracketNull = iota
+	bracketNumber
+	bracketString
+)
+
+func keyBracket(key interface{}) int {
+	switch key.(type) {
+	case nil:
+		return bracketNull
+	case string:
+		return bracketString
+	}
+	return bracketNumber
+}
+
+// bracketFilter es la condición de los documentos cuya clave es del tipo indicado
+func bracketFilter(field string, bracket int) bson.M {
+	switch bracket {
+	case bracketNull:
+		return bson.M{field: nil}
+	case bracketNumber:
+		return bson.M{field: bson.M{"$type": "number"}}
+	}
+	return bson.M{field: bson.M{"$type": "string"}}
+}
+
+// beyond devuelve las condiciones de los valores de field mayores (greater)
+// o menores que key, incluidos los tipos que se ordenan después o antes
+func beyond(field string, key interface{}, greater bool) []bson.M {
+	op := "$gt"
+	if !greater {
+		op = "$lt"
+	}
+
+	var conditions []bson.M
+	if key != nil {
+		conditions = append(conditions, bson.M{field: bson.M{op: key}})
+	}
+	current := keyBracket(key)
+	for bracket := bracketNull; bracket <= bracketString; bracket++ {
+		if (greater && bracket > current) || (!greater && bracket < current) {
+			conditions = append(conditions, bracketFilter(field, bracket))
+		}
+	}
+	return conditions
+}
+
+// Filter devuelve la condición de los documentos que siguen al cursor en el
+// orden indicado (forward) o que lo preceden (backward): los que superan la
+// primera clave, los que la igualan y superan la segunda, y así hasta el _id
+func (c Cursor) Filter(order Order, forward bool) bson.M {
+	var branches bson.A
+	equal := bson.M{}
+	for i, key := range order {
+		for _, condition := range beyond(key.Field, c.Keys[i], forward != key.Desc) {
+			branches = append(branches, merge(equal, condition))
+		}
+		equal[key.Field] = c.Keys[i]
+	}
+
+	op := "$gt"
+	if !forward {
+		op = "$lt"
+	}
+	branches = append(branches, merge(equal, bson.M{"_id": bson.M{op: c.ID}}))
+	return bson.M{"$or": branches}
+}
+
+func merge(a, b bson.M) bson.M {
+	result := make(bson.M, len(a)+len(b))
+	for key, value := range a {
+		result[key] = value
+	}
+	for key, value := range b {
+		result[key] = value
+	}
+	return result
+}
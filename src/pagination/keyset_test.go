@@ -0,0 +1,95 @@
+// pagination/keyset_test.go
+package pagination
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// null < número < texto: los tipos que se ordenan después (o antes) de la
+// clave se agregan completos, porque $gt y $lt no cruzan tipos
+func TestBeyond(t *testing.T) {
+	var (
+		isNull   = bson.M{"f": nil}
+		isNumber = bson.M{"f": bson.M{"$type": "number"}}
+		isString = bson.M{"f": bson.M{"$type": "string"}}
+	)
+
+	tests := []struct {
+		name    string
+		key     interface{}
+		greater bool
+		want    []bson.M
+	}{
+		{"después de null", nil, true, []bson.M{isNumber, isString}},
+		{"antes de null", nil, false, nil},
+		{"después de un número", int64(5), true, []bson.M{{"f": bson.M{"$gt": int64(5)}}, isString}},
+		{"antes de un número", int64(5), false, []bson.M{{"f": bson.M{"$lt": int64(5)}}, isNull}},
+		{"después de un decimal", 1.5, true, []bson.M{{"f": bson.M{"$gt": 1.5}}, isString}},
+		{"después de un texto", "m", true, []bson.M{{"f": bson.M{"$gt": "m"}}}},
+		{"antes de un texto", "m", false, []bson.M{{"f": bson.M{"$lt": "m"}}, isNull, isNumber}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := beyond("f", tt.key, tt.greater); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("beyond(%v, %v) = %v, se esperaba %v", tt.key, tt.greater, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCursorFilter(t *testing.T) {
+	id := primitive.NewObjectID()
+
+	tests := []struct {
+		name    string
+		order   Order
+		keys    []interface{}
+		forward bool
+		want    bson.M
+	}{
+		{
+			"ascendente hacia adelante",
+			Order{{Field: "Nombre"}}, []interface{}{"pedro"}, true,
+			bson.M{"$or": bson.A{
+				bson.M{"Nombre": bson.M{"$gt": "pedro"}},
+				bson.M{"Nombre": "pedro", "_id": bson.M{"$gt": id}},
+			}},
+		},
+		{
+			// Descendente hacia atrás recorre los valores mayores
+			"descendente hacia atrás",
+			Order{{Field: "Clave_Cliente", Desc: true}}, []interface{}{int64(10)}, false,
+			bson.M{"$or": bson.A{
+				bson.M{"Clave_Cliente": bson.M{"$gt": int64(10)}},
+				bson.M{"Clave_Cliente": bson.M{"$type": "string"}},
+				bson.M{"Clave_Cliente": int64(10), "_id": bson.M{"$lt": id}},
+			}},
+		},
+		{
+			"dos claves con null",
+			Order{{Field: "Email"}, {Field: "Nombre", Desc: true}}, []interface{}{nil, "ana"}, true,
+			bson.M{"$or": bson.A{
+				bson.M{"Email": bson.M{"$type": "number"}},
+				bson.M{"Email": bson.M{"$type": "string"}},
+				bson.M{"Email": nil, "Nombre": bson.M{"$lt": "ana"}},
+				bson.M{"Email": nil, "Nombre": nil},
+				bson.M{"Email": nil, "Nombre": bson.M{"$type": "number"}},
+				bson.M{"Email": nil, "Nombre": "ana", "_id": bson.M{"$gt": id}},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor := Cursor{Order: tt.order.String(), Keys: tt.keys, ID: id}
+			if got := cursor.Filter(tt.order, tt.forward); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Filter() = %v\nse esperaba %v", got, tt.want)
+			}
+		})
+	}
+}
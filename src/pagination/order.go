@@ -0,0 +1,89 @@
+// pagination/order.go
+package pagination
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// SortKey es un campo del orden; Desc lo recorre de mayor a menor
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+// Order es el orden de un listado. El _id desempata siempre al final, así
+// que dos documentos nunca quedan en la misma posición.
+type Order []SortKey
+
+// ParseOrder lee un orden como "Nombre,-Email": campos separados por coma y
+// "-" para el orden descendente. Solo se aceptan los campos de allowed, sin
+// repetir.
+func ParseOrder(value string, allowed []string) (Order, error) {
+	var order Order
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		key := SortKey{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		if key.Field == "" {
+			return nil, fmt.Errorf("campo de orden vacío en %q", value)
+		}
+		if !contains(allowed, key.Field) {
+			return nil, fmt.Errorf("no se puede ordenar por %q (permitidos: %s)", key.Field, strings.Join(allowed, ", "))
+		}
+		if seen[key.Field] {
+			return nil, fmt.Errorf("el campo %q se repite en el orden", key.Field)
+		}
+		seen[key.Field] = true
+		order = append(order, key)
+	}
+	return order, nil
+}
+
+// String es la forma canónica del orden, la misma que acepta ParseOrder
+func (o Order) String() string {
+	parts := make([]string, len(o))
+	for i, key := range o {
+		parts[i] = key.Field
+		if key.Desc {
+			parts[i] = "-" + key.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// Fields devuelve los campos del orden
+func (o Order) Fields() []string {
+	fields := make([]string, len(o))
+	for i, key := range o {
+		fields[i] = key.Field
+	}
+	return fields
+}
+
+// Sort es el documento de orden de MongoDB; reverse lo invierte para leer la
+// página anterior
+func (o Order) Sort(reverse bool) bson.D {
+	direction := func(desc bool) int {
+		if desc != reverse {
+			return -1
+		}
+		return 1
+	}
+	sort := make(bson.D, 0, len(o)+1)
+	for _, key := range o {
+		sort = append(sort, bson.E{Key: key.Field, Value: direction(key.Desc)})
+	}
+	return append(sort, bson.E{Key: "_id", Value: direction(false)})
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
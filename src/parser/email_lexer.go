@@ -0,0 +1,128 @@
+// parser/email_lexer.go
+package parser
+
+import (
+	"strings"
+	"unicode"
+)
+
+// tokenKind identifica la categoría léxica de un token del email
+type tokenKind int
+
+const (
+	tokEOF           tokenKind = iota
+	tokAtom                    // secuencia de atext (RFC 5322 §3.2.3, con UTF-8 según RFC 6531)
+	tokDot                     // punto
+	tokAt                      // símbolo @
+	tokQuotedString            // cadena entre comillas dobles, con sus escapes
+	tokDomainLiteral           // literal de dominio entre corchetes
+	tokSpace                   // espacios en blanco
+	tokInvalid                 // carácter que no puede aparecer fuera de comillas
+)
+
+// token es un lexema con su posición en runas dentro del email
+type token struct {
+	kind   tokenKind
+	value  string
+	offset int
+	length int
+	// unterminated indica una cadena o literal al que le falta el cierre
+	unterminated bool
+}
+
+func (t token) end() int {
+	return t.offset + t.length
+}
+
+// Caracteres ASCII de atext además de letras y dígitos
+const atextSpecials = "!#$%&'*+-/=?^_`{|}~"
+
+func isAtext(r rune) bool {
+	if r < 0x80 {
+		return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+			strings.ContainsRune(atextSpecials, r)
+	}
+	// RFC 6531: cualquier carácter UTF-8 visible es atext
+	return unicode.IsGraphic(r) && !unicode.IsSpace(r)
+}
+
+// emailLexer recorre el email runa por runa
+type emailLexer struct {
+	input []rune
+	pos   int
+}
+
+func (l *emailLexer) next() token {
+	if l.pos >= len(l.input) {
+		return token{kind: tokEOF, offset: len(l.input)}
+	}
+
+	start := l.pos
+	r := l.input[l.pos]
+	switch {
+	case r == '@':
+		l.pos++
+		return l.emit(tokAt, start)
+	case r == '.':
+		l.pos++
+		return l.emit(tokDot, start)
+	case r == '"':
+		return l.delimited(tokQuotedString, start, '"', true)
+	case r == '[':
+		return l.delimited(tokDomainLiteral, start, ']', false)
+	case unicode.IsSpace(r):
+		for l.pos < len(l.input) && unicode.IsSpace(l.input[l.pos]) {
+			l.pos++
+		}
+		return l.emit(tokSpace, start)
+	case isAtext(r):
+		for l.pos < len(l.input) && isAtext(l.input[l.pos]) {
+			l.pos++
+		}
+		return l.emit(tokAtom, start)
+	}
+	l.pos++
+	return l.emit(tokInvalid, start)
+}
+
+// delimited consume hasta el cierre; en las cadenas entre comillas la
+// diagonal invertida escapa el siguiente carácter (quoted-pair)
+func (l *emailLexer) delimited(kind tokenKind, start int, closing rune, escapes bool) token {
+	l.pos++
+	for l.pos < len(l.input) {
+		r := l.input[l.pos]
+		l.pos++
+		if escapes && r == '\\' && l.pos < len(l.input) {
+			l.pos++
+			continue
+		}
+		if r == closing {
+			return l.emit(kind, start)
+		}
+	}
+	tok := l.emit(kind, start)
+	tok.unterminated = true
+	return tok
+}
+
+func (l *emailLexer) emit(kind tokenKind, start int) token {
+	return token{
+		kind:   kind,
+		value:  string(l.input[start:l.pos]),
+		offset: start,
+		length: l.pos - start,
+	}
+}
+
+// tokenize analiza el email completo; el último token siempre es tokEOF
+func tokenize(input string) []token {
+	l := &emailLexer{input: []rune(input)}
+	var tokens []token
+	for {
+		tok := l.next()
+		tokens = append(tokens, tok)
+		if tok.kind == tokEOF {
+			return tokens
+		}
+	}
+}
@@ -0,0 +1,403 @@
+// parser/email_parser.go
+package parser
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/idna"
+)
+
+// Analizador descendente recursivo para direcciones de email (addr-spec de
+// RFC 5322 §3.4.1, con las extensiones UTF-8 de RFC 6531):
+//
+//	address       = local-part "@" domain EOF
+//	local-part    = dot-atom / quoted-string
+//	domain        = dot-atom / domain-literal
+//	dot-atom      = atom *("." atom)
+//	domain-literal = "[" (IPv4 / "IPv6:" IPv6) "]"
+//
+// No se admiten comentarios (CFWS) ni las formas obsoletas (obs-local-part).
+
+// MaxLabelLength es la longitud máxima de una etiqueta de dominio en su forma ASCII
+const MaxLabelLength = 63
+
+// ErrorKind clasifica los errores de sintaxis del email
+type ErrorKind int
+
+const (
+	ErrMissingAt         ErrorKind = iota // no hay @
+	ErrExtraAt                            // @ adicional
+	ErrLocalEmpty                         // nada antes del @
+	ErrLocalDotEdge                       // la parte local empieza o termina con punto
+	ErrConsecutiveDots                    // dos puntos seguidos
+	ErrInvalidChar                        // carácter no permitido en esa posición
+	ErrUnterminatedQuote                  // falta la comilla de cierre
+	ErrDomainEmpty                        // nada después del @
+	ErrDomainDotEdge                      // el dominio empieza o termina con punto
+	ErrLabelTooLong                       // etiqueta de más de 63 caracteres
+	ErrLabelHyphen                        // etiqueta que empieza o termina con guión
+	ErrInvalidIDN                         // etiqueta internacionalizada inválida
+	ErrDomainLiteral                      // literal de dominio que no es una IP válida
+)
+
+var errorKindNames = map[ErrorKind]string{
+	ErrMissingAt:         "MISSING_AT",
+	ErrExtraAt:           "EXTRA_AT",
+	ErrLocalEmpty:        "LOCAL_EMPTY",
+	ErrLocalDotEdge:      "LOCAL_DOT_EDGE",
+	ErrConsecutiveDots:   "CONSECUTIVE_DOTS",
+	ErrInvalidChar:       "INVALID_CHAR",
+	ErrUnterminatedQuote: "UNTERMINATED_QUOTE",
+	ErrDomainEmpty:       "DOMAIN_EMPTY",
+	ErrDomainDotEdge:     "DOMAIN_DOT_EDGE",
+	ErrLabelTooLong:      "LABEL_TOO_LONG",
+	ErrLabelHyphen:       "LABEL_HYPHEN",
+	ErrInvalidIDN:        "INVALID_IDN",
+	ErrDomainLiteral:     "DOMAIN_LITERAL",
+}
+
+func (k ErrorKind) String() string {
+	if name, ok := errorKindNames[k]; ok {
+		return name
+	}
+	return "UNKNOWN"
+}
+
+// SyntaxError ubica un error en el email; Offset y Length se expresan en runas
+type SyntaxError struct {
+	Kind   ErrorKind
+	Offset int
+	Length int
+	Token  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s en la posición %d (%q)", e.Kind, e.Offset, e.Token)
+}
+
+// LocalPart es la parte antes del @
+type LocalPart struct {
+	// Value es el contenido sin comillas ni escapes
+	Value  string
+	Raw    string
+	Quoted bool
+	Offset int
+	Length int
+}
+
+// Octets devuelve la longitud en bytes UTF-8, que es la que limita RFC 5321
+func (l LocalPart) Octets() int {
+	return len(l.Raw)
+}
+
+// Label es una etiqueta del dominio; ASCII es su forma A-label (xn--...) si es IDN
+type Label struct {
+	Value  string
+	ASCII  string
+	Offset int
+	Length int
+}
+
+// Domain es la parte después del @
+type Domain struct {
+	// Name es el dominio tal como aparece en el email
+	Name string
+	// ASCII es el dominio con las etiquetas internacionalizadas en punycode
+	ASCII   string
+	Labels  []Label
+	Literal bool
+	Offset  int
+	Length  int
+}
+
+// Address es el árbol sintáctico de un email
+type Address struct {
+	Local LocalPart
+	// At es la posición del @ que separa las partes, -1 si no hay
+	At     int
+	Domain Domain
+}
+
+type emailParser struct {
+	input  []rune
+	tokens []token
+	pos    int
+	errs   []*SyntaxError
+}
+
+// ParseEmail analiza el email y devuelve su árbol junto con todos los errores
+// encontrados; el árbol se construye aunque haya errores para que las reglas
+// posteriores puedan revisar las partes que sí se reconocieron
+func ParseEmail(input string) (*Address, []*SyntaxError) {
+	p := &emailParser{input: []rune(input), tokens: tokenize(input)}
+	addr := p.parseAddress()
+	return addr, p.errs
+}
+
+func (p *emailParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *emailParser) advance() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *emailParser) fail(kind ErrorKind, offset, length int) {
+	p.errs = append(p.errs, &SyntaxError{
+		Kind:   kind,
+		Offset: offset,
+		Length: length,
+		Token:  string(p.input[offset : offset+length]),
+	})
+}
+
+func (p *emailParser) failToken(kind ErrorKind, tok token) {
+	p.fail(kind, tok.offset, tok.length)
+}
+
+func (p *emailParser) parseAddress() *Address {
+	addr := &Address{At: -1}
+
+	hasAt := false
+	for _, tok := range p.tokens {
+		if tok.kind == tokAt {
+			hasAt = true
+			break
+		}
+	}
+	if !hasAt {
+		// Una comilla sin cerrar se traga el @; es el error más útil de reportar
+		for _, tok := range p.tokens {
+			if tok.kind == tokQuotedString && tok.unterminated {
+				p.failToken(ErrUnterminatedQuote, tok)
+				return addr
+			}
+		}
+		p.fail(ErrMissingAt, 0, len(p.input))
+		return addr
+	}
+
+	addr.Local = p.parseLocalPart()
+	addr.At = p.advance().offset
+	addr.Domain = p.parseDomain()
+
+	// Lo que quede después del dominio solo puede ser otro @; cada uno se
+	// señala y el texto entre ellos se ignora
+	for p.peek().kind != tokEOF {
+		if tok := p.advance(); tok.kind == tokAt {
+			p.failToken(ErrExtraAt, tok)
+		}
+	}
+	return addr
+}
+
+// parseLocalPart consume hasta el primer @
+func (p *emailParser) parseLocalPart() LocalPart {
+	first := p.peek()
+	if first.kind == tokAt {
+		p.fail(ErrLocalEmpty, first.offset, 0)
+		return LocalPart{Offset: first.offset}
+	}
+
+	if first.kind == tokQuotedString {
+		p.advance()
+		p.checkQuoted(first)
+		// Después de la cadena solo puede venir el @
+		for p.peek().kind != tokAt {
+			p.failToken(ErrInvalidChar, p.advance())
+		}
+		return LocalPart{
+			Value:  unquote(first.value),
+			Raw:    first.value,
+			Quoted: true,
+			Offset: first.offset,
+			Length: first.length,
+		}
+	}
+
+	p.parseDotAtom(ErrLocalDotEdge)
+	end := p.peek().offset
+	raw := string(p.input[first.offset:end])
+	return LocalPart{Value: raw, Raw: raw, Offset: first.offset, Length: end - first.offset}
+}
+
+// checkQuoted revisa el contenido de una cadena entre comillas (qtext / quoted-pair)
+func (p *emailParser) checkQuoted(tok token) {
+	if tok.unterminated {
+		p.failToken(ErrUnterminatedQuote, tok)
+	}
+	runes := []rune(tok.value)
+	for i := 1; i < len(runes); i++ {
+		if runes[i] == '\\' {
+			i++
+			continue
+		}
+		if unicode.IsControl(runes[i]) && runes[i] != '\t' {
+			p.fail(ErrInvalidChar, tok.offset+i, 1)
+		}
+	}
+}
+
+// parseDotAtom consume atom *("." atom) hasta el @ o el final y devuelve los atoms
+func (p *emailParser) parseDotAtom(dotEdge ErrorKind) []token {
+	var atoms []token
+	var last token
+	seen := false
+	leadingDot := -1
+
+	for {
+		tok := p.peek()
+		if tok.kind == tokAt || tok.kind == tokEOF {
+			break
+		}
+		p.advance()
+
+		switch tok.kind {
+		case tokAtom:
+			atoms = append(atoms, tok)
+		case tokDot:
+			if !seen {
+				p.failToken(dotEdge, tok)
+				leadingDot = tok.offset
+			} else if last.kind == tokDot {
+				p.fail(ErrConsecutiveDots, last.offset, 2)
+			}
+		default:
+			p.failToken(ErrInvalidChar, tok)
+		}
+		last = tok
+		seen = true
+	}
+
+	// Un punto que ya se señaló como inicio no se vuelve a señalar como final
+	if seen && last.kind == tokDot && last.offset != leadingDot {
+		p.failToken(dotEdge, last)
+	}
+	return atoms
+}
+
+func (p *emailParser) parseDomain() Domain {
+	first := p.peek()
+	if first.kind == tokEOF || first.kind == tokAt {
+		p.fail(ErrDomainEmpty, first.offset, 0)
+		return Domain{Offset: first.offset}
+	}
+
+	if first.kind == tokDomainLiteral {
+		p.advance()
+		return p.parseDomainLiteral(first)
+	}
+
+	atoms := p.parseDotAtom(ErrDomainDotEdge)
+	end := p.peek().offset
+	domain := Domain{
+		Name:   string(p.input[first.offset:end]),
+		Offset: first.offset,
+		Length: end - first.offset,
+	}
+
+	ascii := make([]string, 0, len(atoms))
+	for _, atom := range atoms {
+		label := p.parseLabel(atom)
+		domain.Labels = append(domain.Labels, label)
+		ascii = append(ascii, label.ASCII)
+	}
+	domain.ASCII = strings.Join(ascii, ".")
+	return domain
+}
+
+// parseLabel revisa una etiqueta: letras, dígitos y guiones (LDH), o letras
+// Unicode que se convierten a punycode (IDNA2008)
+func (p *emailParser) parseLabel(atom token) Label {
+	label := Label{Value: atom.value, ASCII: atom.value, Offset: atom.offset, Length: atom.length}
+	runes := []rune(atom.value)
+
+	ascii, valid := true, true
+	for i, r := range runes {
+		switch {
+		case r >= 0x80:
+			ascii = false
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.In(r, unicode.Mn, unicode.Mc) {
+				p.fail(ErrInvalidChar, atom.offset+i, 1)
+				valid = false
+			}
+		case r == '-', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		default:
+			p.fail(ErrInvalidChar, atom.offset+i, 1)
+			valid = false
+		}
+	}
+
+	if runes[0] == '-' || runes[len(runes)-1] == '-' {
+		p.failToken(ErrLabelHyphen, atom)
+		valid = false
+	}
+
+	if !ascii && valid {
+		converted, err := idna.Lookup.ToASCII(atom.value)
+		if err != nil {
+			p.failToken(ErrInvalidIDN, atom)
+		} else {
+			label.ASCII = converted
+		}
+	}
+
+	if len(label.ASCII) > MaxLabelLength {
+		if ascii {
+			p.fail(ErrLabelTooLong, atom.offset+MaxLabelLength, atom.length-MaxLabelLength)
+		} else {
+			p.failToken(ErrLabelTooLong, atom)
+		}
+	}
+	return label
+}
+
+// parseDomainLiteral acepta [IPv4] o [IPv6:dirección]
+func (p *emailParser) parseDomainLiteral(tok token) Domain {
+	domain := Domain{Name: tok.value, ASCII: tok.value, Literal: true, Offset: tok.offset, Length: tok.length}
+	if tok.unterminated {
+		p.failToken(ErrDomainLiteral, tok)
+		return domain
+	}
+
+	content := tok.value[1 : len(tok.value)-1]
+	valid := false
+	if strings.HasPrefix(strings.ToLower(content), "ipv6:") {
+		ip := net.ParseIP(content[5:])
+		valid = ip != nil && strings.Contains(content[5:], ":")
+	} else {
+		ip := net.ParseIP(content)
+		valid = ip != nil && ip.To4() != nil && !strings.Contains(content, ":")
+	}
+	if !valid {
+		p.failToken(ErrDomainLiteral, tok)
+	}
+	return domain
+}
+
+// unquote quita las comillas y resuelve los quoted-pair
+func unquote(quoted string) string {
+	runes := []rune(quoted)
+	if len(runes) < 2 {
+		return ""
+	}
+	end := len(runes)
+	if runes[end-1] == '"' {
+		end--
+	}
+	var b strings.Builder
+	for i := 1; i < end; i++ {
+		if runes[i] == '\\' && i+1 < end {
+			i++
+		}
+		b.WriteRune(runes[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,103 @@
+// parser/email_parser_test.go
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseEmailValid(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		wantLocal   string
+		wantQuoted  bool
+		wantAt      int
+		wantDomain  string
+		wantLiteral bool
+	}{
+		{"simple", "pedro@example.com", "pedro", false, 5, "example.com", false},
+		{"puntos y especiales", "pedro.perez+ventas@mail.example.com.mx", "pedro.perez+ventas", false, 18, "mail.example.com.mx", false},
+		{"parte local entre comillas", `"pedro perez"@example.com`, "pedro perez", true, 13, "example.com", false},
+		{"comilla escapada", `"a\"b"@example.com`, `a"b`, true, 6, "example.com", false},
+		{"UTF-8 en la parte local", "josé@example.com", "josé", false, 4, "example.com", false},
+		{"dominio internacionalizado", "ana@bücher.de", "ana", false, 3, "xn--bcher-kva.de", false},
+		{"literal IPv4", "root@[192.168.0.1]", "root", false, 4, "[192.168.0.1]", true},
+		{"literal IPv6", "root@[IPv6:2001:db8::1]", "root", false, 4, "[IPv6:2001:db8::1]", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, errs := ParseEmail(tt.input)
+			for _, err := range errs {
+				t.Errorf("error inesperado: %v", err)
+			}
+			if addr.Local.Value != tt.wantLocal || addr.Local.Quoted != tt.wantQuoted {
+				t.Errorf("parte local = %q (comillas %v), se esperaba %q (comillas %v)",
+					addr.Local.Value, addr.Local.Quoted, tt.wantLocal, tt.wantQuoted)
+			}
+			if addr.At != tt.wantAt {
+				t.Errorf("@ en %d, se esperaba %d", addr.At, tt.wantAt)
+			}
+			if addr.Domain.ASCII != tt.wantDomain || addr.Domain.Literal != tt.wantLiteral {
+				t.Errorf("dominio = %q (literal %v), se esperaba %q (literal %v)",
+					addr.Domain.ASCII, addr.Domain.Literal, tt.wantDomain, tt.wantLiteral)
+			}
+		})
+	}
+}
+
+func TestParseEmailErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantKind   ErrorKind
+		wantOffset int
+		wantLength int
+	}{
+		{"sin @", "pedro.example.com", ErrMissingAt, 0, 17},
+		{"@ adicional", "pedro@a@b.com", ErrExtraAt, 7, 1},
+		{"parte local vacía", "@example.com", ErrLocalEmpty, 0, 0},
+		{"punto inicial", ".pedro@example.com", ErrLocalDotEdge, 0, 1},
+		{"punto final", "pedro.@example.com", ErrLocalDotEdge, 5, 1},
+		{"puntos seguidos", "pe..dro@example.com", ErrConsecutiveDots, 2, 2},
+		{"espacio", "pe dro@example.com", ErrInvalidChar, 2, 1},
+		{"texto después de las comillas", `"pedro"x@example.com`, ErrInvalidChar, 7, 1},
+		{"comilla sin cerrar", `"pedro@example.com`, ErrUnterminatedQuote, 0, 18},
+		{"dominio vacío", "pedro@", ErrDomainEmpty, 6, 0},
+		{"punto al final del dominio", "pedro@example.com.", ErrDomainDotEdge, 17, 1},
+		{"puntos seguidos en el dominio", "pedro@example..com", ErrConsecutiveDots, 13, 2},
+		{"guión al inicio de la etiqueta", "pedro@-example.com", ErrLabelHyphen, 6, 8},
+		{"guión bajo en el dominio", "pedro@exa_mple.com", ErrInvalidChar, 9, 1},
+		// Se subraya solo lo que excede los 63 caracteres
+		{"etiqueta larga", "a@" + strings.Repeat("b", 65) + ".com", ErrLabelTooLong, 65, 2},
+		{"IPv4 inválida", "pedro@[300.1.1.1]", ErrDomainLiteral, 6, 11},
+		{"literal sin cerrar", "pedro@[1.2.3.4", ErrDomainLiteral, 6, 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errs := ParseEmail(tt.input)
+			if len(errs) == 0 {
+				t.Fatalf("ParseEmail(%q) no devolvió errores, se esperaba %s", tt.input, tt.wantKind)
+			}
+			err := errs[0]
+			if err.Kind != tt.wantKind || err.Offset != tt.wantOffset || err.Length != tt.wantLength {
+				t.Errorf("ParseEmail(%q) = %s en %d (%d), se esperaba %s en %d (%d)",
+					tt.input, err.Kind, err.Offset, err.Length, tt.wantKind, tt.wantOffset, tt.wantLength)
+			}
+			// El token es el fragmento de la entrada que señala el error
+			if want := string([]rune(tt.input)[tt.wantOffset : tt.wantOffset+tt.wantLength]); err.Token != want {
+				t.Errorf("token = %q, se esperaba %q", err.Token, want)
+			}
+		})
+	}
+}
+
+// Los offsets se cuentan en runas, no en bytes
+func TestParseEmailRuneOffsets(t *testing.T) {
+	_, errs := ParseEmail("ñoño..x@example.com")
+	if len(errs) != 1 || errs[0].Kind != ErrConsecutiveDots || errs[0].Offset != 4 {
+		t.Errorf("errores = %v, se esperaba CONSECUTIVE_DOTS en la posición 4", errs)
+	}
+}
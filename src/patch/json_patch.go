@@ -0,0 +1,293 @@
+// patch/json_patch.go
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Clases de error al aplicar un JSON Patch; OpError las envuelve
+var (
+	// La operación está mal formada (op desconocida, falta path o value)
+	ErrInvalidOperation = errors.New("operación inválida")
+	// El path o from no existe en el documento
+	ErrPathNotFound = errors.New("ruta inexistente")
+	// Una operación "test" no coincidió
+	ErrTestFailed = errors.New("la prueba no coincidió")
+)
+
+// Operation es una operación de JSON Patch (RFC 6902). Value queda en nil
+// cuando el miembro no viene, para distinguirlo de "value": null.
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// OpError ubica el error en la operación Index (desde 0) del parche
+type OpError struct {
+	Index   int    `json:"index"`
+	Op      string `json:"op"`
+	Path    string `json:"path"`
+	Message string `json:"message"`
+	err     error
+}
+
+func (e *OpError) Error() string {
+	return fmt.Sprintf("operación %d (%s %s): %s", e.Index, e.Op, e.Path, e.Message)
+}
+
+func (e *OpError) Unwrap() error {
+	return e.err
+}
+
+// ParseOperations lee el documento de JSON Patch, que debe ser un arreglo
+func ParseOperations(data []byte) ([]Operation, error) {
+	var ops []Operation
+	if err := json.Unmarshal(data, &ops); err != nil {
+		return nil, fmt.Errorf("un JSON Patch debe ser un arreglo de operaciones: %w", err)
+	}
+	return ops, nil
+}
+
+// Apply ejecuta las operaciones en orden sobre una copia del documento. Si
+// alguna falla no se aplica ninguna (RFC 6902, sección 5) y el documento
+// original queda intacto.
+func Apply(doc interface{}, ops []Operation) (interface{}, error) {
+	result := deepCopy(doc)
+	for i, op := range ops {
+		var err error
+		result, err = applyOperation(result, op)
+		if err != nil {
+			opErr := &OpError{Index: i, Op: op.Op, Path: op.Path, Message: err.Error(), err: err}
+			return nil, opErr
+		}
+	}
+	return result, nil
+}
+
+func applyOperation(doc interface{}, op Operation) (interface{}, error) {
+	path, err := ParsePointer(op.Path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidOperation, err)
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, fmt.Errorf("%w: falta value", ErrInvalidOperation)
+		}
+		value, err := Decode(op.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: value no es JSON válido", ErrInvalidOperation)
+		}
+		switch op.Op {
+		case "add":
+			return add(doc, path, value)
+		case "replace":
+			if _, err := get(doc, path); err != nil {
+				return nil, err
+			}
+			if len(path) == 0 {
+				return value, nil
+			}
+			if doc, err = remove(doc, path); err != nil {
+				return nil, err
+			}
+			return add(doc, path, value)
+		default:
+			current, err := get(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			if !equal(current, value) {
+				return nil, ErrTestFailed
+			}
+			return doc, nil
+		}
+
+	case "remove":
+		if len(path) == 0 {
+			return nil, fmt.Errorf("%w: no se puede eliminar el documento completo", ErrInvalidOperation)
+		}
+		return remove(doc, path)
+
+	case "move", "copy":
+		from, err := ParsePointer(op.From)
+		if err != nil {
+			return nil, fmt.Errorf("%w: from: %v", ErrInvalidOperation, err)
+		}
+		value, err := get(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "copy" {
+			return add(doc, path, deepCopy(value))
+		}
+		if from.IsPrefixOf(path) {
+			if len(from) == len(path) {
+				return doc, nil
+			}
+			return nil, fmt.Errorf("%w: no se puede mover un valor dentro de sí mismo", ErrInvalidOperation)
+		}
+		if doc, err = remove(doc, from); err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+	}
+	return nil, fmt.Errorf("%w: op desconocida %q", ErrInvalidOperation, op.Op)
+}
+
+// get devuelve el valor al que apunta path
+func get(doc interface{}, path Pointer) (interface{}, error) {
+	node := doc
+	for _, segment := range path {
+		switch container := node.(type) {
+		case map[string]interface{}:
+			value, ok := container[segment]
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", ErrPathNotFound, path)
+			}
+			node = value
+		case []interface{}:
+			index, err := arrayIndex(segment, len(container), false)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrPathNotFound, path, err)
+			}
+			node = container[index]
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrPathNotFound, path)
+		}
+	}
+	return node, nil
+}
+
+// update recorre path hasta el contenedor del último segmento y lo
+// sustituye por el que devuelve leaf; los arreglos cambian de longitud, así
+// que cada nivel se vuelve a asignar en su padre
+func update(node interface{}, path Pointer, leaf func(container interface{}, segment string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return leaf(node, path[0])
+	}
+	child, err := get(node, path[:1])
+	if err != nil {
+		return nil, err
+	}
+	updated, err := update(child, path[1:], leaf)
+	if err != nil {
+		return nil, err
+	}
+	switch container := node.(type) {
+	case map[string]interface{}:
+		container[path[0]] = updated
+	case []interface{}:
+		index, _ := arrayIndex(path[0], len(container), false)
+		container[index] = updated
+	}
+	return node, nil
+}
+
+func add(doc interface{}, path Pointer, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return update(doc, path, func(node interface{}, segment string) (interface{}, error) {
+		switch container := node.(type) {
+		case map[string]interface{}:
+			container[segment] = value
+			return container, nil
+		case []interface{}:
+			index, err := arrayIndex(segment, len(container), true)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrPathNotFound, path, err)
+			}
+			result := make([]interface{}, 0, len(container)+1)
+			result = append(result, container[:index]...)
+			result = append(result, value)
+			return append(result, container[index:]...), nil
+		}
+		return nil, fmt.Errorf("%w: %s", ErrPathNotFound, path)
+	})
+}
+
+func remove(doc interface{}, path Pointer) (interface{}, error) {
+	return update(doc, path, func(node interface{}, segment string) (interface{}, error) {
+		switch container := node.(type) {
+		case map[string]interface{}:
+			if _, ok := container[segment]; !ok {
+				return nil, fmt.Errorf("%w: %s", ErrPathNotFound, path)
+			}
+			delete(container, segment)
+			return container, nil
+		case []interface{}:
+			index, err := arrayIndex(segment, len(container), false)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrPathNotFound, path, err)
+			}
+			result := make([]interface{}, 0, len(container)-1)
+			result = append(result, container[:index]...)
+			return append(result, container[index+1:]...), nil
+		}
+		return nil, fmt.Errorf("%w: %s", ErrPathNotFound, path)
+	})
+}
+
+// equal compara dos valores JSON; los números se comparan por su valor, así
+// que 1 y 1.0 son iguales
+func equal(a, b interface{}) bool {
+	switch x := a.(type) {
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for key, value := range x {
+			other, ok := y[key]
+			if !ok || !equal(value, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case json.Number:
+		y, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		fx, okx := new(big.Float).SetString(x.String())
+		fy, oky := new(big.Float).SetString(y.String())
+		return okx && oky && fx.Cmp(fy) == 0
+	}
+	return a == b
+}
+
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = deepCopy(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = deepCopy(item)
+		}
+		return result
+	}
+	return value
+}
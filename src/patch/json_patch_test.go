@@ -0,0 +1,128 @@
+// patch/json_patch_test.go
+package patch
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func mustDecode(t *testing.T, data string) interface{} {
+	t.Helper()
+	value, err := Decode([]byte(data))
+	if err != nil {
+		t.Fatalf("JSON inválido %s: %v", data, err)
+	}
+	return value
+}
+
+func TestApply(t *testing.T) {
+	const doc = `{"Nombre":"Pedro","tags":["a","b"],"meta":{"a/b":1,"m~n":2}}`
+
+	tests := []struct {
+		name    string
+		ops     string
+		want    string
+		wantErr error
+		// wantIndex es la operación que falla
+		wantIndex int
+	}{
+		{"add", `[{"op":"add","path":"/Email","value":"pedro@example.com"}]`,
+			`{"Nombre":"Pedro","Email":"pedro@example.com","tags":["a","b"],"meta":{"a/b":1,"m~n":2}}`, nil, 0},
+		{"add al final con -", `[{"op":"add","path":"/tags/-","value":"c"}]`,
+			`{"Nombre":"Pedro","tags":["a","b","c"],"meta":{"a/b":1,"m~n":2}}`, nil, 0},
+		{"add en medio del arreglo", `[{"op":"add","path":"/tags/1","value":"x"}]`,
+			`{"Nombre":"Pedro","tags":["a","x","b"],"meta":{"a/b":1,"m~n":2}}`, nil, 0},
+		{"add con value null", `[{"op":"add","path":"/Email","value":null}]`,
+			`{"Nombre":"Pedro","Email":null,"tags":["a","b"],"meta":{"a/b":1,"m~n":2}}`, nil, 0},
+		{"remove", `[{"op":"remove","path":"/tags/0"}]`,
+			`{"Nombre":"Pedro","tags":["b"],"meta":{"a/b":1,"m~n":2}}`, nil, 0},
+		{"replace", `[{"op":"replace","path":"/Nombre","value":"Ana"}]`,
+			`{"Nombre":"Ana","tags":["a","b"],"meta":{"a/b":1,"m~n":2}}`, nil, 0},
+		{"replace del documento", `[{"op":"replace","path":"","value":{"x":1}}]`, `{"x":1}`, nil, 0},
+		{"move", `[{"op":"move","from":"/Nombre","path":"/meta/nombre"}]`,
+			`{"tags":["a","b"],"meta":{"a/b":1,"m~n":2,"nombre":"Pedro"}}`, nil, 0},
+		{"copy", `[{"op":"copy","from":"/tags/1","path":"/tags/-"}]`,
+			`{"Nombre":"Pedro","tags":["a","b","b"],"meta":{"a/b":1,"m~n":2}}`, nil, 0},
+		{"escape ~1", `[{"op":"replace","path":"/meta/a~1b","value":10}]`,
+			`{"Nombre":"Pedro","tags":["a","b"],"meta":{"a/b":10,"m~n":2}}`, nil, 0},
+		{"escape ~0", `[{"op":"remove","path":"/meta/m~0n"}]`,
+			`{"Nombre":"Pedro","tags":["a","b"],"meta":{"a/b":1}}`, nil, 0},
+		{"test que coincide", `[{"op":"test","path":"/meta/a~1b","value":1.0},{"op":"replace","path":"/Nombre","value":"Ana"}]`,
+			`{"Nombre":"Ana","tags":["a","b"],"meta":{"a/b":1,"m~n":2}}`, nil, 0},
+
+		{"test que falla", `[{"op":"replace","path":"/Nombre","value":"Ana"},{"op":"test","path":"/Nombre","value":"Pedro"}]`,
+			"", ErrTestFailed, 1},
+		{"test de un arreglo distinto", `[{"op":"test","path":"/tags","value":["b","a"]}]`, "", ErrTestFailed, 0},
+		{"- fuera de add", `[{"op":"remove","path":"/tags/-"}]`, "", ErrPathNotFound, 0},
+		{"índice con cero a la izquierda", `[{"op":"replace","path":"/tags/01","value":"x"}]`, "", ErrPathNotFound, 0},
+		{"índice fuera del arreglo", `[{"op":"add","path":"/tags/3","value":"x"}]`, "", ErrPathNotFound, 0},
+		{"ruta inexistente", `[{"op":"remove","path":"/Email"}]`, "", ErrPathNotFound, 0},
+		{"escape inválido", `[{"op":"remove","path":"/meta/m~2n"}]`, "", ErrInvalidOperation, 0},
+		{"falta value", `[{"op":"add","path":"/Email"}]`, "", ErrInvalidOperation, 0},
+		{"op desconocida", `[{"op":"rename","path":"/Nombre"}]`, "", ErrInvalidOperation, 0},
+		{"remove del documento", `[{"op":"remove","path":""}]`, "", ErrInvalidOperation, 0},
+		{"move dentro de sí mismo", `[{"op":"move","from":"/meta","path":"/meta/copia"}]`, "", ErrInvalidOperation, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := mustDecode(t, doc)
+			ops, err := ParseOperations([]byte(tt.ops))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := Apply(original, ops)
+			if tt.wantErr != nil {
+				var opErr *OpError
+				if !errors.As(err, &opErr) || !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, se esperaba %v", err, tt.wantErr)
+				}
+				if opErr.Index != tt.wantIndex {
+					t.Errorf("falló la operación %d, se esperaba la %d", opErr.Index, tt.wantIndex)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("error inesperado: %v", err)
+				}
+				if want := mustDecode(t, tt.want); !equal(result, want) {
+					t.Errorf("resultado = %v, se esperaba %v", result, want)
+				}
+			}
+
+			// El documento original nunca se modifica, aunque el parche falle
+			if !equal(original, mustDecode(t, doc)) {
+				t.Errorf("el documento original cambió: %v", original)
+			}
+		})
+	}
+}
+
+func TestParsePointer(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Pointer
+		wantErr bool
+	}{
+		{"", Pointer{}, false},
+		{"/", Pointer{""}, false},
+		{"/a/0", Pointer{"a", "0"}, false},
+		{"/a~1b/m~0n", Pointer{"a/b", "m~n"}, false},
+		// ~01 es "~1" literal, no "/"
+		{"/~01", Pointer{"~1"}, false},
+		{"a/b", nil, true},
+		{"/a~", nil, true},
+		{"/a~2", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParsePointer(tt.input)
+		if (err != nil) != tt.wantErr || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParsePointer(%q) = %q, %v; se esperaba %q (error %v)", tt.input, got, err, tt.want, tt.wantErr)
+		}
+		if err == nil && got.String() != tt.input && tt.input != "" {
+			t.Errorf("Pointer(%q).String() = %q", tt.input, got.String())
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// patch/merge.go
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Decode lee un documento JSON conservando los números como json.Number,
+// para que la comparación de "test" no dependa de la conversión a float64
+func Decode(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("hay datos después del documento JSON")
+	}
+	return value, nil
+}
+
+// MergePatch aplica un JSON Merge Patch (RFC 7396): los miembros del parche
+// reemplazan a los del documento, null elimina el miembro y los objetos se
+// combinan de forma recursiva. Un parche que no es objeto reemplaza al
+// documento completo. El documento original no se modifica.
+func MergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	result := make(map[string]interface{}, len(targetObject)+len(patchObject))
+	if ok {
+		for name, value := range targetObject {
+			result[name] = value
+		}
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(result, name)
+			continue
+		}
+		result[name] = MergePatch(result[name], value)
+	}
+	return result
+}
@@ -0,0 +1,36 @@
+// patch/merge_test.go
+package patch
+
+import "testing"
+
+// Casos del apéndice A de RFC 7396
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name, target, patch, want string
+	}{
+		{"reemplaza", `{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"agrega", `{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{"null elimina", `{"a":"b"}`, `{"a":null}`, `{}`},
+		{"null de un miembro inexistente", `{"a":"b"}`, `{"c":null}`, `{"a":"b"}`},
+		{"null anidado", `{"a":{"b":"c","d":"e"}}`, `{"a":{"b":null}}`, `{"a":{"d":"e"}}`},
+		{"arreglo reemplaza", `{"a":["b"]}`, `{"a":["c","d"]}`, `{"a":["c","d"]}`},
+		{"objeto sobre escalar", `{"a":"b"}`, `{"a":{"b":"c"}}`, `{"a":{"b":"c"}}`},
+		{"null dentro de un objeto nuevo", `{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		{"parche que no es objeto", `{"a":"b"}`, `["c"]`, `["c"]`},
+		{"null como parche", `{"a":"b"}`, `null`, `null`},
+		{"destino que no es objeto", `["a"]`, `{"a":"b"}`, `{"a":"b"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := mustDecode(t, tt.target)
+			got := MergePatch(target, mustDecode(t, tt.patch))
+			if want := mustDecode(t, tt.want); !equal(got, want) {
+				t.Errorf("MergePatch(%s, %s) = %v, se esperaba %v", tt.target, tt.patch, got, want)
+			}
+			if !equal(target, mustDecode(t, tt.target)) {
+				t.Errorf("el documento original cambió: %v", target)
+			}
+		})
+	}
+}
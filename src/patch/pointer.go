@@ -0,0 +1,78 @@
+// patch/pointer.go
+package patch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Pointer es un JSON Pointer (RFC 6901) ya separado en sus segmentos
+type Pointer []string
+
+// ParsePointer separa el puntero y resuelve los escapes "~1" (/) y "~0" (~).
+// El puntero vacío apunta al documento completo.
+func ParsePointer(value string) (Pointer, error) {
+	if value == "" {
+		return Pointer{}, nil
+	}
+	if !strings.HasPrefix(value, "/") {
+		return nil, fmt.Errorf("el puntero %q debe empezar con /", value)
+	}
+	segments := strings.Split(value[1:], "/")
+	for i, segment := range segments {
+		for j := 0; j < len(segment); j++ {
+			if segment[j] == '~' && (j+1 >= len(segment) || (segment[j+1] != '0' && segment[j+1] != '1')) {
+				return nil, fmt.Errorf("escape inválido en el puntero %q", value)
+			}
+		}
+		segments[i] = strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+	}
+	return segments, nil
+}
+
+func (p Pointer) String() string {
+	var b strings.Builder
+	for _, segment := range p {
+		b.WriteByte('/')
+		b.WriteString(strings.ReplaceAll(strings.ReplaceAll(segment, "~", "~0"), "/", "~1"))
+	}
+	return b.String()
+}
+
+// IsPrefixOf indica si p es el mismo puntero que other o uno de sus ancestros
+func (p Pointer) IsPrefixOf(other Pointer) bool {
+	if len(p) > len(other) {
+		return false
+	}
+	for i := range p {
+		if p[i] != other[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// arrayIndex interpreta un segmento como índice de arreglo; "-" (el final)
+// solo se admite al agregar
+func arrayIndex(segment string, length int, appending bool) (int, error) {
+	if segment == "-" && appending {
+		return length, nil
+	}
+	// Sin ceros a la izquierda ni signo (RFC 6901, sección 4)
+	if segment == "" || (len(segment) > 1 && segment[0] == '0') || strings.TrimLeft(segment, "0123456789") != "" {
+		return 0, fmt.Errorf("índice de arreglo inválido %q", segment)
+	}
+	index, err := strconv.Atoi(segment)
+	if err != nil {
+		return 0, fmt.Errorf("índice de arreglo inválido %q", segment)
+	}
+	max := length - 1
+	if appending {
+		max = length
+	}
+	if index > max {
+		return 0, fmt.Errorf("índice %d fuera del arreglo de %d elementos", index, length)
+	}
+	return index, nil
+}
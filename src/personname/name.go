@@ -0,0 +1,130 @@
+// personname/name.go
+package personname
+
+import "strings"
+
+// Partículas que forman parte del apellido o nombre que las sigue
+// (De la Cruz, del Valle, van der Berg)
+var particles = map[string]bool{
+	"de": true, "del": true, "la": true, "las": true, "los": true,
+	"da": true, "das": true, "do": true, "dos": true, "di": true,
+	"van": true, "von": true, "der": true, "den": true, "san": true, "santa": true,
+}
+
+// Conjunciones que unen dos apellidos en uno solo (Ortega y Gasset)
+var conjunctions = map[string]bool{"y": true}
+
+// Part es una palabra del nombre, que puede ser compuesta con guiones o
+// apóstrofos (María-José, O'Connor); Offset y Length están en runas
+type Part struct {
+	Value  string
+	Offset int
+	Length int
+}
+
+// Name es el nombre de una persona separado en nombres de pila y apellidos
+type Name struct {
+	// Normalized es el nombre en NFC con un solo espacio entre palabras
+	Normalized string
+	Tokens     []Token
+	Parts      []Part
+	// BadConnectors son los guiones o apóstrofos que no están entre dos letras
+	BadConnectors []Token
+	// Given son los nombres de pila; Paterno y Materno los apellidos
+	Given   []string
+	Paterno string
+	Materno string
+}
+
+// Parse analiza un nombre ya normalizado con Normalize. Las partículas se
+// agrupan con la palabra siguiente y las conjunciones unen dos palabras; de
+// las unidades resultantes, las dos últimas se toman como apellido paterno y
+// materno (convención hispana) y las demás como nombres de pila. Con dos
+// unidades solo hay apellido paterno.
+func Parse(input string) Name {
+	name := Name{Tokens: Tokenize(input)}
+	name.Parts, name.BadConnectors = splitParts(name.Tokens)
+
+	values := make([]string, len(name.Parts))
+	for i, part := range name.Parts {
+		values[i] = part.Value
+	}
+	name.Normalized = strings.Join(values, " ")
+
+	units := groupUnits(values)
+	switch n := len(units); {
+	case n == 1:
+		name.Given = units
+	case n == 2:
+		name.Given, name.Paterno = units[:1], units[1]
+	case n > 2:
+		name.Given, name.Paterno, name.Materno = units[:n-2], units[n-2], units[n-1]
+	}
+	return name
+}
+
+// splitParts junta palabras, guiones y apóstrofos contiguos; los demás tokens
+// (espacios, dígitos y símbolos) separan las partes
+func splitParts(tokens []Token) ([]Part, []Token) {
+	var parts []Part
+	var bad []Token
+
+	for i := 0; i < len(tokens); {
+		if !inPart(tokens[i].Type) {
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(tokens) && inPart(tokens[i].Type) {
+			tok := tokens[i]
+			if tok.Type != Word {
+				// El conector debe tener una palabra a cada lado dentro de la parte
+				prevWord := i > start && tokens[i-1].Type == Word
+				nextWord := i+1 < len(tokens) && tokens[i+1].Type == Word
+				if !prevWord || !nextWord {
+					bad = append(bad, tok)
+				}
+			}
+			i++
+		}
+
+		first, last := tokens[start], tokens[i-1]
+		var value strings.Builder
+		for _, tok := range tokens[start:i] {
+			value.WriteString(tok.Value)
+		}
+		parts = append(parts, Part{Value: value.String(), Offset: first.Offset, Length: last.End() - first.Offset})
+	}
+	return parts, bad
+}
+
+func inPart(t TokenType) bool {
+	return t == Word || t == Hyphen || t == Apostrophe
+}
+
+// groupUnits une las partículas con la palabra que sigue y las conjunciones
+// con las palabras a sus lados
+func groupUnits(values []string) []string {
+	var units []string
+	var pending []string
+
+	for i := 0; i < len(values); i++ {
+		value := values[i]
+		lower := strings.ToLower(value)
+
+		if conjunctions[lower] && len(pending) == 0 && len(units) > 0 && i+1 < len(values) {
+			pending = append(pending, units[len(units)-1], value)
+			units = units[:len(units)-1]
+			continue
+		}
+		pending = append(pending, value)
+		// Una partícula espera a la palabra siguiente, salvo al final
+		if particles[lower] && i+1 < len(values) {
+			continue
+		}
+		units = append(units, strings.Join(pending, " "))
+		pending = nil
+	}
+	return units
+}
@@ -0,0 +1,101 @@
+// personname/name_test.go
+package personname
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		wantNormalized string
+		wantGiven      []string
+		wantPaterno    string
+		wantMaterno    string
+	}{
+		{"solo nombre", "Pedro", "Pedro", []string{"Pedro"}, "", ""},
+		{"nombre y apellido", "Pedro Pérez", "Pedro Pérez", []string{"Pedro"}, "Pérez", ""},
+		{"dos apellidos", "Pedro Pérez López", "Pedro Pérez López", []string{"Pedro"}, "Pérez", "López"},
+		{"dos nombres de pila", "Juan Carlos Pérez López", "Juan Carlos Pérez López", []string{"Juan", "Carlos"}, "Pérez", "López"},
+		{"partículas", "Juan Carlos de la Cruz Gómez", "Juan Carlos de la Cruz Gómez", []string{"Juan", "Carlos"}, "de la Cruz", "Gómez"},
+		{"partícula en el materno", "Ana Ruiz del Valle", "Ana Ruiz del Valle", []string{"Ana"}, "Ruiz", "del Valle"},
+		{"conjunción", "José Ortega y Gasset", "José Ortega y Gasset", []string{"José"}, "Ortega y Gasset", ""},
+		{"partícula al final", "Pedro del", "Pedro del", []string{"Pedro"}, "del", ""},
+		{"compuestos", "María-José O'Connor Ruiz", "María-José O'Connor Ruiz", []string{"María-José"}, "O'Connor", "Ruiz"},
+		{"espacios repetidos", "  Ana   Ruiz ", "Ana Ruiz", []string{"Ana"}, "Ruiz", ""},
+		{"vacío", "", "", nil, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := Parse(tt.input)
+			if name.Normalized != tt.wantNormalized {
+				t.Errorf("Normalized = %q, se esperaba %q", name.Normalized, tt.wantNormalized)
+			}
+			if !reflect.DeepEqual(name.Given, tt.wantGiven) || name.Paterno != tt.wantPaterno || name.Materno != tt.wantMaterno {
+				t.Errorf("Parse(%q) = %q / %q / %q, se esperaba %q / %q / %q", tt.input,
+					name.Given, name.Paterno, name.Materno, tt.wantGiven, tt.wantPaterno, tt.wantMaterno)
+			}
+		})
+	}
+}
+
+// Los dígitos y símbolos separan las partes; los offsets están en runas
+func TestParseParts(t *testing.T) {
+	name := Parse("Ana Ruiz3 López")
+	want := []Part{
+		{Value: "Ana", Offset: 0, Length: 3},
+		{Value: "Ruiz", Offset: 4, Length: 4},
+		{Value: "López", Offset: 10, Length: 5},
+	}
+	if !reflect.DeepEqual(name.Parts, want) {
+		t.Errorf("Parts = %+v, se esperaba %+v", name.Parts, want)
+	}
+}
+
+func TestParseBadConnectors(t *testing.T) {
+	tests := []struct {
+		input       string
+		wantOffsets []int
+	}{
+		{"María-José Ruiz", nil},
+		{"Pedro -Ruiz", []int{6}},
+		{"Pedro- Ruiz", []int{5}},
+		{"O''Connor", []int{1, 2}},
+		{"'Ana Ruiz", []int{0}},
+	}
+
+	for _, tt := range tests {
+		var offsets []int
+		for _, tok := range Parse(tt.input).BadConnectors {
+			offsets = append(offsets, tok.Offset)
+		}
+		if !reflect.DeepEqual(offsets, tt.wantOffsets) {
+			t.Errorf("Parse(%q).BadConnectors en %v, se esperaba %v", tt.input, offsets, tt.wantOffsets)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		want          string
+		wantPositions []int
+	}{
+		{"ya en NFC", "Jos\u00e9", "Jos\u00e9", nil},
+		// La é compuesta queda en la posición de la e original
+		{"acento combinado", "Jose\u0301 Ruiz", "Jos\u00e9 Ruiz", []int{0, 1, 2, 3, 5, 6, 7, 8, 9}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, positions := Normalize(tt.input)
+			if got != tt.want || !reflect.DeepEqual(positions, tt.wantPositions) {
+				t.Errorf("Normalize(%q) = %q %v, se esperaba %q %v", tt.input, got, positions, tt.want, tt.wantPositions)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+// personname/normalize.go
+package personname
+
+import (
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalize devuelve el nombre en forma NFC (letras y acentos combinados en un
+// solo carácter cuando existe) y la posición, en runas dentro de la entrada,
+// de cada runa del resultado. Positions es nil si la entrada ya estaba en NFC.
+func Normalize(input string) (string, []int) {
+	if norm.NFC.IsNormalString(input) {
+		return input, nil
+	}
+
+	var out []rune
+	var positions []int
+	var it norm.Iter
+	it.InitString(norm.NFC, input)
+
+	source := 0
+	for !it.Done() {
+		start := it.Pos()
+		segment := it.Next()
+		// Las runas de un segmento se ubican al inicio del segmento original
+		for _, r := range string(segment) {
+			out = append(out, r)
+			positions = append(positions, source)
+		}
+		source += utf8.RuneCountInString(input[start:it.Pos()])
+	}
+	return string(out), positions
+}
@@ -0,0 +1,100 @@
+// personname/token.go
+package personname
+
+import "unicode"
+
+// TokenType identifica la categoría léxica de un token del nombre
+type TokenType int
+
+const (
+	Word       TokenType = iota // letras con sus marcas diacríticas
+	Space                       // espacios en blanco
+	Hyphen                      // guión de un nombre compuesto (María-José)
+	Apostrophe                  // apóstrofo (O'Connor, D'Angelo)
+	Digit                       // secuencia de dígitos
+	Symbol                      // cualquier otro carácter
+)
+
+var tokenNames = map[TokenType]string{
+	Word:       "WORD",
+	Space:      "SPACE",
+	Hyphen:     "HYPHEN",
+	Apostrophe: "APOSTROPHE",
+	Digit:      "DIGIT_RUN",
+	Symbol:     "SYMBOL",
+}
+
+func (t TokenType) String() string {
+	if name, ok := tokenNames[t]; ok {
+		return name
+	}
+	return "ILLEGAL"
+}
+
+// Token es un lexema del nombre; Offset y Length se expresan en runas del
+// nombre normalizado
+type Token struct {
+	Type   TokenType
+	Value  string
+	Offset int
+	Length int
+}
+
+func (t Token) End() int {
+	return t.Offset + t.Length
+}
+
+// Las marcas combinables que quedan después de NFC son parte de la palabra
+func isLetter(r rune) bool {
+	return unicode.IsLetter(r) || unicode.In(r, unicode.Mn, unicode.Mc, unicode.Me)
+}
+
+func isHyphen(r rune) bool {
+	return r == '-' || r == '‐' || r == '‑'
+}
+
+// Apóstrofo recto, tipográfico y la letra modificadora que se usa en algunos teclados
+func isApostrophe(r rune) bool {
+	return r == '\'' || r == '’' || r == 'ʼ'
+}
+
+// Tokenize separa el nombre (ya normalizado) en tokens
+func Tokenize(input string) []Token {
+	runes := []rune(input)
+	var tokens []Token
+
+	for pos := 0; pos < len(runes); {
+		start := pos
+		consume := func(accept func(rune) bool) {
+			for pos < len(runes) && accept(runes[pos]) {
+				pos++
+			}
+		}
+
+		var kind TokenType
+		r := runes[pos]
+		switch {
+		// El apóstrofo U+02BC también es letra; se revisa antes
+		case isApostrophe(r):
+			kind = Apostrophe
+			pos++
+		case isHyphen(r):
+			kind = Hyphen
+			pos++
+		case isLetter(r):
+			kind = Word
+			consume(func(r rune) bool { return isLetter(r) && !isApostrophe(r) })
+		case unicode.IsSpace(r):
+			kind = Space
+			consume(unicode.IsSpace)
+		case unicode.IsDigit(r):
+			kind = Digit
+			consume(unicode.IsDigit)
+		default:
+			kind = Symbol
+			pos++
+		}
+		tokens = append(tokens, Token{Type: kind, Value: string(runes[start:pos]), Offset: start, Length: pos - start})
+	}
+	return tokens
+}
@@ -0,0 +1,162 @@
+// phone/country.go
+package phone
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Country describe las reglas de numeración de un país
+type Country struct {
+	// Region es el código ISO 3166 (MX, US, CA, GT, ES)
+	Region      string
+	Name        string
+	CallingCode string
+	// Length es la cantidad de dígitos del número nacional
+	Length int
+	// validPrefix revisa los primeros dígitos del número nacional
+	validPrefix func(national string) bool
+	// trunkPrefixes son prefijos de marcación nacional que se eliminan cuando
+	// el número tiene exactamente esa longitud total
+	trunkPrefixes []trunkPrefix
+	format        func(national string) string
+}
+
+type trunkPrefix struct {
+	digits string
+	total  int
+}
+
+// ValidPrefix indica si los primeros dígitos son válidos en el país; con
+// menos de 3 dígitos no hay suficiente información y se acepta
+func (c *Country) ValidPrefix(national string) bool {
+	return c.validPrefix == nil || len(national) < 3 || c.validPrefix(national)
+}
+
+// Format devuelve el número nacional con la separación que se usa en el país
+func (c *Country) Format(national string) string {
+	if c.format == nil || len(national) != c.Length {
+		return national
+	}
+	return c.format(national)
+}
+
+var (
+	mexico = &Country{
+		// En México los primeros dígitos se revisan contra la tabla de ladas
+		Region: "MX", Name: "México", CallingCode: "52", Length: NationalLength,
+		// El 1 después de +52 y los prefijos 044/045 (celular local y de larga
+		// distancia) y 01 ya no se marcan desde 2019, pero siguen apareciendo en
+		// datos capturados antes
+		trunkPrefixes: []trunkPrefix{{"521", 13}, {"52", 12}, {"044", 13}, {"045", 13}, {"01", 12}},
+		format: func(n string) string {
+			if lada, ok := Lookup(n); ok && len(lada.Codigo) == 2 {
+				return n[:2] + " " + n[2:6] + " " + n[6:]
+			}
+			return n[:3] + " " + n[3:6] + " " + n[6:]
+		},
+	}
+	unitedStates = &Country{
+		Region: "US", Name: "Estados Unidos", CallingCode: "1", Length: 10,
+		validPrefix:   validNANP,
+		trunkPrefixes: []trunkPrefix{{"1", 11}},
+		format:        formatNANP,
+	}
+	canada = &Country{
+		Region: "CA", Name: "Canadá", CallingCode: "1", Length: 10,
+		validPrefix:   validNANP,
+		trunkPrefixes: []trunkPrefix{{"1", 11}},
+		format:        formatNANP,
+	}
+	guatemala = &Country{
+		Region: "GT", Name: "Guatemala", CallingCode: "502", Length: 8,
+		validPrefix: func(n string) bool { return n[0] >= '2' && n[0] <= '7' },
+		format:      func(n string) string { return n[:4] + " " + n[4:] },
+	}
+	spain = &Country{
+		Region: "ES", Name: "España", CallingCode: "34", Length: 9,
+		validPrefix: func(n string) bool { return n[0] >= '6' },
+		format:      func(n string) string { return n[:3] + " " + n[3:5] + " " + n[5:7] + " " + n[7:] },
+	}
+)
+
+// Países soportados, por código ISO
+var countries = map[string]*Country{
+	"MX": mexico,
+	"US": unitedStates,
+	"CA": canada,
+	"GT": guatemala,
+	"ES": spain,
+}
+
+// Claves de país reconocidas después de +, de la más larga a la más corta
+var callingCodes = []string{"502", "52", "34", "1"}
+
+// En el Plan de Numeración de Norteamérica la lada y la central empiezan con
+// 2-9 y la lada no puede ser un código de servicio N11
+func validNANP(n string) bool {
+	if n[0] < '2' || (n[1] == '1' && n[2] == '1') {
+		return false
+	}
+	return len(n) < 4 || n[3] >= '2'
+}
+
+func formatNANP(n string) string {
+	return "(" + n[:3] + ") " + n[3:6] + "-" + n[6:]
+}
+
+// Ladas de Canadá; el resto de las ladas con clave +1 se asignan a Estados Unidos
+var canadianAreaCodes = map[string]bool{
+	"204": true, "226": true, "236": true, "249": true, "250": true, "263": true, "289": true,
+	"306": true, "343": true, "354": true, "365": true, "367": true, "368": true, "382": true,
+	"403": true, "416": true, "418": true, "428": true, "431": true, "437": true, "438": true,
+	"450": true, "468": true, "474": true, "506": true, "514": true, "519": true, "548": true,
+	"579": true, "581": true, "584": true, "587": true, "604": true, "613": true, "639": true,
+	"647": true, "672": true, "683": true, "705": true, "709": true, "742": true, "753": true,
+	"778": true, "780": true, "782": true, "807": true, "819": true, "825": true, "867": true,
+	"873": true, "879": true, "902": true, "905": true,
+}
+
+// nanpCountry distingue Estados Unidos de Canadá por la lada
+func nanpCountry(national string) *Country {
+	if len(national) >= 3 && canadianAreaCodes[national[:3]] {
+		return canada
+	}
+	return unitedStates
+}
+
+// LookupCountry busca un país soportado por su código ISO
+func LookupCountry(region string) (*Country, bool) {
+	country, ok := countries[strings.ToUpper(strings.TrimSpace(region))]
+	return country, ok
+}
+
+// Regions devuelve los códigos ISO de los países soportados
+func Regions() []string {
+	return []string{"MX", "US", "CA", "GT", "ES"}
+}
+
+var (
+	regionMu      sync.RWMutex
+	defaultRegion = mexico
+)
+
+// DefaultRegion es el país que se asume cuando el número no trae clave de país
+func DefaultRegion() *Country {
+	regionMu.RLock()
+	defer regionMu.RUnlock()
+	return defaultRegion
+}
+
+// SetDefaultRegion cambia el país por defecto
+func SetDefaultRegion(region string) error {
+	country, ok := LookupCountry(region)
+	if !ok {
+		return fmt.Errorf("país no soportado: %q (disponibles: %s)", region, strings.Join(Regions(), ", "))
+	}
+	regionMu.Lock()
+	defer regionMu.Unlock()
+	defaultRegion = country
+	return nil
+}
@@ -0,0 +1,126 @@
+// phone/normalize.go
+package phone
+
+import "strings"
+
+// NationalLength es la longitud de un número nacional en México
+const NationalLength = 10
+
+// Number es un número telefónico separado en país y dígitos nacionales
+type Number struct {
+	// Country es nil cuando la clave de país no está soportada
+	Country *Country
+	// National son los dígitos sin separadores ni prefijos de marcación
+	National string
+	// Positions guarda la posición (en runas) de cada dígito de National en la entrada
+	Positions []int
+	// Prefix es el prefijo de marcación que se eliminó ("+52", "+521", "044", "00502", ...)
+	Prefix string
+	// International indica que la entrada traía clave de país (+ o 00)
+	International bool
+}
+
+// Parse separa una entrada como "+52 1 961 123 4567", "(961) 123-4567",
+// "+1 (212) 555-1234" o "044 961 123 4567" en país y número nacional. Sin
+// clave de país se usa el país por defecto. Los caracteres que no son dígitos
+// se ignoran; validar que sean separadores válidos le corresponde a quien llama.
+func Parse(input string, def *Country) Number {
+	var digits []byte
+	var positions []int
+	plus := false
+	for i, r := range []rune(input) {
+		if r >= '0' && r <= '9' {
+			digits = append(digits, byte(r))
+			positions = append(positions, i)
+		} else if r == '+' && i == 0 {
+			plus = true
+		}
+	}
+	number := Number{National: string(digits), Positions: positions}
+
+	prefix := ""
+	if plus {
+		prefix = "+"
+	} else if strings.HasPrefix(number.National, "00") {
+		// 00 es el prefijo internacional en México, Guatemala y España
+		number = number.strip(2, "00")
+		prefix = "00"
+	} else {
+		number.Country = def
+		for _, trunk := range def.trunkPrefixes {
+			if len(digits) == trunk.total && strings.HasPrefix(number.National, trunk.digits) {
+				number = number.strip(len(trunk.digits), trunk.digits)
+				break
+			}
+		}
+		if def.CallingCode == "1" {
+			number.Country = nanpCountry(number.National)
+		}
+		return number
+	}
+
+	number.International = true
+	for _, code := range callingCodes {
+		if !strings.HasPrefix(number.National, code) {
+			continue
+		}
+		number = number.strip(len(code), prefix+code)
+		switch code {
+		case "52":
+			number.Country = mexico
+			// El 1 de celular que se marcaba después de +52
+			if len(number.National) == NationalLength+1 && number.National[0] == '1' {
+				number = number.strip(1, number.Prefix+"1")
+			}
+		case "1":
+			number.Country = nanpCountry(number.National)
+		case "502":
+			number.Country = guatemala
+		case "34":
+			number.Country = spain
+		}
+		return number
+	}
+	// Clave de país no soportada: se conservan todos los dígitos
+	number.Prefix = prefix
+	return number
+}
+
+func (n Number) strip(size int, prefix string) Number {
+	return Number{
+		Country:       n.Country,
+		National:      n.National[size:],
+		Positions:     n.Positions[size:],
+		Prefix:        prefix,
+		International: n.International,
+	}
+}
+
+// Valid indica si el número cumple la longitud y el prefijo de su país; en
+// México además la lada debe estar en el plan de numeración
+func (n Number) Valid() bool {
+	if n.Country == nil || len(n.National) != n.Country.Length || !n.Country.ValidPrefix(n.National) {
+		return false
+	}
+	if n.Country == mexico {
+		_, ok := Lookup(n.National)
+		return ok
+	}
+	return true
+}
+
+// E164 devuelve el número en formato internacional, p. ej. +529611234567
+func (n Number) E164() string {
+	if n.Country == nil {
+		return ""
+	}
+	return "+" + n.Country.CallingCode + n.National
+}
+
+// Format devuelve la forma nacional para mostrar, p. ej. "961 123 4567"
+func (n Number) Format() string {
+	if n.Country == nil {
+		return n.National
+	}
+	return n.Country.Format(n.National)
+}
@@ -0,0 +1,84 @@
+// phone/normalize_test.go
+package phone
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		def          *Country
+		wantRegion   string
+		wantNational string
+		wantPrefix   string
+		wantValid    bool
+		wantE164     string
+		wantFormat   string
+	}{
+		{"nacional", "(961) 123-4567", mexico, "MX", "9611234567", "", true, "+529611234567", "961 123 4567"},
+		{"lada de 2 dígitos", "55 1234 5678", mexico, "MX", "5512345678", "", true, "+525512345678", "55 1234 5678"},
+		{"+52 con 1 de celular", "+52 1 961 123 4567", mexico, "MX", "9611234567", "+521", true, "+529611234567", "961 123 4567"},
+		{"+52", "+529611234567", mexico, "MX", "9611234567", "+52", true, "+529611234567", "961 123 4567"},
+		{"521 sin +", "5219611234567", mexico, "MX", "9611234567", "521", true, "+529611234567", "961 123 4567"},
+		{"044", "044 961 123 4567", mexico, "MX", "9611234567", "044", true, "+529611234567", "961 123 4567"},
+		{"01", "01 961 123 4567", mexico, "MX", "9611234567", "01", true, "+529611234567", "961 123 4567"},
+		{"Estados Unidos", "+1 (212) 555-1234", mexico, "US", "2125551234", "+1", true, "+12125551234", "(212) 555-1234"},
+		{"Canadá", "+1 416 555 1234", mexico, "CA", "4165551234", "+1", true, "+14165551234", "(416) 555-1234"},
+		{"NANP por defecto con 1", "1 212 555 1234", unitedStates, "US", "2125551234", "1", true, "+12125551234", "(212) 555-1234"},
+		{"Guatemala", "+502 2345 6789", mexico, "GT", "23456789", "+502", true, "+50223456789", "2345 6789"},
+		{"España con 00", "0034 612 345 678", mexico, "ES", "612345678", "0034", true, "+34612345678", "612 34 56 78"},
+
+		{"lada inexistente", "100 123 4567", mexico, "MX", "1001234567", "", false, "+521001234567", "100 123 4567"},
+		{"corto", "961 123 456", mexico, "MX", "961123456", "", false, "+52961123456", "961123456"},
+		{"código N11", "+1 911 555 1234", mexico, "US", "9115551234", "+1", false, "+19115551234", "(911) 555-1234"},
+		{"central que empieza con 1", "+1 212 155 1234", mexico, "US", "2121551234", "+1", false, "+12121551234", "(212) 155-1234"},
+		{"Guatemala con prefijo inválido", "+502 9345 6789", mexico, "GT", "93456789", "+502", false, "+50293456789", "9345 6789"},
+		{"clave no soportada", "+44 20 7946 0958", mexico, "", "442079460958", "+", false, "", "442079460958"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			number := Parse(tt.input, tt.def)
+			region := ""
+			if number.Country != nil {
+				region = number.Country.Region
+			}
+			if region != tt.wantRegion || number.National != tt.wantNational || number.Prefix != tt.wantPrefix {
+				t.Errorf("Parse(%q) = %s %q prefijo %q, se esperaba %s %q prefijo %q",
+					tt.input, region, number.National, number.Prefix, tt.wantRegion, tt.wantNational, tt.wantPrefix)
+			}
+			if got := number.Valid(); got != tt.wantValid {
+				t.Errorf("Valid() = %v, se esperaba %v", got, tt.wantValid)
+			}
+			if got := number.E164(); got != tt.wantE164 {
+				t.Errorf("E164() = %q, se esperaba %q", got, tt.wantE164)
+			}
+			if got := number.Format(); got != tt.wantFormat {
+				t.Errorf("Format() = %q, se esperaba %q", got, tt.wantFormat)
+			}
+		})
+	}
+}
+
+// Las posiciones apuntan a cada dígito nacional dentro de la entrada, sin
+// contar separadores ni el prefijo eliminado
+func TestParsePositions(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []int
+	}{
+		{"(961) 123-4567", []int{1, 2, 3, 6, 7, 8, 10, 11, 12, 13}},
+		{"+52 1 961 1234567", []int{6, 7, 8, 10, 11, 12, 13, 14, 15, 16}},
+		{"044-55-1234-5678", []int{4, 5, 7, 8, 9, 10, 12, 13, 14, 15}},
+	}
+
+	for _, tt := range tests {
+		number := Parse(tt.input, mexico)
+		if !reflect.DeepEqual(number.Positions, tt.want) {
+			t.Errorf("Parse(%q).Positions = %v, se esperaba %v", tt.input, number.Positions, tt.want)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// phone/plan.go
+package phone
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Tabla de ladas del Plan Nacional de Numeración (lada,estado,municipio).
+// Se puede sustituir en tiempo de ejecución con LoadPlanFile.
+//
+//go:embed data/ladas_mx.csv
+var ladasCSV []byte
+
+// Lada es un código de área de México con la región a la que pertenece
+type Lada struct {
+	Codigo    string `json:"lada"`
+	Estado    string `json:"estado"`
+	Municipio string `json:"municipio"`
+}
+
+// Plan es la tabla de ladas; desde 2019 las ladas de 2 dígitos (CDMX,
+// Guadalajara y Monterrey) no comparten prefijo con ninguna de 3
+type Plan struct {
+	ladas map[string]Lada
+}
+
+var ladaCode = regexp.MustCompile(`^[1-9][0-9]{1,2}$`)
+
+// ParsePlan lee una tabla CSV con encabezado lada,estado,municipio
+func ParsePlan(r io.Reader) (*Plan, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo tabla de ladas: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("la tabla de ladas está vacía")
+	}
+
+	plan := &Plan{ladas: make(map[string]Lada, len(records))}
+	for i, record := range records {
+		if i == 0 && strings.EqualFold(record[0], "lada") {
+			continue
+		}
+		codigo := strings.TrimSpace(record[0])
+		if !ladaCode.MatchString(codigo) {
+			return nil, fmt.Errorf("lada inválida en la línea %d: %q", i+1, codigo)
+		}
+		if _, exists := plan.ladas[codigo]; exists {
+			return nil, fmt.Errorf("lada duplicada en la línea %d: %s", i+1, codigo)
+		}
+		plan.ladas[codigo] = Lada{
+			Codigo:    codigo,
+			Estado:    strings.TrimSpace(record[1]),
+			Municipio: strings.TrimSpace(record[2]),
+		}
+	}
+	return plan, nil
+}
+
+// Lookup busca la lada con la que empieza un número nacional
+func (p *Plan) Lookup(national string) (Lada, bool) {
+	for _, size := range []int{2, 3} {
+		if len(national) < size {
+			break
+		}
+		if lada, ok := p.ladas[national[:size]]; ok {
+			return lada, true
+		}
+	}
+	return Lada{}, false
+}
+
+// States devuelve los estados presentes en la tabla, ordenados
+func (p *Plan) States() []string {
+	seen := make(map[string]bool)
+	var states []string
+	for _, lada := range p.ladas {
+		if !seen[lada.Estado] {
+			seen[lada.Estado] = true
+			states = append(states, lada.Estado)
+		}
+	}
+	sort.Strings(states)
+	return states
+}
+
+func (p *Plan) Len() int {
+	return len(p.ladas)
+}
+
+var (
+	planMu  sync.RWMutex
+	current = mustParsePlan(ladasCSV)
+)
+
+func mustParsePlan(data []byte) *Plan {
+	plan, err := ParsePlan(bytes.NewReader(data))
+	if err != nil {
+		panic(err)
+	}
+	return plan
+}
+
+// CurrentPlan devuelve la tabla de ladas en uso
+func CurrentPlan() *Plan {
+	planMu.RLock()
+	defer planMu.RUnlock()
+	return current
+}
+
+// SetPlan reemplaza la tabla de ladas en uso
+func SetPlan(plan *Plan) {
+	planMu.Lock()
+	defer planMu.Unlock()
+	current = plan
+}
+
+// LoadPlanFile reemplaza la tabla integrada por una exportación más reciente
+func LoadPlanFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error abriendo tabla de ladas: %w", err)
+	}
+	defer file.Close()
+
+	plan, err := ParsePlan(file)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	SetPlan(plan)
+	return nil
+}
+
+// Lookup busca la lada en la tabla en uso
+func Lookup(national string) (Lada, bool) {
+	return CurrentPlan().Lookup(national)
+}
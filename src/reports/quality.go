@@ -0,0 +1,426 @@
+// reports/quality.go
+package reports
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/sync/singleflight"
+
+	"api_compiladores/src/models"
+	"api_compiladores/src/utils"
+)
+
+// Elementos por omisión y máximos de cada lista del reporte
+const (
+	DefaultTop = 20
+	MaxTop     = 100
+)
+
+// Tiempo máximo de la agregación y del recorrido de similitud
+const queryMaxTime = 2 * time.Minute
+
+// Clientes de la muestra aleatoria con la que se calcula la similitud
+// nombre/email; recorrer la colección completa en Go no cabe en queryMaxTime
+const SimilaritySampleSize = 100000
+
+// Límites inferiores de los rangos de longitud del nombre (en caracteres);
+// los más largos van en el rango "100+"
+var nameLengthBoundaries = []int{0, 1, 5, 10, 15, 20, 25, 30, 40, 50, 75, 100}
+
+// activeFilter agrega a un filtro la condición de cliente sin baja lógica;
+// el reporte no cubre los clientes eliminados
+func activeFilter(filter bson.M) bson.M {
+	active := bson.M{"deleted_at": bson.M{"$exists": false}}
+	for key, value := range filter {
+		active[key] = value
+	}
+	return active
+}
+
+// QualityReport es el reporte de calidad de datos de la colección de clientes
+type QualityReport struct {
+	GeneratedAt  time.Time `json:"generated_at"`
+	Total        int64     `json:"total"`
+	Valid        int64     `json:"valid"`
+	Invalid      int64     `json:"invalid"`
+	ValidPercent float64   `json:"valid_percent"`
+	// Códigos de error más frecuentes, con el número de clientes que los tienen
+	TopErrors []ErrorStat `json:"top_errors"`
+	// Ladas y dominios de email más frecuentes
+	Ladas        []Bucket `json:"ladas"`
+	EmailDomains []Bucket `json:"email_domains"`
+	// Emails (sin distinguir mayúsculas) y celulares repetidos
+	DuplicateEmails Duplicates `json:"duplicate_emails"`
+	DuplicatePhones Duplicates `json:"duplicate_phones"`
+	// Histograma de la longitud del nombre en caracteres
+	NameLengths []Bucket `json:"name_lengths"`
+	// Similitud entre el nombre y la parte local del email
+	NameEmail SimilarityStats `json:"name_email_similarity"`
+}
+
+// ErrorStat es un código de error de un campo y un mensaje de ejemplo
+type ErrorStat struct {
+	Field    string `json:"field"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	Clientes int64  `json:"clientes"`
+}
+
+// Bucket es un valor (o rango) y cuántos clientes lo tienen; Label agrega
+// una descripción, como el estado de una lada
+type Bucket struct {
+	Key   string `json:"key"`
+	Label string `json:"label,omitempty"`
+	Count int64  `json:"count"`
+}
+
+// Duplicates cuenta los valores repetidos: cuántos valores distintos se
+// repiten, cuántos clientes los comparten y los más repetidos
+type Duplicates struct {
+	Values   int64    `json:"values"`
+	Clientes int64    `json:"clientes"`
+	Top      []Bucket `json:"top"`
+}
+
+// SimilarityStats resume la similitud nombre/email con el mismo cálculo que
+// la regla email.name_match: Mismatches son los que esa regla marcaría.
+// Se calcula sobre una muestra aleatoria de SampleSize clientes; si la
+// colección es menor, la muestra la cubre completa.
+type SimilarityStats struct {
+	SampleSize int      `json:"sample_size"`
+	Compared   int64    `json:"compared"`
+	Mismatches int64    `json:"mismatches"`
+	Threshold  float64  `json:"threshold"`
+	Histogram  []Bucket `json:"histogram"`
+}
+
+// Cálculos del reporte en curso por nombre de caché; las peticiones que
+// llegan mientras se calcula esperan ese resultado en lugar de recorrer otra
+// vez la colección
+var qualityBuilds singleflight.Group
+
+// LoadQuality devuelve el reporte guardado en caché (bajo el prefijo
+// stats:) o, si no hay uno o refresh es verdadero, lo calcula y lo guarda.
+// cached indica si el reporte vino de caché.
+func LoadQuality(ctx context.Context, collection *mongo.Collection, top int, refresh bool) (report *QualityReport, cached bool, err error) {
+	name := fmt.Sprintf("report:quality:top_%d", top)
+	if !refresh {
+		var stored QualityReport
+		if found, err := utils.GetCachedStats(name, &stored); found && err == nil {
+			return &stored, true, nil
+		}
+	}
+
+	built, err, _ := qualityBuilds.Do(name, func() (interface{}, error) {
+		report, err := BuildQuality(ctx, collection, top)
+		if err != nil {
+			return nil, err
+		}
+		if err := utils.CacheStats(name, report, utils.LongTTL); err != nil {
+			log.Printf("Error guardando reporte de calidad en caché: %v", err)
+		}
+		return report, nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return built.(*QualityReport), false, nil
+}
+
+// BuildQuality calcula el reporte con una sola agregación ($facet) sobre la
+// colección y un recorrido de nombre y email para la similitud
+func BuildQuality(ctx context.Context, collection *mongo.Collection, top int) (*QualityReport, error) {
+	report := &QualityReport{GeneratedAt: time.Now().UTC()}
+
+	cursor, err := collection.Aggregate(ctx, qualityPipeline(top),
+		options.Aggregate().SetAllowDiskUse(true).SetMaxTime(queryMaxTime))
+	if err != nil {
+		return nil, fmt.Errorf("error agregando el reporte de calidad: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var facets []struct {
+		Totals []struct {
+			Total   int64 `bson:"total"`
+			Invalid int64 `bson:"invalid"`
+		} `bson:"totals"`
+		Errors []struct {
+			ID struct {
+				Field string `bson:"field"`
+				Code  string `bson:"code"`
+			} `bson:"_id"`
+			Message  string `bson:"message"`
+			Clientes int64  `bson:"clientes"`
+		} `bson:"errors"`
+		Ladas            []rawBucket `bson:"ladas"`
+		EmailDomains     []rawBucket `bson:"email_domains"`
+		DuplicateEmails  []rawBucket `bson:"duplicate_emails"`
+		DuplicateEmailsN []rawTotal  `bson:"duplicate_emails_total"`
+		DuplicatePhones  []rawBucket `bson:"duplicate_phones"`
+		DuplicatePhonesN []rawTotal  `bson:"duplicate_phones_total"`
+		NameLengths      []rawBucket `bson:"name_lengths"`
+	}
+	if err := cursor.All(ctx, &facets); err != nil {
+		return nil, fmt.Errorf("error leyendo el reporte de calidad: %w", err)
+	}
+	if len(facets) == 0 {
+		return nil, fmt.Errorf("la agregación del reporte no devolvió resultados")
+	}
+	result := facets[0]
+
+	if len(result.Totals) > 0 {
+		report.Total = result.Totals[0].Total
+		report.Invalid = result.Totals[0].Invalid
+	}
+	report.Valid = report.Total - report.Invalid
+	if report.Total > 0 {
+		report.ValidPercent = math.Round(float64(report.Valid)*10000/float64(report.Total)) / 100
+	}
+
+	report.TopErrors = make([]ErrorStat, 0, len(result.Errors))
+	for _, row := range result.Errors {
+		// Los mensajes históricos sin migrar no tienen código
+		code := row.ID.Code
+		if code == "" {
+			code = models.LegacyCode
+		}
+		report.TopErrors = append(report.TopErrors, ErrorStat{
+			Field: row.ID.Field, Code: code, Message: row.Message, Clientes: row.Clientes,
+		})
+	}
+
+	report.Ladas = buckets(result.Ladas)
+	report.EmailDomains = buckets(result.EmailDomains)
+	report.DuplicateEmails = duplicates(result.DuplicateEmails, result.DuplicateEmailsN)
+	report.DuplicatePhones = duplicates(result.DuplicatePhones, result.DuplicatePhonesN)
+	report.NameLengths = nameLengthBuckets(result.NameLengths)
+
+	if report.NameEmail, err = nameEmailSimilarity(ctx, collection); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+type rawBucket struct {
+	ID    interface{} `bson:"_id"`
+	Label string      `bson:"label"`
+	Count int64       `bson:"count"`
+}
+
+type rawTotal struct {
+	Values   int64 `bson:"values"`
+	Clientes int64 `bson:"clientes"`
+}
+
+func buckets(rows []rawBucket) []Bucket {
+	result := make([]Bucket, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, Bucket{Key: fmt.Sprint(row.ID), Label: row.Label, Count: row.Count})
+	}
+	return result
+}
+
+func duplicates(rows []rawBucket, totals []rawTotal) Duplicates {
+	result := Duplicates{Top: buckets(rows)}
+	if len(totals) > 0 {
+		result.Values, result.Clientes = totals[0].Values, totals[0].Clientes
+	}
+	return result
+}
+
+// nameLengthBuckets etiqueta cada rango con sus límites ("5-9", "100+")
+func nameLengthBuckets(rows []rawBucket) []Bucket {
+	result := make([]Bucket, 0, len(rows))
+	for _, row := range rows {
+		key := fmt.Sprint(row.ID)
+		for i, lower := range nameLengthBoundaries[:len(nameLengthBoundaries)-1] {
+			if key == fmt.Sprint(lower) {
+				upper := nameLengthBoundaries[i+1] - 1
+				if upper == lower {
+					key = fmt.Sprint(lower)
+				} else {
+					key = fmt.Sprintf("%d-%d", lower, upper)
+				}
+				break
+			}
+		}
+		result = append(result, Bucket{Key: key, Count: row.Count})
+	}
+	return result
+}
+
+// qualityPipeline arma la agregación: cada faceta recorre los mismos
+// documentos, así que la colección se lee una sola vez
+func qualityPipeline(top int) mongo.Pipeline {
+	// Campos de Errores como arreglo [{k: campo, v: [errores]}]
+	errorFields := bson.M{"$objectToArray": bson.M{"$ifNull": bson.A{"$Errores", bson.M{}}}}
+	// Un cliente es inválido si algún campo tiene un error con severidad "error"
+	invalid := bson.M{"$anyElementTrue": bson.A{bson.M{"$map": bson.M{
+		"input": errorFields,
+		"as":    "campo",
+		"in":    bson.M{"$in": bson.A{models.SeverityError, bson.M{"$ifNull": bson.A{"$$campo.v.severity", bson.A{}}}}},
+	}}}}
+	email := bson.M{"$toLower": bson.M{"$trim": bson.M{"input": bson.M{"$ifNull": bson.A{"$Email", ""}}}}}
+	phone := bson.M{"$ifNull": bson.A{"$CelularE164", "$Celular"}}
+
+	topCounts := func(stages ...bson.D) bson.A {
+		pipeline := bson.A{}
+		for _, stage := range stages {
+			pipeline = append(pipeline, stage)
+		}
+		return append(pipeline,
+			bson.D{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+			bson.D{{Key: "$limit", Value: top}},
+		)
+	}
+	repeated := func(value interface{}) (bson.A, bson.A) {
+		group := bson.D{{Key: "$group", Value: bson.M{"_id": value, "count": bson.M{"$sum": 1}}}}
+		match := bson.D{{Key: "$match", Value: bson.M{"_id": bson.M{"$nin": bson.A{"", nil}}, "count": bson.M{"$gt": 1}}}}
+		totals := bson.A{group, match, bson.D{{Key: "$group", Value: bson.M{
+			"_id":      nil,
+			"values":   bson.M{"$sum": 1},
+			"clientes": bson.M{"$sum": "$count"},
+		}}}}
+		return topCounts(group, match), totals
+	}
+	duplicateEmails, duplicateEmailsTotal := repeated(email)
+	duplicatePhones, duplicatePhonesTotal := repeated(phone)
+
+	facets := bson.M{
+		"totals": bson.A{bson.D{{Key: "$group", Value: bson.M{
+			"_id":     nil,
+			"total":   bson.M{"$sum": 1},
+			"invalid": bson.M{"$sum": bson.M{"$cond": bson.A{invalid, 1, 0}}},
+		}}}},
+		// Un cliente cuenta una vez por código aunque lo repita en el campo;
+		// los mensajes históricos son texto y se agrupan por mensaje
+		"errors": bson.A{
+			bson.D{{Key: "$project", Value: bson.M{"errores": errorFields}}},
+			bson.D{{Key: "$unwind", Value: "$errores"}},
+			bson.D{{Key: "$unwind", Value: "$errores.v"}},
+			bson.D{{Key: "$group", Value: bson.M{
+				"_id": bson.M{
+					"field":   "$errores.k",
+					"code":    bson.M{"$ifNull": bson.A{"$errores.v.code", ""}},
+					"cliente": "$_id",
+				},
+				"message": bson.M{"$first": bson.M{"$ifNull": bson.A{"$errores.v.message", "$errores.v"}}},
+			}}},
+			bson.D{{Key: "$group", Value: bson.M{
+				"_id":      bson.M{"field": "$_id.field", "code": "$_id.code"},
+				"message":  bson.M{"$first": "$message"},
+				"clientes": bson.M{"$sum": 1},
+			}}},
+			bson.D{{Key: "$sort", Value: bson.D{{Key: "clientes", Value: -1}, {Key: "_id.field", Value: 1}, {Key: "_id.code", Value: 1}}}},
+			bson.D{{Key: "$limit", Value: top}},
+		},
+		"ladas": topCounts(
+			bson.D{{Key: "$match", Value: bson.M{"Region.lada": bson.M{"$type": "string"}}}},
+			bson.D{{Key: "$group", Value: bson.M{
+				"_id":   "$Region.lada",
+				"label": bson.M{"$first": "$Region.estado"},
+				"count": bson.M{"$sum": 1},
+			}}},
+		),
+		"email_domains": topCounts(
+			bson.D{{Key: "$match", Value: bson.M{"Email": bson.M{"$regex": "@"}}}},
+			bson.D{{Key: "$group", Value: bson.M{
+				"_id":   bson.M{"$arrayElemAt": bson.A{bson.M{"$split": bson.A{email, "@"}}, -1}},
+				"count": bson.M{"$sum": 1},
+			}}},
+		),
+		"duplicate_emails":       duplicateEmails,
+		"duplicate_emails_total": duplicateEmailsTotal,
+		"duplicate_phones":       duplicatePhones,
+		"duplicate_phones_total": duplicatePhonesTotal,
+		"name_lengths": bson.A{
+			bson.D{{Key: "$bucket", Value: bson.M{
+				"groupBy":    bson.M{"$strLenCP": bson.M{"$trim": bson.M{"input": bson.M{"$ifNull": bson.A{"$Nombre", ""}}}}},
+				"boundaries": toArray(nameLengthBoundaries),
+				"default":    fmt.Sprintf("%d+", nameLengthBoundaries[len(nameLengthBoundaries)-1]),
+				"output":     bson.M{"count": bson.M{"$sum": 1}},
+			}}},
+		},
+	}
+
+	return mongo.Pipeline{
+		{{Key: "$match", Value: activeFilter(nil)}},
+		{{Key: "$facet", Value: facets}},
+	}
+}
+
+func toArray(values []int) bson.A {
+	array := make(bson.A, len(values))
+	for i, value := range values {
+		array[i] = value
+	}
+	return array
+}
+
+// nameEmailSimilarity aplica el cálculo de la regla email.name_match, con su
+// umbral configurado, a una muestra aleatoria de clientes. $sample va
+// primero para que MongoDB elija los documentos sin recorrer la colección;
+// el filtro se aplica después, sobre la muestra.
+func nameEmailSimilarity(ctx context.Context, collection *mongo.Collection) (SimilarityStats, error) {
+	stats := SimilarityStats{SampleSize: SimilaritySampleSize, Threshold: utils.NameEmailMinSimilarity()}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$sample", Value: bson.M{"size": SimilaritySampleSize}}},
+		{{Key: "$match", Value: activeFilter(bson.M{
+			"Nombre": bson.M{"$type": "string", "$ne": ""},
+			"Email":  bson.M{"$regex": "@"},
+		})}},
+		{{Key: "$project", Value: bson.M{"_id": 0, "Nombre": 1, "Email": 1}}},
+	}
+	cursor, err := collection.Aggregate(ctx, pipeline, options.Aggregate().
+		SetAllowDiskUse(true).
+		SetBatchSize(1000).
+		SetMaxTime(queryMaxTime))
+	if err != nil {
+		return stats, fmt.Errorf("error leyendo nombres y emails: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	// Rangos de 0.1: "0.0-0.1", ..., "0.9-1.0"
+	histogram := make([]int64, 10)
+	for cursor.Next(ctx) {
+		var cliente struct {
+			Nombre string `bson:"Nombre"`
+			Email  string `bson:"Email"`
+		}
+		if err := cursor.Decode(&cliente); err != nil {
+			continue
+		}
+		local, ok := utils.EmailLocalPart(cliente.Email)
+		if !ok {
+			continue
+		}
+		similarity, compared, mismatch := utils.NameEmailSimilarity(cliente.Nombre, local, stats.Threshold)
+		if !compared {
+			continue
+		}
+		stats.Compared++
+		histogram[min(int(similarity*10), 9)]++
+		if mismatch {
+			stats.Mismatches++
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return stats, fmt.Errorf("error recorriendo nombres y emails: %w", err)
+	}
+
+	for i, count := range histogram {
+		stats.Histogram = append(stats.Histogram, Bucket{
+			Key:   fmt.Sprintf("%.1f-%.1f", float64(i)/10, float64(i+1)/10),
+			Count: count,
+		})
+	}
+	return stats, nil
+}
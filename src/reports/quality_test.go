@@ -0,0 +1,41 @@
+// reports/quality_test.go
+package reports
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestNameEmailSimilaritySample(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("sample", func(mt *mtest.T) {
+		cliente := func(nombre, email string) bson.D {
+			return bson.D{{Key: "Nombre", Value: nombre}, {Key: "Email", Value: email}}
+		}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, mt.DB.Name()+"."+mt.Coll.Name(), mtest.FirstBatch,
+			cliente("Pedro Pérez", "pedro.perez@example.com"),
+			cliente("Pedro Pérez", "ventas2024@example.com"),
+			// Nombre demasiado corto para compararlo
+			cliente("Ana", "ventas2024@example.com"),
+		))
+
+		stats, err := nameEmailSimilarity(context.Background(), mt.Coll)
+		if err != nil {
+			mt.Fatal(err)
+		}
+		if stats.SampleSize != SimilaritySampleSize || stats.Compared != 2 || stats.Mismatches != 1 {
+			mt.Errorf("stats = %+v, se esperaba muestra %d, 2 comparados y 1 sin coincidencia", stats, SimilaritySampleSize)
+		}
+
+		// La muestra va antes del filtro para no recorrer la colección
+		stages, _ := mt.GetStartedEvent().Command.Lookup("pipeline").Array().Values()
+		size, ok := stages[0].Document().Lookup("$sample", "size").AsInt64OK()
+		if !ok || size != SimilaritySampleSize {
+			mt.Errorf("la primera etapa debe ser $sample de %d: %v", SimilaritySampleSize, stages[0])
+		}
+	})
+}
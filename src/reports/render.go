@@ -0,0 +1,164 @@
+// reports/render.go
+package reports
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formatos de salida del reporte en la línea de comandos
+const (
+	FormatCSV      = "csv"
+	FormatMarkdown = "markdown"
+)
+
+// CheckFormat verifica que el formato de salida sea uno de los soportados
+func CheckFormat(format string) error {
+	switch format {
+	case FormatCSV, FormatMarkdown, "md":
+		return nil
+	}
+	return fmt.Errorf("formato %q no soportado (disponibles: %s, %s)", format, FormatCSV, FormatMarkdown)
+}
+
+// Write escribe el reporte en el formato indicado
+func Write(w io.Writer, report *QualityReport, format string) error {
+	if err := CheckFormat(format); err != nil {
+		return err
+	}
+	if format == FormatCSV {
+		return WriteCSV(w, report)
+	}
+	return WriteMarkdown(w, report)
+}
+
+// row es una fila del reporte en su forma plana: la sección, la llave
+// dentro de la sección, un detalle opcional y el conteo
+type row struct {
+	section, key, detail string
+	count                int64
+}
+
+// rows aplana el reporte en el orden en que se presenta
+func (r *QualityReport) rows() []row {
+	rows := []row{
+		{"resumen", "total", "", r.Total},
+		{"resumen", "validos", strconv.FormatFloat(r.ValidPercent, 'f', 2, 64) + "%", r.Valid},
+		{"resumen", "invalidos", "", r.Invalid},
+	}
+	for _, stat := range r.TopErrors {
+		rows = append(rows, row{"errores", stat.Field + "/" + stat.Code, stat.Message, stat.Clientes})
+	}
+	add := func(section string, buckets []Bucket) {
+		for _, bucket := range buckets {
+			rows = append(rows, row{section, bucket.Key, bucket.Label, bucket.Count})
+		}
+	}
+	add("ladas", r.Ladas)
+	add("dominios_email", r.EmailDomains)
+	rows = append(rows, row{"emails_duplicados", "valores", "", r.DuplicateEmails.Values},
+		row{"emails_duplicados", "clientes", "", r.DuplicateEmails.Clientes})
+	add("emails_duplicados", r.DuplicateEmails.Top)
+	rows = append(rows, row{"celulares_duplicados", "valores", "", r.DuplicatePhones.Values},
+		row{"celulares_duplicados", "clientes", "", r.DuplicatePhones.Clientes})
+	add("celulares_duplicados", r.DuplicatePhones.Top)
+	add("longitud_nombre", r.NameLengths)
+	rows = append(rows, row{"similitud_nombre_email", "muestra", "", int64(r.NameEmail.SampleSize)},
+		row{"similitud_nombre_email", "comparados", "", r.NameEmail.Compared},
+		row{"similitud_nombre_email", "no_coinciden", "umbral " + strconv.FormatFloat(r.NameEmail.Threshold, 'f', 2, 64), r.NameEmail.Mismatches})
+	add("similitud_nombre_email", r.NameEmail.Histogram)
+	return rows
+}
+
+// WriteCSV escribe el reporte como CSV con columnas seccion,llave,detalle,conteo
+func WriteCSV(w io.Writer, report *QualityReport) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"seccion", "llave", "detalle", "conteo"}); err != nil {
+		return err
+	}
+	for _, r := range report.rows() {
+		if err := writer.Write([]string{r.section, r.key, r.detail, strconv.FormatInt(r.count, 10)}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteMarkdown escribe el reporte como un documento Markdown con una tabla
+// por sección
+func WriteMarkdown(w io.Writer, report *QualityReport) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Reporte de calidad de clientes\n\nGenerado: %s\n", report.GeneratedAt.Format(time.RFC3339))
+
+	table := func(title string, headers []string, rows [][]string) {
+		fmt.Fprintf(&b, "\n## %s\n\n", title)
+		if len(rows) == 0 {
+			b.WriteString("Sin datos.\n")
+			return
+		}
+		b.WriteString("| " + strings.Join(headers, " | ") + " |\n")
+		b.WriteString("|" + strings.Repeat(" --- |", len(headers)) + "\n")
+		for _, cells := range rows {
+			for i, cell := range cells {
+				cells[i] = escapeMarkdown(cell)
+			}
+			b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		}
+	}
+	count := func(n int64) string { return strconv.FormatInt(n, 10) }
+	bucketRows := func(buckets []Bucket, withLabel bool) [][]string {
+		var rows [][]string
+		for _, bucket := range buckets {
+			cells := []string{bucket.Key}
+			if withLabel {
+				cells = append(cells, bucket.Label)
+			}
+			rows = append(rows, append(cells, count(bucket.Count)))
+		}
+		return rows
+	}
+
+	table("Resumen", []string{"Clientes", "Válidos", "Inválidos", "% válidos"}, [][]string{{
+		count(report.Total), count(report.Valid), count(report.Invalid),
+		strconv.FormatFloat(report.ValidPercent, 'f', 2, 64),
+	}})
+
+	var errorRows [][]string
+	for _, stat := range report.TopErrors {
+		errorRows = append(errorRows, []string{stat.Field, stat.Code, stat.Message, count(stat.Clientes)})
+	}
+	table("Errores más frecuentes", []string{"Campo", "Código", "Mensaje", "Clientes"}, errorRows)
+	table("Ladas", []string{"Lada", "Estado", "Clientes"}, bucketRows(report.Ladas, true))
+	table("Dominios de email", []string{"Dominio", "Clientes"}, bucketRows(report.EmailDomains, false))
+
+	for _, duplicated := range []struct {
+		title  string
+		header string
+		stats  Duplicates
+	}{
+		{"Emails duplicados", "Email", report.DuplicateEmails},
+		{"Celulares duplicados", "Celular", report.DuplicatePhones},
+	} {
+		table(duplicated.title, []string{duplicated.header, "Clientes"}, bucketRows(duplicated.stats.Top, false))
+		fmt.Fprintf(&b, "\n%d valores repetidos entre %d clientes.\n", duplicated.stats.Values, duplicated.stats.Clientes)
+	}
+
+	table("Longitud del nombre", []string{"Caracteres", "Clientes"}, bucketRows(report.NameLengths, false))
+	table("Similitud nombre/email", []string{"Similitud", "Clientes"}, bucketRows(report.NameEmail.Histogram, false))
+	fmt.Fprintf(&b, "\n%d de %d clientes comparados no coinciden (similitud menor a %.2f), en una muestra aleatoria de hasta %d clientes.\n",
+		report.NameEmail.Mismatches, report.NameEmail.Compared, report.NameEmail.Threshold, report.NameEmail.SampleSize)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// escapeMarkdown evita que un valor rompa la tabla
+func escapeMarkdown(value string) string {
+	value = strings.ReplaceAll(value, "|", `\|`)
+	return strings.ReplaceAll(value, "\n", " ")
+}
@@ -0,0 +1,29 @@
+package routes
+
+import (
+    "github.com/gin-gonic/gin"
+    "api_compiladores/src/auth"
+    "api_compiladores/src/controllers"
+    "api_compiladores/src/middleware"
+)
+
+// AdminRoute monta las rutas administrativas, que requieren rol admin
+func AdminRoute(router *gin.Engine, authenticator *auth.Authenticator) {
+    adminGroup := router.Group("/api/admin")
+    adminGroup.Use(middleware.Authenticate(authenticator), middleware.RequireRole(auth.RoleAdmin))
+    {
+        adminGroup.GET("/cache/stats", controllers.GetCacheStats)
+        adminGroup.DELETE("/cache", controllers.ClearCache)
+        adminGroup.GET("/health", controllers.HealthCheck)
+        adminGroup.GET("/health/ready", controllers.Readiness)
+        adminGroup.GET("/health/live", controllers.Liveness)
+        adminGroup.DELETE("/clientes/purge", controllers.PurgeClientes)
+
+        // Revalidación de toda la colección en segundo plano
+        adminGroup.POST("/revalidation", controllers.StartRevalidation)
+        adminGroup.GET("/revalidation", controllers.GetRevalidationJobs)
+        adminGroup.GET("/revalidation/:id", controllers.GetRevalidationJob)
+        adminGroup.POST("/revalidation/:id/resume", controllers.ResumeRevalidation)
+        adminGroup.POST("/revalidation/:id/cancel", controllers.CancelRevalidation)
+    }
+}
@@ -2,19 +2,36 @@ package routes
 
 import (
     "github.com/gin-gonic/gin"
+    "api_compiladores/src/auth"
     "api_compiladores/src/controllers"
+    "api_compiladores/src/middleware"
     "go.mongodb.org/mongo-driver/mongo"
 )
 
-func ClienteRoute(router *gin.Engine, collection *mongo.Collection) {
+// ClienteRoute monta el CRUD de clientes: las consultas (y la validación, que
+// no escribe) requieren rol reader y las escrituras rol editor
+func ClienteRoute(router *gin.Engine, collection *mongo.Collection, authenticator *auth.Authenticator) {
     controllers.SetClienteCollection(collection)
 
+    reader := middleware.RequireRole(auth.RoleReader)
+    editor := middleware.RequireRole(auth.RoleEditor)
+
     clienteGroup := router.Group("/api/clientes")
+    clienteGroup.Use(middleware.Authenticate(authenticator))
     {
-        clienteGroup.POST("/", controllers.CreateCliente)
-        clienteGroup.GET("/page/:page", controllers.GetClientes)
-        clienteGroup.GET("/:Clave_Cliente", controllers.GetCliente)
-        clienteGroup.PUT("/:Clave_Cliente", controllers.UpdateCliente)
-        clienteGroup.DELETE("/:Clave_Cliente", controllers.DeleteCliente)
+        clienteGroup.POST("/", editor, controllers.CreateCliente)
+        clienteGroup.POST("/validate", reader, controllers.ValidateClientes)
+        clienteGroup.GET("", reader, controllers.ListClientes)
+        clienteGroup.GET("/", reader, controllers.ListClientes)
+        clienteGroup.GET("/page/:page", reader, controllers.GetClientes)
+        clienteGroup.GET("/search", reader, controllers.SearchClientes)
+        clienteGroup.GET("/count", reader, controllers.GetClientesCount)
+        clienteGroup.GET("/errors", reader, controllers.GetErrorSummary)
+        clienteGroup.GET("/:Clave_Cliente", reader, controllers.GetCliente)
+        clienteGroup.GET("/:Clave_Cliente/history", reader, controllers.GetClienteHistory)
+        clienteGroup.PUT("/:Clave_Cliente", editor, controllers.UpdateCliente)
+        clienteGroup.PATCH("/:Clave_Cliente", editor, controllers.PatchCliente)
+        clienteGroup.DELETE("/:Clave_Cliente", editor, controllers.DeleteCliente)
+        clienteGroup.POST("/:Clave_Cliente/restore", editor, controllers.RestoreCliente)
     }
-}
\ No newline at end of file
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+    "github.com/gin-gonic/gin"
+    "api_compiladores/src/auth"
+    "api_compiladores/src/controllers"
+    "api_compiladores/src/middleware"
+)
+
+// ReportRoute monta los reportes sobre la colección de clientes, que
+// requieren rol reader
+func ReportRoute(router *gin.Engine, authenticator *auth.Authenticator) {
+    reportGroup := router.Group("/api/reports")
+    reportGroup.Use(middleware.Authenticate(authenticator), middleware.RequireRole(auth.RoleReader))
+    {
+        reportGroup.GET("/quality", controllers.GetQualityReport)
+    }
+}
@@ -0,0 +1,127 @@
+// routes/routes_test.go
+package routes
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"api_compiladores/src/auth"
+	"api_compiladores/src/middleware"
+)
+
+// Rol mínimo de cada ruta protegida
+var protectedRoutes = []struct {
+	method, path string
+	role         auth.Role
+}{
+	{http.MethodGet, "/api/clientes", auth.RoleReader},
+	{http.MethodGet, "/api/clientes/page/1", auth.RoleReader},
+	{http.MethodGet, "/api/clientes/search", auth.RoleReader},
+	{http.MethodGet, "/api/clientes/count", auth.RoleReader},
+	{http.MethodGet, "/api/clientes/errors", auth.RoleReader},
+	{http.MethodPost, "/api/clientes/validate", auth.RoleReader},
+	{http.MethodGet, "/api/clientes/101", auth.RoleReader},
+	{http.MethodGet, "/api/clientes/101/history", auth.RoleReader},
+	{http.MethodGet, "/api/reports/quality", auth.RoleReader},
+	{http.MethodPost, "/api/clientes/", auth.RoleEditor},
+	{http.MethodPut, "/api/clientes/101", auth.RoleEditor},
+	{http.MethodPatch, "/api/clientes/101", auth.RoleEditor},
+	{http.MethodDelete, "/api/clientes/101", auth.RoleEditor},
+	{http.MethodPost, "/api/clientes/101/restore", auth.RoleEditor},
+	{http.MethodGet, "/api/admin/cache/stats", auth.RoleAdmin},
+	{http.MethodDelete, "/api/admin/cache", auth.RoleAdmin},
+	{http.MethodGet, "/api/admin/health", auth.RoleAdmin},
+	{http.MethodGet, "/api/admin/health/ready", auth.RoleAdmin},
+	{http.MethodGet, "/api/admin/health/live", auth.RoleAdmin},
+	{http.MethodDelete, "/api/admin/clientes/purge", auth.RoleAdmin},
+	{http.MethodPost, "/api/admin/revalidation", auth.RoleAdmin},
+	{http.MethodGet, "/api/admin/revalidation", auth.RoleAdmin},
+	{http.MethodGet, "/api/admin/revalidation/665f1c2e8b3a4d0012345678", auth.RoleAdmin},
+	{http.MethodPost, "/api/admin/revalidation/665f1c2e8b3a4d0012345678/resume", auth.RoleAdmin},
+	{http.MethodPost, "/api/admin/revalidation/665f1c2e8b3a4d0012345678/cancel", auth.RoleAdmin},
+}
+
+// newTestRouter monta todas las rutas sin base de datos. Los handlers que
+// pasan la autorización fallan al usar las colecciones; Recovery convierte
+// ese pánico en 500, que basta para saber que la petición no fue rechazada.
+func newTestRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	keys, err := auth.ParseAPIKeys("lector:reader:llave-reader,capturista:editor:llave-editor,ops:admin:llave-admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticator := auth.NewAuthenticator(nil, keys)
+
+	r := gin.New()
+	r.Use(gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, _ interface{}) {
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}))
+	ClienteRoute(r, nil, authenticator)
+	AdminRoute(r, authenticator)
+	ReportRoute(r, authenticator)
+	return r
+}
+
+func serve(r *gin.Engine, method, path, key string) int {
+	req := httptest.NewRequest(method, path, nil)
+	if key != "" {
+		req.Header.Set(middleware.APIKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestRouteRoles(t *testing.T) {
+	r := newTestRouter(t)
+	keys := map[auth.Role]string{
+		auth.RoleReader: "llave-reader",
+		auth.RoleEditor: "llave-editor",
+		auth.RoleAdmin:  "llave-admin",
+	}
+
+	for _, route := range protectedRoutes {
+		t.Run(route.method+" "+route.path+" sin credenciales", func(t *testing.T) {
+			if status := serve(r, route.method, route.path, ""); status != http.StatusUnauthorized {
+				t.Errorf("status = %d, se esperaba %d", status, http.StatusUnauthorized)
+			}
+		})
+		for _, role := range []auth.Role{auth.RoleReader, auth.RoleEditor, auth.RoleAdmin} {
+			t.Run(route.method+" "+route.path+" "+role.String(), func(t *testing.T) {
+				status := serve(r, route.method, route.path, keys[role])
+				if role.Allows(route.role) {
+					if status == http.StatusUnauthorized || status == http.StatusForbidden {
+						t.Errorf("status = %d, el rol %s debería tener acceso", status, role)
+					}
+				} else if status != http.StatusForbidden {
+					t.Errorf("status = %d, se esperaba %d para el rol %s", status, http.StatusForbidden, role)
+				}
+			})
+		}
+	}
+}
+
+func TestQualityRefreshRequiresAdmin(t *testing.T) {
+	r := newTestRouter(t)
+	if status := serve(r, http.MethodGet, "/api/reports/quality?refresh=true", "llave-editor"); status != http.StatusForbidden {
+		t.Errorf("editor: status = %d, se esperaba %d", status, http.StatusForbidden)
+	}
+	if status := serve(r, http.MethodGet, "/api/reports/quality?refresh=true", "llave-admin"); status == http.StatusForbidden {
+		t.Errorf("admin: status = %d, debería poder recalcular", status)
+	}
+}
+
+func TestErrorSummaryRefreshRequiresAdmin(t *testing.T) {
+	r := newTestRouter(t)
+	if status := serve(r, http.MethodGet, "/api/clientes/errors?refresh=true", "llave-reader"); status != http.StatusForbidden {
+		t.Errorf("reader: status = %d, se esperaba %d", status, http.StatusForbidden)
+	}
+	if status := serve(r, http.MethodGet, "/api/clientes/errors?refresh=true", "llave-admin"); status == http.StatusForbidden {
+		t.Errorf("admin: status = %d, debería poder recalcular", status)
+	}
+}
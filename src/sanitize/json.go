@@ -0,0 +1,75 @@
+// sanitize/json.go
+package sanitize
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Campos que MongoDB usa en filtros y que deben llegar como valor simple
+var scalarFields = map[string]bool{"Clave_Cliente": true}
+
+// ScanJSON revisa un cuerpo JSON en busca de inyección de operadores de
+// MongoDB: claves que empiezan con "$" en cualquier nivel, claves con
+// caracteres de control y objetos o arreglos en los campos de scalarFields.
+// Un cuerpo que no es JSON válido no produce hallazgos; el error de formato
+// lo reporta el handler al decodificarlo.
+func ScanJSON(body []byte) []Finding {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil
+	}
+	var findings []Finding
+	walkJSON("", value, &findings)
+	return findings
+}
+
+func walkJSON(path string, value interface{}, findings *[]Finding) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		// Claves en orden para que los hallazgos sean reproducibles
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			item := v[key]
+			keyPath := joinPath(path, key)
+			if strings.HasPrefix(key, "$") {
+				*findings = append(*findings, Finding{Kind: KindOperator, Path: keyPath, Length: len([]rune(key)), Token: key})
+			}
+			for _, f := range ScanText(key) {
+				if f.Kind == KindControl {
+					f.Path = keyPath
+					*findings = append(*findings, f)
+				}
+			}
+			if scalarFields[key] {
+				switch item.(type) {
+				case map[string]interface{}, []interface{}:
+					*findings = append(*findings, Finding{Kind: KindOperator, Path: keyPath, Length: len([]rune(key)), Token: key})
+					continue
+				}
+			}
+			walkJSON(keyPath, item, findings)
+		}
+	case []interface{}:
+		for i, item := range v {
+			walkJSON(joinPath(path, "["+strconv.Itoa(i)+"]"), item, findings)
+		}
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" || strings.HasPrefix(key, "[") {
+		return path + key
+	}
+	return path + "." + key
+}
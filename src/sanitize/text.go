@@ -0,0 +1,163 @@
+// sanitize/text.go
+package sanitize
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Kind clasifica un hallazgo de entrada insegura
+type Kind int
+
+const (
+	KindMarkup    Kind = iota // etiqueta HTML o comentario (<b>, </script>, <!-- -->)
+	KindScriptURL             // esquema ejecutable (javascript:, vbscript:)
+	KindControl               // carácter de control o de formato invisible
+	KindOperator              // operador de MongoDB o estructura no permitida en JSON
+)
+
+var kindNames = map[Kind]string{
+	KindMarkup:    "MARKUP",
+	KindScriptURL: "SCRIPT_URL",
+	KindControl:   "CONTROL_CHAR",
+	KindOperator:  "NOSQL_OPERATOR",
+}
+
+func (k Kind) String() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+	return "UNKNOWN"
+}
+
+// MarshalText hace que el tipo se serialice por nombre en las respuestas JSON
+func (k Kind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// Finding ubica un fragmento inseguro; Offset y Length se expresan en runas.
+// En los hallazgos de JSON, Path indica la clave (p. ej. "Clave_Cliente.$gt").
+type Finding struct {
+	Kind   Kind   `json:"kind"`
+	Path   string `json:"path,omitempty"`
+	Offset int    `json:"offset"`
+	Length int    `json:"length"`
+	Token  string `json:"token"`
+}
+
+func (f Finding) Error() string {
+	if f.Path != "" {
+		return fmt.Sprintf("%s en %s (%q)", f.Kind, f.Path, f.Token)
+	}
+	return fmt.Sprintf("%s en la posición %d (%q)", f.Kind, f.Offset, f.Token)
+}
+
+// Esquemas de URL que ejecutan código en el navegador
+var scriptSchemes = map[string]bool{"javascript": true, "vbscript": true}
+
+// ScanText recorre el texto y reporta etiquetas HTML, esquemas ejecutables y
+// caracteres de control. A diferencia de buscar palabras clave, una palabra
+// como "select" o "Createson" no es un hallazgo: solo cuenta la estructura
+// (un "<" que abre una etiqueta, un esquema seguido de ":").
+func ScanText(input string) []Finding {
+	runes := []rune(input)
+	var findings []Finding
+	add := func(kind Kind, start, end int) {
+		findings = append(findings, Finding{Kind: kind, Offset: start, Length: end - start, Token: string(runes[start:end])})
+	}
+
+	for pos := 0; pos < len(runes); {
+		r := runes[pos]
+		switch {
+		case isUnsafeControl(r):
+			add(KindControl, pos, pos+1)
+			pos++
+
+		case r == '<' && opensTag(runes, pos):
+			end := tagEnd(runes, pos)
+			add(KindMarkup, pos, end)
+			pos = end
+
+		case unicode.IsLetter(r):
+			start := pos
+			for pos < len(runes) && unicode.IsLetter(runes[pos]) {
+				pos++
+			}
+			// Los navegadores ignoran espacios y controles entre el esquema y ":"
+			next := pos
+			for next < len(runes) && (unicode.IsSpace(runes[next]) || isUnsafeControl(runes[next])) {
+				next++
+			}
+			if next < len(runes) && runes[next] == ':' && scriptSchemes[strings.ToLower(string(runes[start:pos]))] {
+				add(KindScriptURL, start, next+1)
+				pos = next + 1
+			}
+
+		default:
+			pos++
+		}
+	}
+	return findings
+}
+
+// ScanParam revisa un parámetro de ruta o de query: además de ScanText, un
+// valor que empieza con "$" se trata como operador
+func ScanParam(value string) []Finding {
+	var findings []Finding
+	if strings.HasPrefix(value, "$") {
+		findings = append(findings, Finding{Kind: KindOperator, Length: 1, Token: "$"})
+	}
+	return append(findings, ScanText(value)...)
+}
+
+// opensTag indica si el "<" inicia una etiqueta: le sigue una letra, "/" y
+// una letra, "!" (comentario o doctype) o "?" (instrucción de procesamiento)
+func opensTag(runes []rune, pos int) bool {
+	if pos+1 >= len(runes) {
+		return false
+	}
+	next := runes[pos+1]
+	switch {
+	case unicode.IsLetter(next), next == '!', next == '?':
+		return true
+	case next == '/':
+		return pos+2 < len(runes) && unicode.IsLetter(runes[pos+2])
+	}
+	return false
+}
+
+// tagEnd devuelve la posición después del ">" que cierra la etiqueta,
+// respetando los valores de atributos entre comillas
+func tagEnd(runes []rune, pos int) int {
+	var quote rune
+	for i := pos + 1; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '>':
+			return i + 1
+		}
+	}
+	return len(runes)
+}
+
+// isUnsafeControl reconoce los caracteres de control (incluidos tabuladores y
+// saltos de línea, que no caben en un campo de una línea), los que invierten
+// la dirección del texto y los espacios de ancho cero que ocultan contenido
+func isUnsafeControl(r rune) bool {
+	switch {
+	case unicode.IsControl(r):
+		return true
+	case r >= '\u202a' && r <= '\u202e', r >= '\u2066' && r <= '\u2069':
+		return true
+	case r == '\u200b' || r == '\ufeff':
+		return true
+	}
+	return false
+}
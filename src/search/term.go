@@ -0,0 +1,209 @@
+// search/term.go
+package search
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Lenguaje de los términos de búsqueda:
+//
+//	=pedro      exacto (igualdad, usa el índice)
+//	pedro*      prefijo (rango [pedro, pedrp), usa el índice)
+//	*pedro*     contiene (regex sin anclar, recorre la colección)
+//	pe*ro       comodín (regex anclado ^pe.*ro$, usa el índice hasta el
+//	            primer comodín)
+//	pedro       el tipo por defecto del campo (exacto o prefijo)
+//
+// "\*" y "\=" buscan el carácter literal. El texto nunca se interpreta como
+// expresión regular: cada fragmento literal se escapa con QuoteMeta.
+//
+// Las condiciones distinguen mayúsculas. Los campos de texto se buscan en su
+// forma plegada (Fold) y el término se pliega igual antes de analizarlo, así
+// que ningún tipo distingue mayúsculas.
+
+// Límites de complejidad de un término
+const (
+	MaxTermLength = 100
+	MaxWildcards  = 4
+	// MinLiteral es el mínimo de caracteres literales para los términos que
+	// no usan el índice (contiene y comodín)
+	MinLiteral = 2
+)
+
+// Kind es el tipo de coincidencia de un término
+type Kind int
+
+const (
+	Contains Kind = iota
+	Exact
+	Prefix
+	Wildcard
+)
+
+var kindNames = map[Kind]string{
+	Contains: "contains",
+	Exact:    "exact",
+	Prefix:   "prefix",
+	Wildcard: "wildcard",
+}
+
+func (k Kind) String() string {
+	return kindNames[k]
+}
+
+// Error es un término mal formado; Offset es la posición en runas del problema
+type Error struct {
+	Term    string `json:"term"`
+	Offset  int    `json:"offset"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("término %q inválido en la posición %d: %s", e.Term, e.Offset, e.Message)
+}
+
+// Term es un término ya analizado; Parts son los fragmentos literales
+// separados por comodines
+type Term struct {
+	Kind  Kind
+	Parts []string
+}
+
+// Fold es la forma con la que se guardan y se buscan los campos de texto:
+// NFC, en minúsculas y sin espacios en los extremos
+func Fold(value string) string {
+	return strings.ToLower(norm.NFC.String(strings.TrimSpace(value)))
+}
+
+// Parse analiza el texto de búsqueda de un campo; def es el tipo que se usa
+// cuando el término no lleva "=" ni "*". Para que la búsqueda use el índice
+// del campo, def debe ser Exact o Prefix; contiene (*x*) se pide explícito.
+func Parse(input string, def Kind) (Term, error) {
+	fail := func(offset int, format string, args ...interface{}) (Term, error) {
+		return Term{}, &Error{Term: input, Offset: offset, Message: fmt.Sprintf(format, args...)}
+	}
+
+	runes := []rune(input)
+	if strings.TrimSpace(input) == "" {
+		return fail(0, "el término está vacío")
+	}
+	if len(runes) > MaxTermLength {
+		return fail(MaxTermLength, "el término no puede exceder %d caracteres", MaxTermLength)
+	}
+
+	exact := runes[0] == '='
+	start := 0
+	if exact {
+		start = 1
+	}
+
+	// Separar en fragmentos literales y comodines
+	var parts []string
+	var current strings.Builder
+	wildcards := 0
+	literal := 0
+	lastWildcard := false
+	for i := start; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\':
+			if i+1 >= len(runes) {
+				return fail(i, "la diagonal invertida debe escapar un carácter")
+			}
+			i++
+			current.WriteRune(runes[i])
+			literal++
+			lastWildcard = false
+		case r == '*':
+			if exact {
+				return fail(i, "un término exacto (=) no admite comodines")
+			}
+			if lastWildcard {
+				return fail(i, "no se permiten comodines consecutivos")
+			}
+			wildcards++
+			if wildcards > MaxWildcards {
+				return fail(i, "el término no puede tener más de %d comodines", MaxWildcards)
+			}
+			parts = append(parts, current.String())
+			current.Reset()
+			lastWildcard = true
+		default:
+			current.WriteRune(r)
+			literal++
+			lastWildcard = false
+		}
+	}
+	parts = append(parts, current.String())
+
+	if literal == 0 {
+		return fail(start, "el término debe tener al menos un carácter además de los comodines")
+	}
+
+	term := Term{Parts: parts}
+	switch {
+	case exact:
+		term.Kind = Exact
+	case wildcards == 0:
+		term.Kind = def
+	case wildcards == 1 && parts[1] == "" && parts[0] != "":
+		term.Kind = Prefix
+		term.Parts = parts[:1]
+	case wildcards == 2 && parts[0] == "" && parts[2] == "":
+		term.Kind = Contains
+		term.Parts = parts[1:2]
+	default:
+		term.Kind = Wildcard
+	}
+
+	if (term.Kind == Contains || term.Kind == Wildcard) && literal < MinLiteral {
+		return fail(start, "la búsqueda por contenido requiere al menos %d caracteres", MinLiteral)
+	}
+	return term, nil
+}
+
+// Filter devuelve la condición de MongoDB para el campo
+func (t Term) Filter() interface{} {
+	switch t.Kind {
+	case Exact:
+		return t.Parts[0]
+	case Prefix:
+		// Rango de cadenas que empiezan con el prefijo; el índice lo resuelve sin regex
+		prefix := t.Parts[0]
+		if upper, ok := prefixUpperBound(prefix); ok {
+			return bson.M{"$gte": prefix, "$lt": upper}
+		}
+		return bson.M{"$gte": prefix}
+	case Wildcard:
+		quoted := make([]string, len(t.Parts))
+		for i, part := range t.Parts {
+			quoted[i] = regexp.QuoteMeta(part)
+		}
+		return primitive.Regex{Pattern: "^" + strings.Join(quoted, ".*") + "$"}
+	}
+	return primitive.Regex{Pattern: regexp.QuoteMeta(strings.Join(t.Parts, ""))}
+}
+
+// prefixUpperBound incrementa la última runa del prefijo; falla si todas son
+// la runa máxima
+func prefixUpperBound(prefix string) (string, bool) {
+	runes := []rune(prefix)
+	for i := len(runes) - 1; i >= 0; i-- {
+		if runes[i] < utf8.MaxRune {
+			runes[i]++
+			// Las sustitutas de UTF-16 no son runas válidas
+			if runes[i] == 0xD800 {
+				runes[i] = 0xE000
+			}
+			return string(runes[:i+1]), true
+		}
+	}
+	return "", false
+}
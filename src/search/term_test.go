@@ -0,0 +1,142 @@
+// search/term_test.go
+package search
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		def       Kind
+		wantKind  Kind
+		wantParts []string
+		// wantOffset >= 0 indica que se espera un error en esa posición
+		wantOffset int
+	}{
+		{"por defecto prefijo", "pedro", Prefix, Prefix, []string{"pedro"}, -1},
+		{"por defecto exacto", "5512345678", Exact, Exact, []string{"5512345678"}, -1},
+		{"exacto explícito", "=pedro", Prefix, Exact, []string{"pedro"}, -1},
+		{"prefijo explícito", "ped*", Exact, Prefix, []string{"ped"}, -1},
+		{"prefijo de una letra", "p*", Exact, Prefix, []string{"p"}, -1},
+		{"contiene explícito", "*dro*", Prefix, Contains, []string{"dro"}, -1},
+		{"comodín", "pe*ro", Prefix, Wildcard, []string{"pe", "ro"}, -1},
+		{"comodín inicial", "*dro", Prefix, Wildcard, []string{"", "dro"}, -1},
+		{"asterisco escapado", `a\*b`, Prefix, Prefix, []string{"a*b"}, -1},
+		{"igual escapado", `\=pedro`, Exact, Exact, []string{"=pedro"}, -1},
+		{"diagonal escapada", `a\\b*`, Exact, Prefix, []string{`a\b`}, -1},
+		{"cuatro comodines", "a*b*c*d*e", Prefix, Wildcard, []string{"a", "b", "c", "d", "e"}, -1},
+
+		{"vacío", "   ", Prefix, 0, nil, 0},
+		{"diagonal al final", `pedro\`, Prefix, 0, nil, 5},
+		{"exacto con comodín", "=pe*", Prefix, 0, nil, 3},
+		{"comodines consecutivos", "pe**ro", Prefix, 0, nil, 3},
+		{"cinco comodines", "a*b*c*d*e*f", Prefix, 0, nil, 9},
+		{"solo comodines", "*", Prefix, 0, nil, 0},
+		{"contiene corto", "*a*", Prefix, 0, nil, 0},
+		{"comodín corto", "*a", Prefix, 0, nil, 0},
+		{"demasiado largo", strings.Repeat("a", MaxTermLength+1), Prefix, 0, nil, MaxTermLength},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			term, err := Parse(tt.input, tt.def)
+			if tt.wantOffset >= 0 {
+				var termErr *Error
+				if !errors.As(err, &termErr) {
+					t.Fatalf("error = %v, se esperaba *Error", err)
+				}
+				if termErr.Offset != tt.wantOffset {
+					t.Errorf("offset = %d, se esperaba %d (%s)", termErr.Offset, tt.wantOffset, termErr.Message)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if term.Kind != tt.wantKind || !reflect.DeepEqual(term.Parts, tt.wantParts) {
+				t.Errorf("Parse(%q) = %s %q, se esperaba %s %q", tt.input, term.Kind, term.Parts, tt.wantKind, tt.wantParts)
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  interface{}
+	}{
+		{"exacto", "=pedro", "pedro"},
+		{"prefijo", "ped*", bson.M{"$gte": "ped", "$lt": "pee"}},
+		{"contiene escapa el texto", "*a.b*", primitive.Regex{Pattern: `a\.b`}},
+		{"comodín anclado", "p(e*ro", primitive.Regex{Pattern: `^p\(e.*ro$`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			term, err := Parse(tt.input, Prefix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := term.Filter(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Filter() = %#v, se esperaba %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrefixUpperBound(t *testing.T) {
+	max := string(utf8.MaxRune)
+	tests := []struct {
+		name   string
+		prefix string
+		want   string
+		wantOk bool
+	}{
+		{"ascii", "abc", "abd", true},
+		{"acento", "josé", "josê", true},
+		{"antes de las sustitutas", "a\uD7FF", "a\uE000", true},
+		{"última runa máxima", "a" + max, "b", true},
+		{"todas máximas", max + max, "", false},
+		{"vacío", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := prefixUpperBound(tt.prefix)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("prefixUpperBound(%q) = %q, %v; se esperaba %q, %v", tt.prefix, got, ok, tt.want, tt.wantOk)
+			}
+			if ok && !utf8.ValidString(got) {
+				t.Errorf("límite %q no es UTF-8 válido", got)
+			}
+		})
+	}
+}
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"  Pedro PÉREZ ", "pedro pérez"},
+		// "e" + acento combinado se compone en NFC
+		{"Jose\u0301", "jos\u00e9"},
+		{"Ana@Gmail.COM", "ana@gmail.com"},
+	}
+
+	for _, tt := range tests {
+		if got := Fold(tt.input); got != tt.want {
+			t.Errorf("Fold(%q) = %q, se esperaba %q", tt.input, got, tt.want)
+		}
+	}
+}
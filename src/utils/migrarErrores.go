@@ -0,0 +1,101 @@
+// utils/migrarErrores.go
+package utils
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"api_compiladores/src/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MigrateErrores reescribe los documentos cuyo campo Errores aún tiene la forma
+// histórica (map[string][]string) a errores estructurados con código, severidad
+// y parámetros. Es idempotente: los documentos migrados ya tienen Mensajes.
+func MigrateErrores(collection *mongo.Collection) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Hour)
+	defer cancel()
+
+	filter := bson.M{
+		"Errores":  bson.M{"$type": "object"},
+		"Mensajes": bson.M{"$exists": false},
+	}
+	findOptions := options.Find().
+		SetProjection(bson.M{"Errores": 1}).
+		SetBatchSize(1000)
+
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return 0, fmt.Errorf("error buscando documentos a migrar: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var migrados int64
+	var writes []mongo.WriteModel
+	flush := func() error {
+		if len(writes) == 0 {
+			return nil
+		}
+		result, err := collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+		if err != nil {
+			return fmt.Errorf("error escribiendo lote de migración: %w", err)
+		}
+		migrados += result.ModifiedCount
+		writes = writes[:0]
+		fmt.Printf("Migrados %d documentos...\n", migrados)
+		return nil
+	}
+
+	for cursor.Next(ctx) {
+		var cliente models.Cliente
+		if err := cursor.Decode(&cliente); err != nil {
+			log.Printf("Error decodificando documento %v: %v", cursor.Current.Lookup("_id"), err)
+			continue
+		}
+
+		errores := migrateLegacyErrores(cliente.Errores)
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": cliente.ID}).
+			SetUpdate(bson.M{"$set": bson.M{
+				"Errores":  errores,
+				"Mensajes": errores.Messages(),
+			}}))
+
+		// Escribe en lotes de 1000
+		if len(writes) == 1000 {
+			if err := flush(); err != nil {
+				return migrados, err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return migrados, fmt.Errorf("error en cursor de migración: %w", err)
+	}
+	if err := flush(); err != nil {
+		return migrados, err
+	}
+
+	return migrados, nil
+}
+
+// migrateLegacyErrores asigna código y parámetros a los mensajes históricos;
+// los que no se reconocen conservan LegacyCode
+func migrateLegacyErrores(errores models.Errores) models.Errores {
+	out := make(models.Errores, len(errores))
+	for field, lista := range errores {
+		for _, ve := range lista {
+			if ve.Code == models.LegacyCode {
+				if code, params, ok := CodeFromMessage(ve.Message); ok {
+					ve.Code = code
+					ve.Params = params
+				}
+			}
+			out[field] = append(out[field], ve)
+		}
+	}
+	return out
+}
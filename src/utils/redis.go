@@ -22,12 +22,16 @@ var (
 // Constantes para configuración de caché
 const (
     DefaultTTL           = 5 * time.Minute
-    LongTTL             = 30 * time.Minute
-    ShortTTL            = 1 * time.Minute
-    MaxRetries          = 3
-    ClientesCachePrefix = "clientes:"
-    SingleClientePrefix = "cliente:"
-    StatsPrefix         = "stats:"
+    LongTTL              = 30 * time.Minute
+    ShortTTL             = 1 * time.Minute
+    MaxRetries           = 3
+    ClientesCachePrefix  = "clientes:"
+    SingleClientePrefix  = "cliente:"
+    ClientesCursorPrefix = ClientesCachePrefix + "cursor:"
+    // Set con las llaves de los listados cacheados, para invalidarlos sin
+    // recorrer el keyspace
+    ClientesListKeys = ClientesCachePrefix + "list_keys"
+    StatsPrefix          = "stats:"
 )
 
 // Configuración de Redis
@@ -116,13 +120,19 @@ func getDurationEnvWithDefault(key string, defaultValue time.Duration) time.Dura
 
 // === OPERACIONES DE CACHÉ PARA CLIENTES ===
 
+// ClientesPageKey arma la llave de una página numerada; options es la forma
+// canónica del tamaño, orden y campos del listado
+func ClientesPageKey(page int, options string) string {
+    return fmt.Sprintf("%spage_%d:%s", ClientesCachePrefix, page, options)
+}
+
 // Obtener lista de clientes desde caché
-func GetCachedClientesList(page int) ([]models.Cliente, bool, error) {
+func GetCachedClientesList(page int, options string) ([]models.Cliente, bool, error) {
     if RedisClient == nil {
         return nil, false, fmt.Errorf("Redis no disponible")
     }
 
-    key := fmt.Sprintf("%spage_%d", ClientesCachePrefix, page)
+    key := ClientesPageKey(page, options)
     
     cachedData, err := RedisClient.Get(Ctx, key).Result()
     if err == redis.Nil {
@@ -143,6 +153,66 @@ func GetCachedClientesList(page int) ([]models.Cliente, bool, error) {
     return clientes, true, nil
 }
 
+// CursorPage es una página de clientes por cursor junto con los cursores de
+// las páginas vecinas (vacíos si no hay más)
+type CursorPage struct {
+    Clientes []models.Cliente `json:"clientes"`
+    Next     string           `json:"next,omitempty"`
+    Prev     string           `json:"prev,omitempty"`
+}
+
+// CursorCacheKey arma la llave de una página por cursor: la dirección
+// (after o before), el cursor recibido (vacío en la primera página) y la
+// forma canónica del tamaño, orden y campos del listado
+func CursorCacheKey(direction, cursor, options string) string {
+    return fmt.Sprintf("%s%s:%s:%s", ClientesCursorPrefix, direction, cursor, options)
+}
+
+// Guardar página por cursor en caché
+func CacheCursorPage(key string, page CursorPage, ttl time.Duration) error {
+    if RedisClient == nil {
+        return fmt.Errorf("Redis no disponible")
+    }
+
+    jsonData, err := json.Marshal(page)
+    if err != nil {
+        return fmt.Errorf("error serializando página: %w", err)
+    }
+    pipe := RedisClient.Pipeline()
+    pipe.Set(Ctx, key, jsonData, ttl)
+    trackListKeys(pipe, ttl, key)
+    if _, err := pipe.Exec(Ctx); err != nil {
+        return fmt.Errorf("error guardando página en caché: %w", err)
+    }
+
+    log.Printf("📦 Página por cursor cacheada con %d clientes", len(page.Clientes))
+    return nil
+}
+
+// Obtener página por cursor desde caché
+func GetCachedCursorPage(key string) (*CursorPage, bool, error) {
+    if RedisClient == nil {
+        return nil, false, fmt.Errorf("Redis no disponible")
+    }
+
+    cachedData, err := RedisClient.Get(Ctx, key).Result()
+    if err == redis.Nil {
+        return nil, false, nil // No encontrado en caché
+    }
+    if err != nil {
+        return nil, false, fmt.Errorf("error obteniendo de caché: %w", err)
+    }
+
+    var page CursorPage
+    if err := json.Unmarshal([]byte(cachedData), &page); err != nil {
+        RedisClient.Del(Ctx, key)
+        return nil, false, fmt.Errorf("error deserializando página: %w", err)
+    }
+
+    log.Printf("🎯 Página por cursor obtenida de caché con %d clientes", len(page.Clientes))
+    return &page, true, nil
+}
+
 // Guardar cliente individual en caché
 func CacheSingleCliente(claveCliente string, cliente models.Cliente, ttl time.Duration) error {
     if RedisClient == nil {
@@ -191,37 +261,123 @@ func GetCachedSingleCliente(claveCliente string) (*models.Cliente, bool, error)
     return &cliente, true, nil
 }
 
+// Guardar una estadística calculada (como un reporte) bajo el prefijo stats:
+func CacheStats(name string, value interface{}, ttl time.Duration) error {
+    if RedisClient == nil {
+        return fmt.Errorf("Redis no disponible")
+    }
+
+    jsonData, err := json.Marshal(value)
+    if err != nil {
+        return fmt.Errorf("error serializando estadística: %w", err)
+    }
+    if err := RedisClient.Set(Ctx, StatsPrefix+name, jsonData, ttl).Err(); err != nil {
+        return fmt.Errorf("error guardando estadística en caché: %w", err)
+    }
+
+    log.Printf("📦 Estadística %s cacheada", name)
+    return nil
+}
+
+// Obtener una estadística calculada desde caché
+func GetCachedStats(name string, dest interface{}) (bool, error) {
+    if RedisClient == nil {
+        return false, fmt.Errorf("Redis no disponible")
+    }
+
+    key := StatsPrefix + name
+    cachedData, err := RedisClient.Get(Ctx, key).Result()
+    if err == redis.Nil {
+        return false, nil // No encontrado en caché
+    }
+    if err != nil {
+        return false, fmt.Errorf("error obteniendo de caché: %w", err)
+    }
+
+    if err := json.Unmarshal([]byte(cachedData), dest); err != nil {
+        RedisClient.Del(Ctx, key)
+        return false, fmt.Errorf("error deserializando estadística: %w", err)
+    }
+
+    log.Printf("🎯 Estadística %s obtenida de caché", name)
+    return true, nil
+}
+
 // === INVALIDACIÓN DE CACHÉ ===
 
 // Invalidar caché de un cliente específico
 func InvalidateClienteCache(claveCliente string) error {
+    return InvalidateClientesCache(claveCliente)
+}
+
+// trackListKeys registra llaves de listados en ClientesListKeys. El set vive
+// al menos tanto como la entrada más larga, para que ninguna llave cacheada
+// quede fuera de la invalidación.
+func trackListKeys(pipe redis.Pipeliner, ttl time.Duration, keys ...string) {
+    members := make([]interface{}, len(keys))
+    for i, key := range keys {
+        members[i] = key
+    }
+    pipe.SAdd(Ctx, ClientesListKeys, members...)
+    if ttl < LongTTL {
+        ttl = LongTTL
+    }
+    pipe.Expire(Ctx, ClientesListKeys, ttl)
+}
+
+// Invalidar caché de varios clientes y de los listados. Las llaves de los
+// listados se leen de ClientesListKeys; solo se quitan del set las que se
+// leyeron, por si otra petición cachea una página mientras tanto.
+func InvalidateClientesCache(claveClientes ...string) error {
     if RedisClient == nil {
         return nil
     }
 
+    listKeys, err := RedisClient.SMembers(Ctx, ClientesListKeys).Result()
+    if err != nil {
+        return fmt.Errorf("error obteniendo llaves de listados: %w", err)
+    }
+
     // Usar pipeline para eliminar múltiples keys relacionadas
     pipe := RedisClient.Pipeline()
-    
-    // Eliminar cliente individual
-    clienteKey := fmt.Sprintf("%s%s", SingleClientePrefix, claveCliente)
-    pipe.Del(Ctx, clienteKey)
-    
-    // Eliminar todas las páginas de la lista de clientes
-    // Esto podría optimizarse usando un set para trackear páginas activas
-    for i := 1; i <= 100; i++ { // Asumiendo máximo 100 páginas
-        pageKey := fmt.Sprintf("%spage_%d", ClientesCachePrefix, i)
-        pipe.Del(Ctx, pageKey, pageKey+"_meta")
+
+    // Eliminar clientes individuales
+    for _, claveCliente := range claveClientes {
+        pipe.Unlink(Ctx, fmt.Sprintf("%s%s", SingleClientePrefix, claveCliente))
     }
 
-    _, err := pipe.Exec(Ctx)
-    if err != nil {
+    // Eliminar todas las páginas de los listados, numeradas y por cursor
+    if len(listKeys) > 0 {
+        members := make([]interface{}, len(listKeys))
+        for i, key := range listKeys {
+            members[i] = key
+        }
+        pipe.Unlink(Ctx, listKeys...)
+        pipe.SRem(Ctx, ClientesListKeys, members...)
+    }
+
+    if _, err := pipe.Exec(Ctx); err != nil {
         return fmt.Errorf("error invalidando caché: %w", err)
     }
 
-    log.Printf("🗑️ Caché invalidado para cliente %s", claveCliente)
+    if len(claveClientes) == 1 {
+        log.Printf("🗑️ Caché invalidado para cliente %s", claveClientes[0])
+    } else if len(claveClientes) > 1 {
+        log.Printf("🗑️ Caché invalidado para %d clientes", len(claveClientes))
+    }
     return nil
 }
 
+// scanKeys recorre el keyspace con SCAN, que no bloquea Redis como KEYS
+func scanKeys(pattern string) ([]string, error) {
+    var keys []string
+    iter := RedisClient.Scan(Ctx, 0, pattern, 1000).Iterator()
+    for iter.Next(Ctx) {
+        keys = append(keys, iter.Val())
+    }
+    return keys, iter.Err()
+}
+
 // Invalidar todo el caché de clientes
 func InvalidateAllClientesCache() error {
     if RedisClient == nil {
@@ -235,14 +391,14 @@ func InvalidateAllClientesCache() error {
     }
 
     for _, pattern := range patterns {
-        keys, err := RedisClient.Keys(Ctx, pattern).Result()
+        keys, err := scanKeys(pattern)
         if err != nil {
             log.Printf("Error obteniendo keys con patrón %s: %v", pattern, err)
             continue
         }
 
         if len(keys) > 0 {
-            _, err = RedisClient.Del(Ctx, keys...).Result()
+            _, err = RedisClient.Unlink(Ctx, keys...).Result()
             if err != nil {
                 log.Printf("Error eliminando keys: %v", err)
             } else {
@@ -336,7 +492,7 @@ func CleanExpiredCache() error {
     }
 
     for _, pattern := range patterns {
-        keys, err := RedisClient.Keys(Ctx, pattern).Result()
+        keys, err := scanKeys(pattern)
         if err != nil {
             continue
         }
@@ -379,12 +535,12 @@ func (h *LoggingHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmd
     return nil
 }
 // Guardar lista de clientes en caché con compresión
-func CacheClientesList(page int, clientes []models.Cliente, ttl time.Duration) error {
+func CacheClientesList(page int, options string, clientes []models.Cliente, ttl time.Duration) error {
     if RedisClient == nil {
         return fmt.Errorf("Redis no disponible")
     }
 
-    key := fmt.Sprintf("%spage_%d", ClientesCachePrefix, page)
+    key := ClientesPageKey(page, options)
     
     // Serializar a JSON
     jsonData, err := json.Marshal(clientes)
@@ -408,6 +564,7 @@ func CacheClientesList(page int, clientes []models.Cliente, ttl time.Duration) e
     pipe := RedisClient.Pipeline()
     pipe.Set(Ctx, key, jsonData, ttl)
     pipe.Set(Ctx, key+"_meta", metaJsonData, ttl)
+    trackListKeys(pipe, ttl, key, key+"_meta")
 
     _, err = pipe.Exec(Ctx)
     if err != nil {
@@ -419,12 +576,12 @@ func CacheClientesList(page int, clientes []models.Cliente, ttl time.Duration) e
 }
 
 // También necesitas actualizar la función que lee los metadatos si los usas
-func GetCachedClientesListWithMeta(page int) ([]models.Cliente, map[string]interface{}, bool, error) {
+func GetCachedClientesListWithMeta(page int, options string) ([]models.Cliente, map[string]interface{}, bool, error) {
     if RedisClient == nil {
         return nil, nil, false, fmt.Errorf("Redis no disponible")
     }
 
-    key := fmt.Sprintf("%spage_%d", ClientesCachePrefix, page)
+    key := ClientesPageKey(page, options)
     metaKey := key + "_meta"
     
     // Usar pipeline para obtener ambos valores
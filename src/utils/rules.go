@@ -0,0 +1,602 @@
+// utils/rules.go
+package utils
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"api_compiladores/src/lexer"
+	"api_compiladores/src/models"
+	"api_compiladores/src/parser"
+	"api_compiladores/src/personname"
+	"api_compiladores/src/phone"
+)
+
+// Rule es una regla de validación que se aplica a un campo del cliente
+type Rule interface {
+	// Name identifica la regla de forma única, p. ej. "email.disposable"
+	Name() string
+	// Field es el campo del cliente al que se aplica (Nombre, Celular o Email)
+	Field() string
+	// Validate revisa el campo y registra sus errores en el contexto
+	Validate(fc *FieldContext, params RuleParams)
+}
+
+// RuleFunc adapta una función a la interfaz Rule
+type RuleFunc struct {
+	name  string
+	field string
+	fn    func(fc *FieldContext, params RuleParams)
+}
+
+func NewRule(name, field string, fn func(fc *FieldContext, params RuleParams)) Rule {
+	return &RuleFunc{name: name, field: field, fn: fn}
+}
+
+func (r *RuleFunc) Name() string  { return r.name }
+func (r *RuleFunc) Field() string { return r.field }
+
+func (r *RuleFunc) Validate(fc *FieldContext, params RuleParams) {
+	r.fn(fc, params)
+}
+
+// RuleConfig controla cómo se ejecuta una regla registrada
+type RuleConfig struct {
+	Enabled  bool       `json:"enabled"`
+	Order    int        `json:"order"`
+	Severity string     `json:"severity"`
+	Params   RuleParams `json:"params,omitempty"`
+}
+
+// RuleParams son los parámetros de una regla, tal como llegan de código o de un archivo
+type RuleParams map[string]interface{}
+
+func (p RuleParams) Int(key string, def int) int {
+	switch v := p[key].(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	case string:
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	}
+	return def
+}
+
+func (p RuleParams) Float(key string, def float64) float64 {
+	switch v := p[key].(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case string:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return def
+}
+
+func (p RuleParams) String(key, def string) string {
+	if v, ok := p[key].(string); ok {
+		return v
+	}
+	return def
+}
+
+func (p RuleParams) Strings(key string, def []string) []string {
+	switch v := p[key].(type) {
+	case []string:
+		return v
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			out = append(out, fmt.Sprint(item))
+		}
+		return out
+	}
+	return def
+}
+
+// Caché de expresiones regulares compiladas a partir de parámetros
+var regexpCache sync.Map
+
+// Regexp compila (una sola vez) el patrón del parámetro; si es inválido usa def
+func (p RuleParams) Regexp(key, def string) *regexp.Regexp {
+	pattern := p.String(key, def)
+	if cached, ok := regexpCache.Load(pattern); ok {
+		return cached.(*regexp.Regexp)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		log.Printf("Patrón inválido en parámetro %s: %v", key, err)
+		re = regexp.MustCompile(def)
+	}
+	regexpCache.Store(pattern, re)
+	return re
+}
+
+// Copy devuelve una copia superficial de los parámetros
+func (p RuleParams) Copy() RuleParams {
+	out := make(RuleParams, len(p))
+	for k, v := range p {
+		out[k] = v
+	}
+	return out
+}
+
+// === CONTEXTO DE VALIDACIÓN DE UN CAMPO ===
+
+// FieldContext contiene el campo ya normalizado y tokenizado, y acumula sus errores.
+// Canonical es la forma canónica del valor (el nombre en NFC, los dígitos
+// nacionales del celular); en los campos sin forma canónica es igual a Value.
+// Parsed guarda el resultado del analizador propio del campo, si lo tiene
+// (personname.Name para Nombre, phone.Number para Celular y el árbol de
+// parser.ParseEmail para Email).
+type FieldContext struct {
+	Field     string
+	Raw       string
+	Value     string
+	Src       []rune
+	Base      int
+	Tokens    []lexer.Token
+	Canonical string
+	Parsed    interface{}
+	Cliente   *models.Cliente
+
+	// positions ubica cada runa de Canonical en Src; nil si son iguales
+	positions []int
+	errs      models.Errores
+	messages  map[string]string
+	severity  string
+	halted    bool
+}
+
+// fieldSpec describe cómo extraer y normalizar cada campo validado
+type fieldSpec struct {
+	name      string
+	value     func(*models.Cliente) string
+	normalize func(string) string
+	parse     func(string) (canonical string, positions []int, parsed interface{})
+	mode      lexer.Mode
+	// dependsOn son los campos que leen sus reglas (validación cruzada); si
+	// cambia alguno, este campo también se revalida
+	dependsOn []string
+}
+
+// Campos validados, en el orden en que se ejecutan sus reglas
+var fieldSpecs = []fieldSpec{
+	{name: "Nombre", value: func(c *models.Cliente) string { return c.Nombre }, parse: parseNombre, mode: lexer.ModeNombre},
+	{name: "Celular", value: func(c *models.Cliente) string { return c.Celular }, parse: parseCelular, mode: lexer.ModeCelular},
+	{name: "Email", value: func(c *models.Cliente) string { return c.Email }, normalize: strings.ToLower, parse: parseEmail, mode: lexer.ModeEmail,
+		dependsOn: []string{"Nombre", "Celular"}},
+}
+
+// parseNombre normaliza el nombre a NFC y lo separa en nombres y apellidos
+func parseNombre(value string) (string, []int, interface{}) {
+	normalized, positions := personname.Normalize(value)
+	return normalized, positions, personname.Parse(normalized)
+}
+
+func parseCelular(value string) (string, []int, interface{}) {
+	number := phone.Parse(value, phone.DefaultRegion())
+	return number.National, number.Positions, number
+}
+
+// emailParsed guarda el árbol del email y sus errores de sintaxis
+type emailParsed struct {
+	addr *parser.Address
+	errs []*parser.SyntaxError
+}
+
+func parseEmail(value string) (string, []int, interface{}) {
+	addr, errs := parser.ParseEmail(value)
+	return value, nil, emailParsed{addr: addr, errs: errs}
+}
+
+// ValidatedFields devuelve los campos validados, que son las llaves de Errores
+func ValidatedFields() []string {
+	fields := make([]string, len(fieldSpecs))
+	for i, spec := range fieldSpecs {
+		fields[i] = spec.name
+	}
+	return fields
+}
+
+func isKnownField(field string) bool {
+	_, ok := fieldSpecFor(field)
+	return ok
+}
+
+// fieldSpecFor devuelve la descripción del campo validado con ese nombre
+func fieldSpecFor(field string) (fieldSpec, bool) {
+	for _, spec := range fieldSpecs {
+		if spec.name == field {
+			return spec, true
+		}
+	}
+	return fieldSpec{}, false
+}
+
+// normalizeValue aplica la normalización del campo y quita los espacios de
+// los extremos; base es cuántas runas se quitaron al inicio
+func (spec fieldSpec) normalizeValue(raw string) (value string, base int) {
+	normalized := raw
+	if spec.normalize != nil {
+		normalized = spec.normalize(raw)
+	}
+	return leadingTrim(normalized)
+}
+
+func newFieldContext(spec fieldSpec, cliente *models.Cliente, errs models.Errores, messages map[string]string) *FieldContext {
+	raw := spec.value(cliente)
+	value, base := spec.normalizeValue(raw)
+
+	canonical, positions, parsed := value, []int(nil), interface{}(nil)
+	if spec.parse != nil {
+		canonical, positions, parsed = spec.parse(value)
+	}
+
+	return &FieldContext{
+		Field:     spec.name,
+		Raw:       raw,
+		Value:     value,
+		Src:       []rune(value),
+		Base:      base,
+		Tokens:    lexer.Tokenize(value, spec.mode),
+		Canonical: canonical,
+		Parsed:    parsed,
+		Cliente:   cliente,
+		positions: positions,
+		errs:      errs,
+		messages:  messages,
+	}
+}
+
+// Len devuelve la longitud del valor normalizado en runas
+func (fc *FieldContext) Len() int {
+	return len(fc.Src)
+}
+
+// Add registra un error que abarca Src[offset:offset+length]; kv son pares
+// clave/valor con los parámetros del mensaje
+func (fc *FieldContext) Add(code string, offset, length int, kv ...string) {
+	if offset > len(fc.Src) {
+		offset = len(fc.Src)
+	}
+	if offset+length > len(fc.Src) {
+		length = len(fc.Src) - offset
+	}
+	params := buildParams(kv)
+	fc.errs[fc.Field] = append(fc.errs[fc.Field], models.ValidationError{
+		Code:     code,
+		Severity: fc.severity,
+		Message:  renderMessage(code, params, fc.messages),
+		Params:   params,
+		Offset:   fc.Base + offset,
+		Length:   length,
+		Token:    string(fc.Src[offset : offset+length]),
+	})
+}
+
+// AddToken registra un error sobre un token completo
+func (fc *FieldContext) AddToken(code string, tok lexer.Token, kv ...string) {
+	params := buildParams(kv)
+	fc.errs[fc.Field] = append(fc.errs[fc.Field], models.ValidationError{
+		Code:      code,
+		Severity:  fc.severity,
+		Message:   renderMessage(code, params, fc.messages),
+		Params:    params,
+		Offset:    fc.Base + tok.Offset,
+		Length:    tok.Length,
+		Token:     tok.Value,
+		TokenType: tok.Type.String(),
+	})
+}
+
+// AddCanonical registra un error sobre Canonical[start:start+length] y lo
+// traduce al tramo correspondiente del valor original
+func (fc *FieldContext) AddCanonical(code string, start, length int, kv ...string) {
+	if fc.positions == nil {
+		fc.Add(code, start, length, kv...)
+		return
+	}
+	if len(fc.positions) == 0 {
+		fc.Add(code, 0, fc.Len(), kv...)
+		return
+	}
+	if start > len(fc.positions) {
+		start = len(fc.positions)
+	}
+	if start+length > len(fc.positions) {
+		length = len(fc.positions) - start
+	}
+	if length <= 0 {
+		offset := fc.Len()
+		if start < len(fc.positions) {
+			offset = fc.positions[start]
+		}
+		fc.Add(code, offset, 0, kv...)
+		return
+	}
+	first, last := fc.positions[start], fc.positions[start+length-1]
+	fc.Add(code, first, last-first+1, kv...)
+}
+
+// AddCanonicalToken es como AddCanonical pero conserva el tipo del token
+func (fc *FieldContext) AddCanonicalToken(code string, start, length int, tokenType string, kv ...string) {
+	fc.AddCanonical(code, start, length, kv...)
+	lista := fc.errs[fc.Field]
+	lista[len(lista)-1].TokenType = tokenType
+}
+
+// Halt evita que se ejecuten las reglas restantes del campo
+func (fc *FieldContext) Halt() {
+	fc.halted = true
+}
+
+// HasErrors indica si ya se registró algún error en cualquier campo
+func (fc *FieldContext) HasErrors() bool {
+	return len(fc.errs) > 0
+}
+
+// === REGISTRO DE REGLAS ===
+
+type registeredRule struct {
+	rule   Rule
+	config RuleConfig
+}
+
+// RuleRegistry guarda las reglas por nombre y mantiene, por campo, la lista
+// ordenada de reglas habilitadas que usa ValidateCliente. También guarda las
+// plantillas de mensajes que sustituyen a las integradas.
+type RuleRegistry struct {
+	mu       sync.RWMutex
+	rules    map[string]*registeredRule
+	ordered  map[string][]registeredRule
+	messages map[string]string
+}
+
+func NewRuleRegistry() *RuleRegistry {
+	return &RuleRegistry{
+		rules:    make(map[string]*registeredRule),
+		ordered:  make(map[string][]registeredRule),
+		messages: make(map[string]string),
+	}
+}
+
+// NewDefaultRegistry crea un registro con las reglas integradas y su configuración por defecto
+func NewDefaultRegistry() *RuleRegistry {
+	r := NewRuleRegistry()
+	registerBuiltinRules(r)
+	return r
+}
+
+// DefaultRegistry contiene las reglas integradas y es el que usa ValidateCliente
+var DefaultRegistry = NewDefaultRegistry()
+
+// Register agrega una regla; falla si el nombre ya existe o el campo no se valida
+func (r *RuleRegistry) Register(rule Rule, config RuleConfig) error {
+	if !isKnownField(rule.Field()) {
+		return fmt.Errorf("la regla %s usa un campo desconocido: %s", rule.Name(), rule.Field())
+	}
+	if config.Severity == "" {
+		config.Severity = models.SeverityError
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.rules[rule.Name()]; exists {
+		return fmt.Errorf("la regla %s ya está registrada", rule.Name())
+	}
+	r.rules[rule.Name()] = &registeredRule{rule: rule, config: config}
+	r.rebuild()
+	return nil
+}
+
+// MustRegister es como Register pero termina el programa ante un error
+func (r *RuleRegistry) MustRegister(rule Rule, config RuleConfig) {
+	if err := r.Register(rule, config); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Configure cambia la configuración de una regla existente
+func (r *RuleRegistry) Configure(name string, update func(config *RuleConfig)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	registered, ok := r.rules[name]
+	if !ok {
+		return fmt.Errorf("la regla %s no existe", name)
+	}
+	update(&registered.config)
+	r.rebuild()
+	return nil
+}
+
+func (r *RuleRegistry) Enable(name string) error {
+	return r.Configure(name, func(config *RuleConfig) { config.Enabled = true })
+}
+
+func (r *RuleRegistry) Disable(name string) error {
+	return r.Configure(name, func(config *RuleConfig) { config.Enabled = false })
+}
+
+func (r *RuleRegistry) SetOrder(name string, order int) error {
+	return r.Configure(name, func(config *RuleConfig) { config.Order = order })
+}
+
+// SetParams combina los parámetros recibidos con los actuales de la regla
+func (r *RuleRegistry) SetParams(name string, params RuleParams) error {
+	return r.Configure(name, func(config *RuleConfig) {
+		merged := config.Params.Copy()
+		for k, v := range params {
+			merged[k] = v
+		}
+		config.Params = merged
+	})
+}
+
+// SetMessage sustituye la plantilla del mensaje de un código
+func (r *RuleRegistry) SetMessage(code, template string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	messages := make(map[string]string, len(r.messages)+1)
+	for k, v := range r.messages {
+		messages[k] = v
+	}
+	messages[code] = template
+	r.messages = messages
+}
+
+// Replace copia de forma atómica las reglas y mensajes de otro registro;
+// se usa al recargar el archivo de reglas sin detener el servidor
+func (r *RuleRegistry) Replace(other *RuleRegistry) {
+	other.mu.RLock()
+	rules := make(map[string]*registeredRule, len(other.rules))
+	for name, registered := range other.rules {
+		copied := *registered
+		rules[name] = &copied
+	}
+	messages := other.messages
+	other.mu.RUnlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rules = rules
+	r.messages = messages
+	r.rebuild()
+}
+
+// Config devuelve la configuración actual de una regla
+func (r *RuleRegistry) Config(name string) (RuleConfig, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	registered, ok := r.rules[name]
+	if !ok {
+		return RuleConfig{}, false
+	}
+	return registered.config, true
+}
+
+// RuleInfo describe una regla registrada para listados administrativos
+type RuleInfo struct {
+	Name  string `json:"name"`
+	Field string `json:"field"`
+	RuleConfig
+}
+
+// List devuelve todas las reglas ordenadas por campo y orden de ejecución
+func (r *RuleRegistry) List() []RuleInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	infos := make([]RuleInfo, 0, len(r.rules))
+	for name, registered := range r.rules {
+		infos = append(infos, RuleInfo{Name: name, Field: registered.rule.Field(), RuleConfig: registered.config})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Field != infos[j].Field {
+			return infos[i].Field < infos[j].Field
+		}
+		if infos[i].Order != infos[j].Order {
+			return infos[i].Order < infos[j].Order
+		}
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
+
+// rebuild recalcula las listas ordenadas por campo; requiere r.mu tomado
+func (r *RuleRegistry) rebuild() {
+	ordered := make(map[string][]registeredRule)
+	for _, registered := range r.rules {
+		if registered.config.Enabled {
+			ordered[registered.rule.Field()] = append(ordered[registered.rule.Field()], *registered)
+		}
+	}
+	for field := range ordered {
+		lista := ordered[field]
+		sort.Slice(lista, func(i, j int) bool {
+			if lista[i].config.Order != lista[j].config.Order {
+				return lista[i].config.Order < lista[j].config.Order
+			}
+			return lista[i].rule.Name() < lista[j].rule.Name()
+		})
+	}
+	r.ordered = ordered
+}
+
+// Run ejecuta las reglas habilitadas sobre el cliente y devuelve sus errores
+func (r *RuleRegistry) Run(cliente *models.Cliente) models.Errores {
+	errs := make(models.Errores)
+	r.run(cliente, errs, nil)
+	return errs
+}
+
+// RunFields revalida solo los campos indicados y los que dependen de ellos.
+// Los errores de los demás campos se copian de previous, de modo que las
+// reglas cruzadas los ven igual que en una validación completa. Devuelve los
+// errores combinados y los campos revalidados, en orden de ejecución.
+func (r *RuleRegistry) RunFields(cliente *models.Cliente, previous models.Errores, fields ...string) (models.Errores, []string) {
+	affected := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		affected[field] = true
+	}
+	var revalidated []string
+	for _, spec := range fieldSpecs {
+		for _, dependency := range spec.dependsOn {
+			if affected[dependency] {
+				affected[spec.name] = true
+			}
+		}
+		if affected[spec.name] {
+			revalidated = append(revalidated, spec.name)
+		}
+	}
+
+	errs := make(models.Errores)
+	for field, lista := range previous {
+		if !affected[field] && len(lista) > 0 {
+			errs[field] = lista
+		}
+	}
+	r.run(cliente, errs, affected)
+	return errs, revalidated
+}
+
+// run ejecuta las reglas de los campos de only (todos si es nil) sobre errs
+func (r *RuleRegistry) run(cliente *models.Cliente, errs models.Errores, only map[string]bool) {
+	r.mu.RLock()
+	ordered, messages := r.ordered, r.messages
+	r.mu.RUnlock()
+
+	for _, spec := range fieldSpecs {
+		if only != nil && !only[spec.name] {
+			continue
+		}
+		fc := newFieldContext(spec, cliente, errs, messages)
+		for _, registered := range ordered[spec.name] {
+			if fc.halted {
+				break
+			}
+			fc.severity = registered.config.Severity
+			registered.rule.Validate(fc, registered.config.Params)
+		}
+	}
+}
@@ -0,0 +1,687 @@
+// utils/rules_builtin.go
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"api_compiladores/src/domains"
+	"api_compiladores/src/lexer"
+	"api_compiladores/src/models"
+	"api_compiladores/src/parser"
+	"api_compiladores/src/personname"
+	"api_compiladores/src/phone"
+	"api_compiladores/src/sanitize"
+)
+
+// Dominios de la política de dominios permitidos cuando se habilita sin lista propia
+var defaultAllowedDomains = []string{
+	"gmail.com", "hotmail.com", "yahoo.com", "outlook.com", "live.com", "icloud.com",
+	"protonmail.com", "aol.com", "msn.com", "gmx.com", "ymail.com", "me.com", "mail.com",
+	"zoho.com", "edu.mx", "edu.com", "edu.org", "institucional.edu.mx", "unach.mx", "unicach.mx",
+}
+
+// Dominios institucionales que exigen una parte local mínima
+var defaultInstitutionalDomains = []string{"edu.mx", "institucional.edu.mx", "unach.mx", "unicach.mx"}
+
+// builtinRule agrupa una regla integrada con su configuración por defecto
+type builtinRule struct {
+	rule   Rule
+	config RuleConfig
+}
+
+// Reglas integradas; el orden de la lista define el orden por defecto dentro de cada campo
+var builtinRules = []builtinRule{
+	// === Nombre ===
+	{NewRule("nombre.required", "Nombre", ruleRequired(CodeNombreRequired)), RuleConfig{Enabled: true}},
+	{NewRule("nombre.length", "Nombre", ruleLength(CodeNombreTooShort, CodeNombreTooLong)),
+		RuleConfig{Enabled: true, Params: RuleParams{"min": 2, "max": 100}}},
+	{NewRule("nombre.letters", "Nombre", ruleNombreLetters), RuleConfig{Enabled: true, Params: RuleParams{"scripts": []string{"Latin"}}}},
+	{NewRule("nombre.spaces", "Nombre", ruleNombreSpaces), RuleConfig{Enabled: true}},
+	{NewRule("nombre.special_chars", "Nombre", ruleNombreSpecialChars), RuleConfig{Enabled: true}},
+	{NewRule("nombre.connectors", "Nombre", ruleNombreConnectors), RuleConfig{Enabled: true}},
+	{NewRule("nombre.injection", "Nombre", ruleInjection(CodeNombreInjection)), RuleConfig{Enabled: true}},
+	{NewRule("nombre.has_letter", "Nombre", ruleNombreHasLetter), RuleConfig{Enabled: true}},
+	{NewRule("nombre.min_letters", "Nombre", ruleNombreMinLetters), RuleConfig{Enabled: true, Params: RuleParams{"min": 2}}},
+	{NewRule("nombre.pattern", "Nombre", rulePattern(CodeNombrePattern)), RuleConfig{Enabled: false}},
+	{NewRule("nombre.deny", "Nombre", ruleNombreDeny), RuleConfig{Enabled: false}},
+
+	// === Celular ===
+	{NewRule("celular.required", "Celular", ruleRequired(CodeCelularRequired)), RuleConfig{Enabled: true}},
+	{NewRule("celular.digits", "Celular", ruleCelularDigits), RuleConfig{Enabled: true}},
+	{NewRule("celular.country", "Celular", ruleCelularCountry), RuleConfig{Enabled: true}},
+	{NewRule("celular.length", "Celular", ruleCelularLength), RuleConfig{Enabled: true}},
+	{NewRule("celular.lada", "Celular", ruleCelularLada), RuleConfig{Enabled: true}},
+	{NewRule("celular.repeated", "Celular", ruleCelularRepeated),
+		RuleConfig{Enabled: true, Params: RuleParams{"pattern": invalidPhonePatterns.String()}}},
+	{NewRule("celular.leading_zero", "Celular", ruleCelularLeadingZero), RuleConfig{Enabled: true}},
+	{NewRule("celular.lada_format", "Celular", ruleCelularLadaFormat), RuleConfig{Enabled: true}},
+	{NewRule("celular.pattern", "Celular", rulePattern(CodeCelularPattern)), RuleConfig{Enabled: false}},
+	{NewRule("celular.deny", "Celular", ruleCelularDeny), RuleConfig{Enabled: false}},
+
+	// === Email ===
+	{NewRule("email.required", "Email", ruleRequired(CodeEmailRequired)), RuleConfig{Enabled: true}},
+	{NewRule("email.length", "Email", ruleLength(CodeEmailTooShort, CodeEmailTooLong)),
+		RuleConfig{Enabled: true, Params: RuleParams{"min": 5, "max": 254}}},
+	{NewRule("email.structure", "Email", ruleEmailStructure),
+		RuleConfig{Enabled: true, Params: RuleParams{"local_max": 64, "domain_max": 253}}},
+	// Política de dominios permitidos; se habilita desde el archivo de reglas (allow)
+	{NewRule("email.allowed_domains", "Email", ruleEmailAllowedDomains),
+		RuleConfig{Enabled: false, Params: RuleParams{"domains": defaultAllowedDomains}}},
+	// Sufijo público conocido según la base de dominios local (sin consultas de red)
+	{NewRule("email.domain_validity", "Email", ruleEmailDomainValidity), RuleConfig{Enabled: true}},
+	// Lista de desechables de la base de dominios; "domains" agrega dominios propios
+	{NewRule("email.disposable", "Email", ruleEmailDisposable), RuleConfig{Enabled: true}},
+	{NewRule("email.injection", "Email", ruleInjection(CodeEmailInjection)), RuleConfig{Enabled: true}},
+	{NewRule("email.bad_start", "Email", ruleEmailBadStart), RuleConfig{Enabled: true}},
+	{NewRule("email.domain_specific", "Email", ruleEmailDomainSpecific),
+		RuleConfig{Enabled: true, Params: RuleParams{"institutional_domains": defaultInstitutionalDomains, "institutional_min": 3}}},
+	{NewRule("email.pattern", "Email", rulePattern(CodeEmailPattern)), RuleConfig{Enabled: false}},
+	{NewRule("email.deny", "Email", ruleEmailDeny), RuleConfig{Enabled: false}},
+	// Advertencia cuando el email no parece corresponder al nombre; deshabilitada por defecto
+	{NewRule("email.name_match", "Email", ruleEmailNameMatch),
+		RuleConfig{Enabled: false, Severity: models.SeverityWarning, Params: RuleParams{"min_similarity": 0.3}}},
+}
+
+// registerBuiltinRules registra las reglas integradas con su orden por defecto
+func registerBuiltinRules(r *RuleRegistry) {
+	for i, builtin := range builtinRules {
+		config := builtin.config
+		config.Order = (i + 1) * 10
+		config.Params = config.Params.Copy()
+		r.MustRegister(builtin.rule, config)
+	}
+}
+
+// === REGLAS COMPARTIDAS ===
+
+// ruleRequired registra el error de campo obligatorio y detiene las demás reglas
+func ruleRequired(code string) func(*FieldContext, RuleParams) {
+	return func(fc *FieldContext, params RuleParams) {
+		if fc.Value == "" {
+			fc.Add(code, 0, 0)
+			fc.Halt()
+		}
+	}
+}
+
+// ruleLength verifica la longitud en runas; el exceso se señala a partir de max
+func ruleLength(codeShort, codeLong string) func(*FieldContext, RuleParams) {
+	return func(fc *FieldContext, params RuleParams) {
+		min, max := params.Int("min", 0), params.Int("max", 0)
+		if min > 0 && fc.Len() < min {
+			fc.Add(codeShort, 0, fc.Len(), "min", strconv.Itoa(min))
+		}
+		if max > 0 && fc.Len() > max {
+			fc.Add(codeLong, max, fc.Len()-max, "max", strconv.Itoa(max))
+		}
+	}
+}
+
+// ruleInjection señala las etiquetas HTML, los esquemas ejecutables y los
+// caracteres de control que encuentra sanitize.ScanText. Un "pattern" en los
+// parámetros agrega un regex propio cuyas coincidencias también se señalan.
+func ruleInjection(code string) func(*FieldContext, RuleParams) {
+	return func(fc *FieldContext, params RuleParams) {
+		for _, finding := range sanitize.ScanText(fc.Value) {
+			fc.Add(code, finding.Offset, finding.Length, "tipo", finding.Kind.String())
+		}
+		if params.String("pattern", "") == "" {
+			return
+		}
+		for _, loc := range params.Regexp("pattern", "$^").FindAllStringIndex(fc.Value, -1) {
+			offset, length := runeSpan(fc.Value, loc)
+			fc.Add(code, offset, length)
+		}
+	}
+}
+
+// rulePattern exige que la forma canónica del valor coincida con el parámetro
+// "pattern"; solo se habilita cuando el archivo de reglas declara un regex para el campo
+func rulePattern(code string) func(*FieldContext, RuleParams) {
+	return func(fc *FieldContext, params RuleParams) {
+		if params.String("pattern", "") == "" {
+			return
+		}
+		if !params.Regexp("pattern", ".*").MatchString(fc.Canonical) {
+			fc.Add(code, 0, fc.Len())
+		}
+	}
+}
+
+// === NOMBRE ===
+//
+// Las reglas de Nombre trabajan sobre los tokens de personname, que se
+// calculan sobre el nombre en NFC (fc.Canonical); los errores se traducen al
+// tramo correspondiente del texto original.
+
+func nombreName(fc *FieldContext) personname.Name {
+	name, _ := fc.Parsed.(personname.Name)
+	return name
+}
+
+func addNombreToken(fc *FieldContext, code string, tok personname.Token, kv ...string) {
+	fc.AddCanonicalToken(code, tok.Offset, tok.Length, tok.Type.String(), kv...)
+}
+
+// Señala cada letra fuera de los alfabetos "scripts" (por defecto el latino,
+// que incluye acentos, ñ, ç, ø, etc.) y de las letras sueltas de "extra_letters"
+func ruleNombreLetters(fc *FieldContext, params RuleParams) {
+	var scripts []*unicode.RangeTable
+	for _, script := range params.Strings("scripts", []string{"Latin"}) {
+		if table, ok := unicode.Scripts[script]; ok {
+			scripts = append(scripts, table)
+		}
+	}
+	extra := params.String("extra_letters", "")
+
+	for _, tok := range nombreName(fc).Tokens {
+		if tok.Type != personname.Word {
+			continue
+		}
+		for i, r := range []rune(tok.Value) {
+			// Las marcas diacríticas combinables pertenecen a la letra anterior
+			if !unicode.IsLetter(r) || unicode.In(r, scripts...) || strings.ContainsRune(extra, r) {
+				continue
+			}
+			fc.AddCanonical(CodeNombreInvalidChars, tok.Offset+i, 1)
+		}
+	}
+}
+
+func ruleNombreSpaces(fc *FieldContext, params RuleParams) {
+	for _, tok := range nombreName(fc).Tokens {
+		if tok.Type == personname.Space && tok.Length > 1 {
+			addNombreToken(fc, CodeNombreMultiSpaces, tok)
+		}
+	}
+}
+
+// Números y caracteres especiales; los guiones y apóstrofos se revisan en nombre.connectors
+func ruleNombreSpecialChars(fc *FieldContext, params RuleParams) {
+	for _, tok := range nombreName(fc).Tokens {
+		if tok.Type == personname.Digit || tok.Type == personname.Symbol {
+			addNombreToken(fc, CodeNombreSpecialChars, tok)
+		}
+	}
+}
+
+// Los guiones y apóstrofos solo se aceptan entre dos letras (María-José, O'Connor)
+func ruleNombreConnectors(fc *FieldContext, params RuleParams) {
+	for _, tok := range nombreName(fc).BadConnectors {
+		addNombreToken(fc, CodeNombreBadConnector, tok)
+	}
+}
+
+func ruleNombreHasLetter(fc *FieldContext, params RuleParams) {
+	for _, tok := range nombreName(fc).Tokens {
+		if tok.Type == personname.Word {
+			return
+		}
+	}
+	fc.Add(CodeNombreNoLetters, 0, fc.Len())
+}
+
+// Verificar nombres muy cortos tras quitar espacios
+func ruleNombreMinLetters(fc *FieldContext, params RuleParams) {
+	min := params.Int("min", 2)
+	sinEspacios := 0
+	for _, tok := range nombreName(fc).Tokens {
+		if tok.Type != personname.Space {
+			sinEspacios += tok.Length
+		}
+	}
+	if sinEspacios < min {
+		fc.Add(CodeNombreFewLetters, 0, fc.Len(), "min", strconv.Itoa(min))
+	}
+}
+
+// Señala las palabras del nombre que aparecen en la lista "words"; también se
+// comparan las palabras compuestas completas (María-José)
+func ruleNombreDeny(fc *FieldContext, params RuleParams) {
+	denied := params.Strings("words", nil)
+	isDenied := func(value string) bool {
+		for _, word := range denied {
+			if strings.EqualFold(value, word) {
+				return true
+			}
+		}
+		return false
+	}
+
+	name := nombreName(fc)
+	for _, part := range name.Parts {
+		if isDenied(part.Value) {
+			fc.AddCanonical(CodeNombreDeniedWord, part.Offset, part.Length, "word", part.Value)
+			continue
+		}
+		for _, tok := range name.Tokens {
+			if tok.Type == personname.Word && tok.Offset >= part.Offset && tok.End() <= part.Offset+part.Length && isDenied(tok.Value) {
+				addNombreToken(fc, CodeNombreDeniedWord, tok, "word", tok.Value)
+			}
+		}
+	}
+}
+
+// === CELULAR ===
+//
+// Las reglas de Celular trabajan sobre fc.Canonical (el número nacional, sin
+// separadores ni prefijos como +52 o 044) y señalan los errores sobre el texto
+// original. El país sale de la clave +CC o, si no la trae, del país por defecto.
+
+func celularNumber(fc *FieldContext) phone.Number {
+	number, _ := fc.Parsed.(phone.Number)
+	return number
+}
+
+// Las reglas de lada solo aplican a números de México
+func esNumeroMexicano(fc *FieldContext) bool {
+	number := celularNumber(fc)
+	return number.Country != nil && number.Country.Region == "MX"
+}
+
+// Revisa la clave de país y los primeros dígitos según el país; "countries"
+// limita los países aceptados (códigos ISO, p. ej. [MX, US])
+func ruleCelularCountry(fc *FieldContext, params RuleParams) {
+	number := celularNumber(fc)
+	if number.Country == nil {
+		if number.International {
+			size := len(fc.Canonical)
+			if size > 3 {
+				size = 3
+			}
+			fc.AddCanonical(CodeCelularUnknownCountry, 0, size)
+			fc.Halt()
+		}
+		return
+	}
+
+	if allowed := params.Strings("countries", nil); len(allowed) > 0 {
+		permitido := false
+		for _, region := range allowed {
+			if strings.EqualFold(region, number.Country.Region) {
+				permitido = true
+				break
+			}
+		}
+		if !permitido {
+			fc.AddCanonical(CodeCelularCountryDenied, 0, len(fc.Canonical), "pais", number.Country.Name)
+			fc.Halt()
+			return
+		}
+	}
+
+	if !number.Country.ValidPrefix(number.National) {
+		fc.AddCanonical(CodeCelularBadPrefix, 0, 3, "pais", number.Country.Name)
+	}
+}
+
+// La longitud se mide en dígitos nacionales; "length" fija una longitud para
+// todos los países, si no se usa la del país del número
+func ruleCelularLength(fc *FieldContext, params RuleParams) {
+	length := params.Int("length", 0)
+	if length <= 0 {
+		number := celularNumber(fc)
+		if number.Country == nil {
+			return
+		}
+		length = number.Country.Length
+	}
+
+	digits := len(fc.Canonical)
+	if digits < length {
+		fc.Add(CodeCelularTooShort, 0, fc.Len(), "length", strconv.Itoa(length))
+	} else if digits > length {
+		fc.AddCanonical(CodeCelularTooLong, length, digits-length, "length", strconv.Itoa(length))
+	}
+}
+
+// Se aceptan separadores comunes: espacios, guiones, puntos, paréntesis y un + inicial
+func ruleCelularDigits(fc *FieldContext, params RuleParams) {
+	for i, tok := range fc.Tokens {
+		switch {
+		case tok.Type == lexer.DigitRun, tok.Type == lexer.Space, tok.Type == lexer.Hyphen, tok.Type == lexer.Dot:
+		case tok.Type == lexer.Plus && i == 0:
+		case tok.Type == lexer.Symbol && (tok.Value == "(" || tok.Value == ")"):
+		default:
+			fc.AddToken(CodeCelularNotDigits, tok)
+		}
+	}
+}
+
+// La lada se busca en el plan nacional de numeración. Una lista de ladas
+// ("ladas", de 2 o 3 dígitos) o un patrón ("pattern") restringen las ladas
+// aceptadas; "states" limita las ladas del plan a ciertos estados.
+func ruleCelularLada(fc *FieldContext, params RuleParams) {
+	if !esNumeroMexicano(fc) {
+		return
+	}
+	number := fc.Canonical
+
+	if ladas := params.Strings("ladas", nil); ladas != nil {
+		for _, lada := range ladas {
+			if strings.HasPrefix(number, lada) {
+				return
+			}
+		}
+		fc.AddCanonical(CodeCelularBadLada, 0, 3)
+		return
+	}
+
+	if params.String("pattern", "") != "" {
+		if !params.Regexp("pattern", "").MatchString(number) {
+			fc.AddCanonical(CodeCelularBadLada, 0, 3)
+		}
+		return
+	}
+
+	lada, ok := phone.Lookup(number)
+	if !ok {
+		fc.AddCanonical(CodeCelularBadLada, 0, 3)
+		return
+	}
+	if states := params.Strings("states", nil); len(states) > 0 {
+		for _, state := range states {
+			if strings.EqualFold(state, lada.Estado) {
+				return
+			}
+		}
+		fc.AddCanonical(CodeCelularStateDenied, 0, len(lada.Codigo), "lada", lada.Codigo, "estado", lada.Estado)
+	}
+}
+
+func ruleCelularRepeated(fc *FieldContext, params RuleParams) {
+	if params.Regexp("pattern", invalidPhonePatterns.String()).MatchString(fc.Canonical) {
+		fc.AddCanonical(CodeCelularRepeated, 0, len(fc.Canonical))
+	}
+}
+
+func ruleCelularLeadingZero(fc *FieldContext, params RuleParams) {
+	if len(fc.Canonical) > 0 && fc.Canonical[0] == '0' {
+		fc.AddCanonical(CodeCelularLeadZero, 0, 1)
+	}
+}
+
+// Los números de la lista se comparan en formato E.164
+func ruleCelularDeny(fc *FieldContext, params RuleParams) {
+	e164 := celularNumber(fc).E164()
+	if e164 == "" {
+		return
+	}
+	for _, number := range params.Strings("numbers", nil) {
+		if e164 == phone.Parse(number, phone.DefaultRegion()).E164() {
+			fc.AddCanonical(CodeCelularDenied, 0, len(fc.Canonical))
+			return
+		}
+	}
+}
+
+// Verificar límites específicos por lada; la zona se toma del plan de numeración
+func ruleCelularLadaFormat(fc *FieldContext, params RuleParams) {
+	number := fc.Canonical
+	if !esNumeroMexicano(fc) || len(number) != phone.NationalLength {
+		return
+	}
+
+	lada := number[:3]
+	zona := lada
+	if info, ok := phone.Lookup(number); ok {
+		zona = info.Municipio
+	}
+
+	switch lada {
+	case "916", "917", "918", "919":
+		if number[3] == '0' || number[3] == '1' {
+			fc.AddCanonical(CodeCelularLadaFormat, 3, 1, "lada", lada, "zona", zona)
+		}
+	case "932", "934":
+		if number[3] == '0' {
+			fc.AddCanonical(CodeCelularLadaFormat, 3, 1, "lada", lada, "zona", zona)
+		}
+	}
+}
+
+// === EMAIL ===
+//
+// Las reglas de Email trabajan sobre el árbol que produce parser.ParseEmail;
+// la sintaxis se revisa en email.structure y las demás reglas son políticas
+// sobre las partes reconocidas.
+
+// Código de error para cada error de sintaxis del analizador
+var emailSyntaxCodes = map[parser.ErrorKind]string{
+	parser.ErrMissingAt:         CodeEmailAtCount,
+	parser.ErrExtraAt:           CodeEmailAtCount,
+	parser.ErrLocalEmpty:        CodeEmailLocalEmpty,
+	parser.ErrLocalDotEdge:      CodeEmailLocalDotEdge,
+	parser.ErrConsecutiveDots:   CodeEmailConsecutiveDots,
+	parser.ErrInvalidChar:       CodeEmailInvalidChars,
+	parser.ErrUnterminatedQuote: CodeEmailUnterminatedQuote,
+	parser.ErrDomainEmpty:       CodeEmailDomainEmpty,
+	parser.ErrDomainDotEdge:     CodeEmailDomainDotEdge,
+	parser.ErrLabelTooLong:      CodeEmailLabelTooLong,
+	parser.ErrLabelHyphen:       CodeEmailLabelHyphen,
+	parser.ErrInvalidIDN:        CodeEmailInvalidIDN,
+	parser.ErrDomainLiteral:     CodeEmailDomainLiteral,
+}
+
+func emailSyntax(fc *FieldContext) emailParsed {
+	parsed, _ := fc.Parsed.(emailParsed)
+	return parsed
+}
+
+// emailAddress devuelve el árbol del email cuando hay exactamente un @
+func emailAddress(fc *FieldContext) (*parser.Address, bool) {
+	parsed := emailSyntax(fc)
+	if parsed.addr == nil || parsed.addr.At < 0 {
+		return nil, false
+	}
+	for _, err := range parsed.errs {
+		if err.Kind == parser.ErrExtraAt {
+			return nil, false
+		}
+	}
+	return parsed.addr, true
+}
+
+// domainMatches indica si el dominio (en su forma Unicode o ASCII) es alguno
+// de la lista o un subdominio suyo
+func domainMatches(domain parser.Domain, list []string) (string, bool) {
+	names := []string{strings.ToLower(domain.Name), strings.ToLower(domain.ASCII)}
+	for _, entry := range list {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		for _, name := range names {
+			if name == entry || strings.HasSuffix(name, "."+entry) {
+				return entry, true
+			}
+		}
+	}
+	return "", false
+}
+
+func ruleEmailStructure(fc *FieldContext, params RuleParams) {
+	for _, err := range emailSyntax(fc).errs {
+		if err.Kind == parser.ErrLabelTooLong {
+			fc.Add(emailSyntaxCodes[err.Kind], err.Offset, err.Length, "max", strconv.Itoa(parser.MaxLabelLength))
+			continue
+		}
+		fc.Add(emailSyntaxCodes[err.Kind], err.Offset, err.Length)
+	}
+
+	addr, ok := emailAddress(fc)
+	if !ok {
+		return
+	}
+
+	// Los límites de RFC 5321 se miden en bytes; el exceso se señala a partir
+	// de la runa que corresponde al máximo cuando el texto es ASCII
+	localMax := params.Int("local_max", 64)
+	if addr.Local.Octets() > localMax {
+		offset, length := addr.Local.Offset, addr.Local.Length
+		if addr.Local.Length > localMax {
+			offset, length = offset+localMax, length-localMax
+		}
+		fc.Add(CodeEmailLocalTooLong, offset, length, "max", strconv.Itoa(localMax))
+	}
+
+	domainMax := params.Int("domain_max", 253)
+	if len(addr.Domain.ASCII) > domainMax {
+		offset, length := addr.Domain.Offset, addr.Domain.Length
+		if addr.Domain.Length > domainMax {
+			offset, length = offset+domainMax, length-domainMax
+		}
+		fc.Add(CodeEmailDomainTooLong, offset, length, "max", strconv.Itoa(domainMax))
+	}
+}
+
+// Política opcional de dominios permitidos (deshabilitada por defecto); acepta
+// también los subdominios de cada dominio de la lista
+func ruleEmailAllowedDomains(fc *FieldContext, params RuleParams) {
+	addr, ok := emailAddress(fc)
+	if !ok || addr.Domain.Length == 0 {
+		return
+	}
+	if _, allowed := domainMatches(addr.Domain, params.Strings("domains", defaultAllowedDomains)); !allowed {
+		fc.Add(CodeEmailDomainNotAllowed, addr.Domain.Offset, addr.Domain.Length)
+	}
+}
+
+// Señala el dominio si está en la lista "domains" (también sus subdominios)
+func ruleEmailDeny(fc *FieldContext, params RuleParams) {
+	addr, ok := emailAddress(fc)
+	if !ok || addr.Domain.Length == 0 {
+		return
+	}
+	if _, denied := domainMatches(addr.Domain, params.Strings("domains", nil)); denied {
+		fc.Add(CodeEmailDomainDenied, addr.Domain.Offset, addr.Domain.Length, "domain", addr.Domain.Name)
+	}
+}
+
+// Revisa que el dominio termine en un sufijo público conocido y que tenga un
+// nombre antes de él; los literales de IP no tienen sufijo y se omiten
+func ruleEmailDomainValidity(fc *FieldContext, params RuleParams) {
+	addr, ok := emailAddress(fc)
+	if !ok || addr.Domain.Literal || len(addr.Domain.Labels) == 0 {
+		return
+	}
+	domain := addr.Domain
+
+	suffixes := domains.Suffixes()
+	if _, known := suffixes.PublicSuffix(domain.ASCII); !known {
+		// Se señala la última etiqueta, que es el dominio de primer nivel
+		tld := domain.Labels[len(domain.Labels)-1]
+		fc.Add(CodeEmailUnknownSuffix, tld.Offset, tld.Length, "domain", domain.Name)
+		return
+	}
+	if _, registrable := suffixes.RegistrableDomain(domain.ASCII); !registrable {
+		fc.Add(CodeEmailNotRegistrable, domain.Offset, domain.Length, "domain", domain.Name)
+	}
+}
+
+// Verificar emails desechables contra la base de dominios (también sus subdominios)
+func ruleEmailDisposable(fc *FieldContext, params RuleParams) {
+	addr, ok := emailAddress(fc)
+	if !ok || addr.Domain.Literal || addr.Domain.Length == 0 {
+		return
+	}
+	_, disposable := domains.Disposable().Match(addr.Domain.ASCII)
+	if !disposable {
+		_, disposable = domainMatches(addr.Domain, params.Strings("domains", nil))
+	}
+	if disposable {
+		fc.Add(CodeEmailDisposable, addr.Domain.Offset, addr.Domain.Length)
+	}
+}
+
+// Verificar caracteres especiales no permitidos al inicio
+func ruleEmailBadStart(fc *FieldContext, params RuleParams) {
+	if fc.Len() > 0 && (fc.Src[0] == '.' || fc.Src[0] == '-' || fc.Src[0] == '_') {
+		fc.Add(CodeEmailBadStart, 0, 1)
+	}
+}
+
+// Validaciones específicas por dominio
+func ruleEmailDomainSpecific(fc *FieldContext, params RuleParams) {
+	addr, ok := emailAddress(fc)
+	if !ok {
+		return
+	}
+	domain := strings.ToLower(addr.Domain.ASCII)
+
+	if domain == "gmail.com" {
+		// Gmail no permite puntos al final de la parte local (solo posible entre comillas)
+		if strings.HasSuffix(addr.Local.Value, ".") {
+			fc.Add(CodeEmailGmailTrailingDot, addr.At-1, 1)
+		}
+		return
+	}
+
+	// Emails institucionales deben tener formato específico
+	min := params.Int("institutional_min", 3)
+	for _, institutional := range params.Strings("institutional_domains", defaultInstitutionalDomains) {
+		if domain == institutional && len([]rune(addr.Local.Value)) < min {
+			fc.Add(CodeEmailInstitutionShort, addr.Local.Offset, addr.Local.Length, "min", strconv.Itoa(min))
+		}
+	}
+}
+
+// Validación cruzada: solo se evalúa si los demás campos no tienen errores
+func ruleEmailNameMatch(fc *FieldContext, params RuleParams) {
+	if fc.HasErrors() {
+		return
+	}
+	addr, ok := emailAddress(fc)
+	if !ok {
+		return
+	}
+	_, _, mismatch := NameEmailSimilarity(fc.Cliente.Nombre, addr.Local.Value, params.Float("min_similarity", DefaultNameEmailSimilarity))
+	if mismatch {
+		fc.Add(CodeEmailNameMismatch, addr.Local.Offset, addr.Local.Length)
+	}
+}
+
+// DefaultNameEmailSimilarity es el umbral de email.name_match si la regla
+// no configura min_similarity
+const DefaultNameEmailSimilarity = 0.3
+
+// NameEmailMinSimilarity devuelve el umbral vigente de email.name_match
+func NameEmailMinSimilarity() float64 {
+	if config, ok := DefaultRegistry.Config("email.name_match"); ok {
+		return config.Params.Float("min_similarity", DefaultNameEmailSimilarity)
+	}
+	return DefaultNameEmailSimilarity
+}
+
+// NameEmailSimilarity es el criterio de email.name_match: compara el nombre
+// sin espacios con la parte local del email. compared es false si alguno de
+// los dos es demasiado corto para compararse y mismatch indica que la regla
+// marcaría el email (similitud menor al umbral y la parte local no contiene
+// el inicio del nombre).
+func NameEmailSimilarity(nombre, localPart string, minSimilarity float64) (similarity float64, compared, mismatch bool) {
+	nombreSinEspacios := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(nombre), " ", ""))
+	nombreRunes := []rune(nombreSinEspacios)
+	if len(nombreRunes) <= 3 || len([]rune(localPart)) <= 3 {
+		return 0, false, false
+	}
+	similarity = CalculateSimilarity(nombreSinEspacios, localPart)
+	mismatch = similarity < minSimilarity && !strings.Contains(localPart, string(nombreRunes[:3]))
+	return similarity, true, mismatch
+}
+
+// EmailLocalPart devuelve la parte local de un email con exactamente un @,
+// igual que la leen las reglas de Email: normalizada como ese campo (en
+// minúsculas y sin espacios en los extremos)
+func EmailLocalPart(email string) (string, bool) {
+	spec, _ := fieldSpecFor("Email")
+	value, _ := spec.normalizeValue(email)
+	addr, errs := parser.ParseEmail(value)
+	if addr == nil || addr.At < 0 {
+		return "", false
+	}
+	for _, err := range errs {
+		if err.Kind == parser.ErrExtraAt {
+			return "", false
+		}
+	}
+	return addr.Local.Value, true
+}
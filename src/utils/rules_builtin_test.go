@@ -0,0 +1,113 @@
+// utils/rules_builtin_test.go
+package utils
+
+import (
+	"testing"
+
+	"api_compiladores/src/models"
+)
+
+func TestNameEmailSimilarity(t *testing.T) {
+	tests := []struct {
+		name, nombre, local string
+		wantCompared        bool
+		wantMismatch        bool
+	}{
+		{"coincide", "Pedro Pérez", "pedroperez", true, false},
+		{"contiene el inicio del nombre", "Pedro Pérez", "xx.ped.2024", true, false},
+		{"no coincide", "Pedro Pérez", "ventas2024", true, true},
+		{"nombre corto", "Ana", "ventas2024", false, false},
+		{"parte local corta", "Pedro Pérez", "pp", false, false},
+		// El inicio del nombre se toma en caracteres, no en bytes
+		{"inicio multibyte", "Ñuño Álvarez", "zzñuñozz", true, false},
+		{"nombre de 3 letras acentuadas", "Íñé", "ventas2024", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, compared, mismatch := NameEmailSimilarity(tt.nombre, tt.local, DefaultNameEmailSimilarity)
+			if compared != tt.wantCompared || mismatch != tt.wantMismatch {
+				t.Errorf("compared=%v mismatch=%v, se esperaba compared=%v mismatch=%v",
+					compared, mismatch, tt.wantCompared, tt.wantMismatch)
+			}
+		})
+	}
+}
+
+func TestEmailLocalPart(t *testing.T) {
+	tests := []struct {
+		email, want string
+		ok          bool
+	}{
+		{"pedro.perez@example.com", "pedro.perez", true},
+		{"  pedro@example.com ", "pedro", true},
+		// En minúsculas, igual que el campo Email que revisa email.name_match
+		{"Pedro.Perez@Example.com", "pedro.perez", true},
+		{"sin-arroba", "", false},
+		{"a@b@example.com", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := EmailLocalPart(tt.email)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("EmailLocalPart(%q) = %q, %v; se esperaba %q, %v", tt.email, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// El reporte y la regla deben marcar los mismos emails sin importar las
+// mayúsculas
+func TestEmailLocalPartMatchesRule(t *testing.T) {
+	for _, email := range []string{"PEDRO.PEREZ@example.com", "Ventas2024@Example.com", "pEdRo@example.com"} {
+		local, ok := EmailLocalPart(email)
+		if !ok {
+			t.Fatalf("EmailLocalPart(%q) no devolvió la parte local", email)
+		}
+		_, _, reportMismatch := NameEmailSimilarity("Pedro Pérez", local, DefaultNameEmailSimilarity)
+
+		registry := NewDefaultRegistry()
+		if err := registry.Enable("email.name_match"); err != nil {
+			t.Fatal(err)
+		}
+		errores := registry.Run(&models.Cliente{Nombre: "Pedro Pérez", Celular: "9613214782", Email: email})
+		ruleMismatch := containsCode(errores["Email"], CodeEmailNameMismatch)
+
+		if reportMismatch != ruleMismatch {
+			t.Errorf("%s: reporte=%v regla=%v", email, reportMismatch, ruleMismatch)
+		}
+	}
+}
+
+func containsCode(errors []models.ValidationError, code string) bool {
+	for _, ve := range errors {
+		if ve.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCelularLadaList(t *testing.T) {
+	file := RulesFile{Fields: map[string]FieldRules{"Celular": {Ladas: []string{"961", "55"}}}}
+	if err := file.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	registry, err := file.BuildRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		celular string
+		wantBad bool
+	}{
+		{"9613214782", false},
+		{"5512345678", false},
+		{"9621234567", true},
+	}
+	for _, tt := range tests {
+		errores := registry.Run(&models.Cliente{Nombre: "Pedro Pérez", Celular: tt.celular, Email: "pedro@example.com"})
+		if got := containsCode(errores["Celular"], CodeCelularBadLada); got != tt.wantBad {
+			t.Errorf("%s: lada inválida = %v, se esperaba %v", tt.celular, got, tt.wantBad)
+		}
+	}
+}
@@ -0,0 +1,370 @@
+// utils/rules_config.go
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+
+	"api_compiladores/src/models"
+	"api_compiladores/src/phone"
+)
+
+// RulesFile es el archivo declarativo de reglas (YAML o JSON). Ejemplo:
+//
+//	fields:
+//	  Email:
+//	    allow: [gmail.com, empresa.com.mx]
+//	    deny: [mailinator.com]
+//	  Celular:
+//	    countries: [MX, GT]
+//	    states: [Chiapas, Tabasco]
+//	messages:
+//	  EMAIL_DOMAIN_NOT_ALLOWED: "Usa tu correo corporativo"
+type RulesFile struct {
+	Fields   map[string]FieldRules   `json:"fields" yaml:"fields"`
+	Rules    map[string]RuleOverride `json:"rules" yaml:"rules"`
+	Messages map[string]string       `json:"messages" yaml:"messages"`
+}
+
+// FieldRules declara las restricciones de un campo
+type FieldRules struct {
+	MinLength *int              `json:"min_length" yaml:"min_length"`
+	MaxLength *int              `json:"max_length" yaml:"max_length"`
+	Length    *int              `json:"length" yaml:"length"`
+	Regex     string            `json:"regex" yaml:"regex"`
+	Allow     []string          `json:"allow" yaml:"allow"`
+	Deny      []string          `json:"deny" yaml:"deny"`
+	Ladas     []string          `json:"ladas" yaml:"ladas"`
+	States    []string          `json:"states" yaml:"states"`
+	Countries []string          `json:"countries" yaml:"countries"`
+	Messages  map[string]string `json:"messages" yaml:"messages"`
+}
+
+// RuleOverride cambia la configuración de una regla registrada por nombre
+type RuleOverride struct {
+	Enabled  *bool      `json:"enabled" yaml:"enabled"`
+	Order    *int       `json:"order" yaml:"order"`
+	Severity string     `json:"severity" yaml:"severity"`
+	Params   RuleParams `json:"params" yaml:"params"`
+}
+
+var ladaPattern = regexp.MustCompile(`^[1-9][0-9]{1,2}$`)
+
+// LoadRulesFile lee el archivo según su extensión (.yaml, .yml o .json) y lo valida
+func LoadRulesFile(path string) (*RulesFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo archivo de reglas: %w", err)
+	}
+
+	var file RulesFile
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &file)
+	case ".json":
+		err = json.Unmarshal(data, &file)
+	default:
+		return nil, fmt.Errorf("extensión no soportada para archivo de reglas: %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error interpretando archivo de reglas %s: %w", path, err)
+	}
+
+	if err := file.Validate(); err != nil {
+		return nil, fmt.Errorf("archivo de reglas %s inválido: %w", path, err)
+	}
+	return &file, nil
+}
+
+// Validate revisa el archivo completo y reporta todos los problemas encontrados
+func (f *RulesFile) Validate() error {
+	var problems []string
+	addProblem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	defaults := NewDefaultRegistry()
+
+	for field, rules := range f.Fields {
+		if !isKnownField(field) {
+			addProblem("campo desconocido: %s", field)
+			continue
+		}
+		if rules.MinLength != nil && *rules.MinLength < 0 {
+			addProblem("%s.min_length no puede ser negativo", field)
+		}
+		if rules.MaxLength != nil && *rules.MaxLength < 1 {
+			addProblem("%s.max_length debe ser mayor que 0", field)
+		}
+		if rules.MinLength != nil && rules.MaxLength != nil && *rules.MinLength > *rules.MaxLength {
+			addProblem("%s.min_length no puede ser mayor que max_length", field)
+		}
+		if rules.Length != nil {
+			if field != "Celular" {
+				addProblem("%s.length solo se admite en Celular", field)
+			} else if *rules.Length < 1 {
+				addProblem("Celular.length debe ser mayor que 0")
+			}
+		}
+		if field == "Celular" && (rules.MinLength != nil || rules.MaxLength != nil) {
+			addProblem("Celular usa length en lugar de min_length/max_length")
+		}
+		if rules.Regex != "" {
+			if _, err := regexp.Compile(rules.Regex); err != nil {
+				addProblem("%s.regex inválido: %v", field, err)
+			}
+		}
+		if len(rules.Allow) > 0 && field != "Email" {
+			addProblem("%s.allow solo se admite en Email (dominios permitidos)", field)
+		}
+		if len(rules.Ladas) > 0 {
+			if field != "Celular" {
+				addProblem("%s.ladas solo se admite en Celular", field)
+			}
+			for _, lada := range rules.Ladas {
+				if !ladaPattern.MatchString(lada) {
+					addProblem("lada inválida en %s.ladas: %q (2 o 3 dígitos)", field, lada)
+				}
+			}
+		}
+		if len(rules.Ladas) > 0 && len(rules.States) > 0 {
+			// Ambas listas configuran la misma regla (celular.lada); una
+			// reemplazaría a la otra
+			addProblem("%s.ladas y %s.states son excluyentes; usa solo una", field, field)
+		}
+		if len(rules.States) > 0 {
+			if field != "Celular" {
+				addProblem("%s.states solo se admite en Celular", field)
+			}
+			for _, state := range rules.States {
+				if !isKnownState(state) {
+					addProblem("estado desconocido en %s.states: %q", field, state)
+				}
+			}
+		}
+		if len(rules.Countries) > 0 {
+			if field != "Celular" {
+				addProblem("%s.countries solo se admite en Celular", field)
+			}
+			for _, region := range rules.Countries {
+				if _, ok := phone.LookupCountry(region); !ok {
+					addProblem("país no soportado en %s.countries: %q", field, region)
+				}
+			}
+		}
+		for code, template := range rules.Messages {
+			if !isKnownCode(code) {
+				addProblem("%s.messages usa un código desconocido: %s", field, code)
+			}
+			if strings.TrimSpace(template) == "" {
+				addProblem("%s.messages.%s está vacío", field, code)
+			}
+		}
+	}
+
+	for name, override := range f.Rules {
+		if _, ok := defaults.Config(name); !ok {
+			addProblem("regla desconocida: %s", name)
+			continue
+		}
+		if override.Severity != "" && override.Severity != models.SeverityError && override.Severity != models.SeverityWarning {
+			addProblem("rules.%s.severity debe ser %q o %q", name, models.SeverityError, models.SeverityWarning)
+		}
+		for _, script := range override.Params.Strings("scripts", nil) {
+			if _, ok := unicode.Scripts[script]; !ok {
+				addProblem("rules.%s.params.scripts usa un alfabeto desconocido: %q", name, script)
+			}
+		}
+		if pattern, ok := override.Params["pattern"].(string); ok {
+			if _, err := regexp.Compile(pattern); err != nil {
+				addProblem("rules.%s.params.pattern inválido: %v", name, err)
+			}
+		}
+	}
+
+	for code, template := range f.Messages {
+		if !isKnownCode(code) {
+			addProblem("messages usa un código desconocido: %s", code)
+		}
+		if strings.TrimSpace(template) == "" {
+			addProblem("messages.%s está vacío", code)
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func isKnownCode(code string) bool {
+	_, ok := errorMessages[code]
+	return ok
+}
+
+// isKnownState revisa el estado contra el plan de numeración en uso
+func isKnownState(state string) bool {
+	for _, known := range phone.CurrentPlan().States() {
+		if strings.EqualFold(known, state) {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildRegistry crea un registro con las reglas integradas y le aplica el archivo
+func (f *RulesFile) BuildRegistry() (*RuleRegistry, error) {
+	r := NewDefaultRegistry()
+
+	for field, rules := range f.Fields {
+		prefix := strings.ToLower(field)
+
+		if rules.MinLength != nil || rules.MaxLength != nil {
+			params := RuleParams{}
+			if rules.MinLength != nil {
+				params["min"] = *rules.MinLength
+			}
+			if rules.MaxLength != nil {
+				params["max"] = *rules.MaxLength
+			}
+			if err := r.SetParams(prefix+".length", params); err != nil {
+				return nil, err
+			}
+		}
+		if rules.Length != nil {
+			if err := r.SetParams("celular.length", RuleParams{"length": *rules.Length}); err != nil {
+				return nil, err
+			}
+		}
+		if rules.Regex != "" {
+			if err := r.SetParams(prefix+".pattern", RuleParams{"pattern": rules.Regex}); err != nil {
+				return nil, err
+			}
+			if err := r.Enable(prefix + ".pattern"); err != nil {
+				return nil, err
+			}
+		}
+		if len(rules.Allow) > 0 {
+			if err := r.SetParams("email.allowed_domains", RuleParams{"domains": rules.Allow}); err != nil {
+				return nil, err
+			}
+			if err := r.Enable("email.allowed_domains"); err != nil {
+				return nil, err
+			}
+		}
+		if len(rules.Deny) > 0 {
+			key := map[string]string{"Nombre": "words", "Celular": "numbers", "Email": "domains"}[field]
+			if err := r.SetParams(prefix+".deny", RuleParams{key: rules.Deny}); err != nil {
+				return nil, err
+			}
+			if err := r.Enable(prefix + ".deny"); err != nil {
+				return nil, err
+			}
+		}
+		if len(rules.Ladas) > 0 {
+			if err := r.SetParams("celular.lada", RuleParams{"ladas": rules.Ladas}); err != nil {
+				return nil, err
+			}
+		}
+		if len(rules.States) > 0 {
+			if err := r.SetParams("celular.lada", RuleParams{"states": rules.States}); err != nil {
+				return nil, err
+			}
+		}
+		if len(rules.Countries) > 0 {
+			if err := r.SetParams("celular.country", RuleParams{"countries": rules.Countries}); err != nil {
+				return nil, err
+			}
+		}
+		for code, template := range rules.Messages {
+			r.SetMessage(code, template)
+		}
+	}
+
+	for name, override := range f.Rules {
+		err := r.Configure(name, func(config *RuleConfig) {
+			if override.Enabled != nil {
+				config.Enabled = *override.Enabled
+			}
+			if override.Order != nil {
+				config.Order = *override.Order
+			}
+			if override.Severity != "" {
+				config.Severity = override.Severity
+			}
+			if len(override.Params) > 0 {
+				merged := config.Params.Copy()
+				for k, v := range override.Params {
+					merged[k] = v
+				}
+				config.Params = merged
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for code, template := range f.Messages {
+		r.SetMessage(code, template)
+	}
+
+	return r, nil
+}
+
+// ApplyRulesFile carga el archivo y reemplaza las reglas de DefaultRegistry
+func ApplyRulesFile(path string) error {
+	file, err := LoadRulesFile(path)
+	if err != nil {
+		return err
+	}
+	registry, err := file.BuildRegistry()
+	if err != nil {
+		return err
+	}
+	DefaultRegistry.Replace(registry)
+	log.Printf("✅ Reglas de validación cargadas desde %s", path)
+	return nil
+}
+
+// WatchRulesFile revisa periódicamente el archivo y lo recarga cuando cambia.
+// Si la nueva versión es inválida se registra el error y se conservan las reglas anteriores.
+func WatchRulesFile(path string, interval time.Duration) {
+	lastMod, lastSize := statRulesFile(path)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			mod, size := statRulesFile(path)
+			if mod.Equal(lastMod) && size == lastSize {
+				continue
+			}
+			lastMod, lastSize = mod, size
+
+			if err := ApplyRulesFile(path); err != nil {
+				log.Printf("🔴 No se recargaron las reglas de validación: %v", err)
+				continue
+			}
+			log.Printf("🔄 Reglas de validación recargadas desde %s", path)
+		}
+	}()
+}
+
+func statRulesFile(path string) (time.Time, int64) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, -1
+	}
+	return info.ModTime(), info.Size()
+}
@@ -6,264 +6,288 @@ import (
 	"unicode"
 	"unicode/utf8"
 	"fmt"
+	"sync"
 	"api_compiladores/src/models"
+	"api_compiladores/src/personname"
+	"api_compiladores/src/phone"
+	"api_compiladores/src/search"
 )
 
 var (
 	// Expresión regular mejorada para validar que el campo Clave_Cliente sea un número entero positivo
 	identRegexNumeric = regexp.MustCompile(`^[1-9][0-9]*$`)
 	
-	// Expresión regular mejorada para validar nombres (letras, acentos, ñ, espacios únicos)
-	identRegexNombre = regexp.MustCompile(`^[a-zA-ZáéíóúÁÉÍÓÚñÑüÜ]+(\s[a-zA-ZáéíóúÁÉÍÓÚñÑüÜ]+)*$`)
-	
-	// Expresión regular para validar números de celular de Chiapas
-	identRegexCelular = regexp.MustCompile(`^(91[6-9]|93[24]|96[1-8]|99[24])\d{7}$`)
-	
-	// Expresión regular mejorada para validar emails con más dominios
-	identRegexEmail = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9._-]*[a-zA-Z0-9])?@(gmail\.com|hotmail\.com|yahoo\.com|outlook\.com|live\.com|icloud\.com|protonmail\.com|aol\.com|msn\.com|gmx\.com|ymail\.com|me\.com|mail\.com|zoho\.com|edu\.mx|edu\.com|edu\.org|institucional\.edu\.mx|unach\.mx|unicach\.mx)$`)
-	
-	// Patrones peligrosos que podrían indicar ataques
-	sqlInjectionPattern = regexp.MustCompile(`(?i)(union|select|insert|update|delete|drop|create|alter|exec|script|javascript|<script|onload|onerror|alert\(|confirm\(|prompt\()`)
-	
-	// Caracteres especiales no permitidos en nombres
-	specialCharsPattern = regexp.MustCompile(`[!@#$%^&*()_+=\[\]{};':"\\|,.<>/?~` + "`" + `0-9]`)
-	
 	// Números de teléfono conocidos como inválidos o de prueba
 	invalidPhonePatterns = regexp.MustCompile(`^(0000000000|1111111111|2222222222|3333333333|4444444444|5555555555|6666666666|7777777777|8888888888|9999999999|1234567890|0987654321)$`)
-	
-	// Emails temporales o desechables comunes
-	disposableEmailDomains = regexp.MustCompile(`@(10minutemail|guerrillamail|mailinator|tempmail|throwaway|yopmail|maildrop|trashmail)\.`)
 )
 
-func ValidateCliente(cliente *models.Cliente) {
-	errores := make(map[string][]string)
+// Códigos de error estables que identifican cada regla de validación
+const (
+	CodeNombreRequired     = "NOMBRE_REQUIRED"
+	CodeNombreTooShort     = "NOMBRE_TOO_SHORT"
+	CodeNombreTooLong      = "NOMBRE_TOO_LONG"
+	CodeNombreInvalidChars = "NOMBRE_INVALID_CHARS"
+	CodeNombreSpecialChars = "NOMBRE_SPECIAL_CHARS"
+	CodeNombreMultiSpaces  = "NOMBRE_MULTIPLE_SPACES"
+	CodeNombreInjection    = "NOMBRE_INJECTION"
+	CodeNombreNoLetters    = "NOMBRE_NO_LETTERS"
+	CodeNombreFewLetters   = "NOMBRE_TOO_FEW_LETTERS"
+	CodeNombrePattern      = "NOMBRE_PATTERN_MISMATCH"
+	CodeNombreDeniedWord   = "NOMBRE_DENIED_WORD"
+	CodeNombreBadConnector = "NOMBRE_BAD_CONNECTOR"
 
-	// Validaciones mejoradas para Nombre
-	nombre := strings.TrimSpace(cliente.Nombre)
-	if nombre == "" {
-		errores["Nombre"] = append(errores["Nombre"], "El campo Nombre es obligatorio")
-	} else {
-		// Verificar longitud de caracteres UTF-8
-		if utf8.RuneCountInString(nombre) < 2 {
-			errores["Nombre"] = append(errores["Nombre"], "El Nombre debe tener al menos 2 caracteres")
-		}
-		if utf8.RuneCountInString(nombre) > 100 {
-			errores["Nombre"] = append(errores["Nombre"], "El Nombre no puede exceder 100 caracteres")
-		}
-		
-		// Verificar caracteres válidos
-		if !identRegexNombre.MatchString(nombre) {
-			errores["Nombre"] = append(errores["Nombre"], "El Nombre solo puede contener letras, acentos y un espacio entre palabras")
-		}
-		
-		// Verificar caracteres especiales y números
-		if specialCharsPattern.MatchString(nombre) {
-			errores["Nombre"] = append(errores["Nombre"], "El Nombre no puede contener números ni caracteres especiales")
-		}
-		
-		// Verificar espacios múltiples
-		if strings.Contains(nombre, "  ") {
-			errores["Nombre"] = append(errores["Nombre"], "No se permiten espacios múltiples consecutivos")
-		}
-		
-		// Verificar que no empiece o termine con espacio
-		if nombre != strings.TrimSpace(nombre) {
-			errores["Nombre"] = append(errores["Nombre"], "El Nombre no puede empezar o terminar con espacios")
-		}
-		
-		// Verificar patrones de inyección
-		if sqlInjectionPattern.MatchString(nombre) {
-			errores["Nombre"] = append(errores["Nombre"], "El Nombre contiene caracteres o patrones no permitidos")
-		}
-		
-		// Verificar que tenga al menos una letra
-		hasLetter := false
-		for _, r := range nombre {
-			if unicode.IsLetter(r) {
-				hasLetter = true
-				break
-			}
-		}
-		if !hasLetter {
-			errores["Nombre"] = append(errores["Nombre"], "El Nombre debe contener al menos una letra")
-		}
-		
-		// Verificar nombres muy cortos tras quitar espacios
-		nombreSinEspacios := strings.ReplaceAll(nombre, " ", "")
-		if len(nombreSinEspacios) < 2 {
-			errores["Nombre"] = append(errores["Nombre"], "El Nombre debe tener al menos 2 letras (sin contar espacios)")
-		}
+	CodeCelularRequired       = "CELULAR_REQUIRED"
+	CodeCelularTooShort       = "CELULAR_TOO_SHORT"
+	CodeCelularTooLong        = "CELULAR_TOO_LONG"
+	CodeCelularNotDigits      = "CELULAR_NOT_DIGITS"
+	CodeCelularBadLada        = "CELULAR_BAD_LADA"
+	CodeCelularRepeated       = "CELULAR_REPEATED_PATTERN"
+	CodeCelularLeadZero       = "CELULAR_LEADING_ZERO"
+	CodeCelularLadaFormat     = "CELULAR_BAD_LADA_FORMAT"
+	CodeCelularPattern        = "CELULAR_PATTERN_MISMATCH"
+	CodeCelularDenied         = "CELULAR_DENIED"
+	CodeCelularStateDenied    = "CELULAR_STATE_NOT_ALLOWED"
+	CodeCelularUnknownCountry = "CELULAR_UNKNOWN_COUNTRY"
+	CodeCelularCountryDenied  = "CELULAR_COUNTRY_NOT_ALLOWED"
+	CodeCelularBadPrefix      = "CELULAR_BAD_PREFIX"
+
+	CodeEmailRequired          = "EMAIL_REQUIRED"
+	CodeEmailTooShort          = "EMAIL_TOO_SHORT"
+	CodeEmailTooLong           = "EMAIL_TOO_LONG"
+	CodeEmailAtCount           = "EMAIL_AT_COUNT"
+	CodeEmailLocalEmpty        = "EMAIL_LOCAL_EMPTY"
+	CodeEmailLocalTooLong      = "EMAIL_LOCAL_TOO_LONG"
+	CodeEmailLocalDotEdge      = "EMAIL_LOCAL_DOT_EDGE"
+	CodeEmailConsecutiveDots   = "EMAIL_CONSECUTIVE_DOTS"
+	CodeEmailInvalidChars      = "EMAIL_INVALID_CHARS"
+	CodeEmailDomainEmpty       = "EMAIL_DOMAIN_EMPTY"
+	CodeEmailDomainTooLong     = "EMAIL_DOMAIN_TOO_LONG"
+	CodeEmailDomainNotAllowed  = "EMAIL_DOMAIN_NOT_ALLOWED"
+	CodeEmailDisposable        = "EMAIL_DISPOSABLE"
+	CodeEmailInjection         = "EMAIL_INJECTION"
+	CodeEmailBadStart          = "EMAIL_BAD_START"
+	CodeEmailGmailTrailingDot  = "EMAIL_GMAIL_TRAILING_DOT"
+	CodeEmailInstitutionShort  = "EMAIL_INSTITUTIONAL_TOO_SHORT"
+	CodeEmailNameMismatch      = "EMAIL_NAME_MISMATCH"
+	CodeEmailPattern           = "EMAIL_PATTERN_MISMATCH"
+	CodeEmailDomainDenied      = "EMAIL_DOMAIN_DENIED"
+	CodeEmailUnterminatedQuote = "EMAIL_UNTERMINATED_QUOTE"
+	CodeEmailDomainDotEdge     = "EMAIL_DOMAIN_DOT_EDGE"
+	CodeEmailLabelTooLong      = "EMAIL_LABEL_TOO_LONG"
+	CodeEmailLabelHyphen       = "EMAIL_LABEL_HYPHEN"
+	CodeEmailInvalidIDN        = "EMAIL_INVALID_IDN"
+	CodeEmailDomainLiteral     = "EMAIL_DOMAIN_LITERAL"
+	CodeEmailUnknownSuffix     = "EMAIL_UNKNOWN_SUFFIX"
+	CodeEmailNotRegistrable    = "EMAIL_DOMAIN_NOT_REGISTRABLE"
+)
+
+// Mensajes en español de cada código; los marcadores {nombre} se sustituyen por los parámetros
+var errorMessages = map[string]string{
+	CodeNombreRequired:     "El campo Nombre es obligatorio",
+	CodeNombreTooShort:     "El Nombre debe tener al menos {min} caracteres",
+	CodeNombreTooLong:      "El Nombre no puede exceder {max} caracteres",
+	CodeNombreInvalidChars: "El Nombre solo puede contener letras, acentos y un espacio entre palabras",
+	CodeNombreSpecialChars: "El Nombre no puede contener números ni caracteres especiales",
+	CodeNombreMultiSpaces:  "No se permiten espacios múltiples consecutivos",
+	CodeNombreInjection:    "El Nombre contiene caracteres o patrones no permitidos",
+	CodeNombreNoLetters:    "El Nombre debe contener al menos una letra",
+	CodeNombreFewLetters:   "El Nombre debe tener al menos {min} letras (sin contar espacios)",
+	CodeNombrePattern:      "El Nombre no tiene el formato requerido",
+	CodeNombreDeniedWord:   "El Nombre contiene la palabra no permitida {word}",
+	CodeNombreBadConnector: "Los guiones y apóstrofos del Nombre deben ir entre dos letras",
+
+	CodeCelularRequired:       "El campo Celular es obligatorio",
+	CodeCelularTooShort:       "El número de celular debe tener exactamente {length} dígitos (faltan dígitos)",
+	CodeCelularTooLong:        "El número de celular debe tener exactamente {length} dígitos (demasiados dígitos)",
+	CodeCelularNotDigits:      "El número de celular solo puede contener dígitos",
+	CodeCelularBadLada:        "El número debe corresponder a una lada válida de México",
+	CodeCelularRepeated:       "El número de celular no puede ser un patrón repetitivo o secuencial",
+	CodeCelularLeadZero:       "El número de celular no puede empezar con 0",
+	CodeCelularLadaFormat:     "Formato inválido para la lada {lada} de {zona}",
+	CodeCelularPattern:        "El número de celular no tiene el formato requerido",
+	CodeCelularDenied:         "El número de celular no está permitido",
+	CodeCelularStateDenied:    "La lada {lada} corresponde a {estado}, que no está permitido",
+	CodeCelularUnknownCountry: "La clave de país del número no está soportada (MX, US, CA, GT, ES)",
+	CodeCelularCountryDenied:  "No se aceptan números de {pais}",
+	CodeCelularBadPrefix:      "El número no tiene un prefijo válido para {pais}",
+
+	CodeEmailRequired:          "El campo Email es obligatorio",
+	CodeEmailTooShort:          "El Email debe tener al menos {min} caracteres",
+	CodeEmailTooLong:           "El Email no puede exceder {max} caracteres (límite RFC)",
+	CodeEmailAtCount:           "El Email debe tener exactamente un símbolo @",
+	CodeEmailLocalEmpty:        "La parte antes del @ no puede estar vacía",
+	CodeEmailLocalTooLong:      "La parte antes del @ no puede exceder {max} caracteres",
+	CodeEmailLocalDotEdge:      "El Email no puede empezar o terminar con punto antes del @",
+	CodeEmailConsecutiveDots:   "El Email no puede tener puntos consecutivos",
+	CodeEmailInvalidChars:      "El Email contiene un carácter no permitido en esta posición",
+	CodeEmailDomainEmpty:       "La parte después del @ no puede estar vacía",
+	CodeEmailDomainTooLong:     "El dominio no puede exceder {max} caracteres",
+	CodeEmailDomainNotAllowed:  "El Email debe usar un dominio permitido",
+	CodeEmailDisposable:        "No se permiten emails temporales o desechables",
+	CodeEmailInjection:         "El Email contiene caracteres o patrones no permitidos",
+	CodeEmailBadStart:          "El Email no puede empezar con punto, guión o guión bajo",
+	CodeEmailGmailTrailingDot:  "Gmail no permite emails que terminen con punto antes del @",
+	CodeEmailInstitutionShort:  "Los emails institucionales deben tener al menos {min} caracteres antes del @",
+	CodeEmailNameMismatch:      "Recomendación: El email no parece corresponder al nombre proporcionado",
+	CodeEmailPattern:           "El Email no tiene el formato requerido",
+	CodeEmailDomainDenied:      "El dominio {domain} no está permitido",
+	CodeEmailUnterminatedQuote: "Falta cerrar las comillas de la parte antes del @",
+	CodeEmailDomainDotEdge:     "El dominio no puede empezar o terminar con punto",
+	CodeEmailLabelTooLong:      "Cada parte del dominio puede tener como máximo {max} caracteres",
+	CodeEmailLabelHyphen:       "Las partes del dominio no pueden empezar o terminar con guión",
+	CodeEmailInvalidIDN:        "El dominio internacionalizado no es válido",
+	CodeEmailDomainLiteral:     "La dirección IP entre corchetes no es válida",
+	CodeEmailUnknownSuffix:     "El dominio {domain} no termina en un sufijo público conocido",
+	CodeEmailNotRegistrable:    "{domain} es un sufijo público, falta el nombre del dominio",
+}
+
+// Mensajes con los que se guardaron registros antiguos y que ya no coinciden
+// con la plantilla actual; solo se usan para migrar
+var legacyMessages = map[string]string{
+	"Formato inválido para la lada {lada}":                                                                                 CodeCelularLadaFormat,
+	"El Nombre no puede empezar o terminar con espacios":                                                                   CodeNombreInvalidChars,
+	"El número debe corresponder a una lada válida de Chiapas (916-919, 932, 934, 961-968, 992, 994)":                      CodeCelularBadLada,
+	"El Email solo puede contener letras, números, punto, guión y guión bajo antes del @":                                  CodeEmailInvalidChars,
+	"El Email debe usar un dominio permitido (gmail.com, hotmail.com, yahoo.com, outlook.com, institucional.edu.mx, etc.)": CodeEmailDomainNotAllowed,
+}
+
+func buildParams(kv []string) map[string]string {
+	if len(kv) == 0 {
+		return nil
 	}
+	params := make(map[string]string, len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		params[kv[i]] = kv[i+1]
+	}
+	return params
+}
 
-	// Validaciones mejoradas para Celular
-	celular := strings.TrimSpace(cliente.Celular)
-	if celular == "" {
-		errores["Celular"] = append(errores["Celular"], "El campo Celular es obligatorio")
-	} else {
-		// Verificar longitud exacta
-		if len(celular) != 10 {
-			if len(celular) < 10 {
-				errores["Celular"] = append(errores["Celular"], "El número de celular debe tener exactamente 10 dígitos (faltan dígitos)")
-			} else {
-				errores["Celular"] = append(errores["Celular"], "El número de celular debe tener exactamente 10 dígitos (demasiados dígitos)")
-			}
-		}
-		
-		// Verificar que solo contenga números
-		for _, char := range celular {
-			if !unicode.IsDigit(char) {
-				errores["Celular"] = append(errores["Celular"], "El número de celular solo puede contener dígitos")
-				break
-			}
-		}
-		
-		// Verificar formato específico de Chiapas
-		if !identRegexCelular.MatchString(celular) {
-			errores["Celular"] = append(errores["Celular"], "El número debe corresponder a una lada válida de Chiapas (916-919, 932, 934, 961-968, 992, 994)")
+// renderMessage sustituye los parámetros en la plantilla del código; las
+// plantillas de overrides (p. ej. del archivo de reglas) tienen prioridad
+func renderMessage(code string, params map[string]string, overrides map[string]string) string {
+	msg, ok := overrides[code]
+	if !ok {
+		msg, ok = errorMessages[code]
+	}
+	if !ok {
+		return code
+	}
+	for k, v := range params {
+		msg = strings.ReplaceAll(msg, "{"+k+"}", v)
+	}
+	return msg
+}
+
+// Marcadores {nombre} dentro de una plantilla ya escapada con QuoteMeta
+var placeholderPattern = regexp.MustCompile(`\\\{(\w+)\\\}`)
+
+var (
+	messagePatternsOnce sync.Once
+	messagePatterns     []messagePattern
+)
+
+type messagePattern struct {
+	code    string
+	pattern *regexp.Regexp
+}
+
+func compileMessagePattern(code, template string) messagePattern {
+	expr := placeholderPattern.ReplaceAllString(regexp.QuoteMeta(template), `(?P<$1>.+?)`)
+	return messagePattern{code: code, pattern: regexp.MustCompile("^" + expr + "$")}
+}
+
+// CodeFromMessage recupera el código y los parámetros de un mensaje en texto.
+// Se usa para migrar registros guardados antes de los códigos estructurados.
+func CodeFromMessage(message string) (string, map[string]string, bool) {
+	messagePatternsOnce.Do(func() {
+		for code, template := range errorMessages {
+			messagePatterns = append(messagePatterns, compileMessagePattern(code, template))
 		}
-		
-		// Verificar patrones de números inválidos
-		if invalidPhonePatterns.MatchString(celular) {
-			errores["Celular"] = append(errores["Celular"], "El número de celular no puede ser un patrón repetitivo o secuencial")
+		for template, code := range legacyMessages {
+			messagePatterns = append(messagePatterns, compileMessagePattern(code, template))
 		}
-		
-		// Verificar que no empiece con 0
-		if strings.HasPrefix(celular, "0") {
-			errores["Celular"] = append(errores["Celular"], "El número de celular no puede empezar con 0")
+	})
+
+	for _, mp := range messagePatterns {
+		match := mp.pattern.FindStringSubmatch(message)
+		if match == nil {
+			continue
 		}
-		
-		// Verificar límites específicos por lada
-		if len(celular) == 10 {
-			lada := celular[:3]
-			switch lada {
-			case "916", "917", "918", "919":
-				// Tuxtla Gutiérrez y zona metropolitana
-				if celular[3] == '0' || celular[3] == '1' {
-					errores["Celular"] = append(errores["Celular"], "Formato inválido para la lada "+lada+" de Tuxtla Gutiérrez")
-				}
-			case "932", "934":
-				// Tapachula y Comitán
-				if celular[3] == '0' {
-					errores["Celular"] = append(errores["Celular"], "Formato inválido para la lada "+lada)
-				}
+		var params map[string]string
+		for i, name := range mp.pattern.SubexpNames() {
+			if name == "" {
+				continue
 			}
+			if params == nil {
+				params = make(map[string]string)
+			}
+			params[name] = match[i]
 		}
+		return mp.code, params, true
 	}
+	return "", nil, false
+}
+
+// leadingTrim devuelve el valor sin espacios externos y cuántas runas se quitaron al inicio
+func leadingTrim(s string) (string, int) {
+	trimmedLeft := strings.TrimLeftFunc(s, unicode.IsSpace)
+	base := utf8.RuneCountInString(s) - utf8.RuneCountInString(trimmedLeft)
+	return strings.TrimRightFunc(trimmedLeft, unicode.IsSpace), base
+}
+
+// runeSpan convierte un rango en bytes devuelto por regexp a un rango en runas
+func runeSpan(s string, loc []int) (int, int) {
+	offset := utf8.RuneCountInString(s[:loc[0]])
+	return offset, utf8.RuneCountInString(s[loc[0]:loc[1]])
+}
+
+// ValidateCliente ejecuta las reglas habilitadas de DefaultRegistry y asigna
+// los errores al cliente junto con su vista en texto
+func ValidateCliente(cliente *models.Cliente) {
+	errores := DefaultRegistry.Run(cliente)
+	asignarNombre(cliente, errores)
+	asignarTelefono(cliente)
+	cliente.EmailBusqueda = search.Fold(cliente.Email)
 
-	// Validaciones mejoradas para Email
-	email := strings.TrimSpace(strings.ToLower(cliente.Email))
-	if email == "" {
-		errores["Email"] = append(errores["Email"], "El campo Email es obligatorio")
+	if len(errores) > 0 {
+		cliente.Errores = errores
+		cliente.Mensajes = errores.Messages()
 	} else {
-		// Verificar longitud
-		if len(email) < 5 {
-			errores["Email"] = append(errores["Email"], "El Email debe tener al menos 5 caracteres")
-		}
-		if len(email) > 254 {
-			errores["Email"] = append(errores["Email"], "El Email no puede exceder 254 caracteres (límite RFC)")
-		}
-		
-		// Verificar estructura básica
-		parts := strings.Split(email, "@")
-		if len(parts) != 2 {
-			errores["Email"] = append(errores["Email"], "El Email debe tener exactamente un símbolo @")
-		} else {
-			localPart := parts[0]
-			domainPart := parts[1]
-			
-			// Validar parte local (antes del @)
-			if len(localPart) == 0 {
-				errores["Email"] = append(errores["Email"], "La parte antes del @ no puede estar vacía")
-			} else if len(localPart) > 64 {
-				errores["Email"] = append(errores["Email"], "La parte antes del @ no puede exceder 64 caracteres")
-			}
-			
-			// Verificar que no empiece o termine con punto
-			if strings.HasPrefix(localPart, ".") || strings.HasSuffix(localPart, ".") {
-				errores["Email"] = append(errores["Email"], "El Email no puede empezar o terminar con punto antes del @")
-			}
-			
-			// Verificar puntos consecutivos
-			if strings.Contains(localPart, "..") {
-				errores["Email"] = append(errores["Email"], "El Email no puede tener puntos consecutivos")
-			}
-			
-			// Validar dominio
-			if len(domainPart) == 0 {
-				errores["Email"] = append(errores["Email"], "La parte después del @ no puede estar vacía")
-			} else if len(domainPart) > 253 {
-				errores["Email"] = append(errores["Email"], "El dominio no puede exceder 253 caracteres")
-			}
-		}
-		
-		// Verificar formato completo con regex
-		if !identRegexEmail.MatchString(email) {
-			errores["Email"] = append(errores["Email"], "El Email debe usar un dominio permitido (gmail.com, hotmail.com, yahoo.com, outlook.com, institucional.edu.mx, etc.)")
-		}
-		
-		// Verificar emails desechables
-		if disposableEmailDomains.MatchString(email) {
-			errores["Email"] = append(errores["Email"], "No se permiten emails temporales o desechables")
-		}
-		
-		// Verificar patrones de inyección
-		if sqlInjectionPattern.MatchString(email) {
-			errores["Email"] = append(errores["Email"], "El Email contiene caracteres o patrones no permitidos")
-		}
-		
-		// Verificar caracteres especiales no permitidos al inicio
-		if len(email) > 0 && (email[0] == '.' || email[0] == '-' || email[0] == '_') {
-			errores["Email"] = append(errores["Email"], "El Email no puede empezar con punto, guión o guión bajo")
-		}
-		
-		// Validaciones específicas por dominio
-		if strings.Contains(email, "@") {
-			domain := strings.Split(email, "@")[1]
-			switch domain {
-			case "gmail.com":
-				// Gmail no permite puntos al final de la parte local
-				localPart := strings.Split(email, "@")[0]
-				if strings.HasSuffix(localPart, ".") {
-					errores["Email"] = append(errores["Email"], "Gmail no permite emails que terminen con punto antes del @")
-				}
-			case "edu.mx", "institucional.edu.mx", "unach.mx", "unicach.mx":
-				// Emails institucionales deben tener formato específico
-				localPart := strings.Split(email, "@")[0]
-				if len(localPart) < 3 {
-					errores["Email"] = append(errores["Email"], "Los emails institucionales deben tener al menos 3 caracteres antes del @")
-				}
-			}
-		}
+		cliente.Errores = nil
+		cliente.Mensajes = nil
 	}
+}
 
-	// Validaciones cruzadas
-	if len(errores) == 0 {
-		// Verificar consistencia entre nombre y email si ambos son válidos
-		if nombre != "" && email != "" {
-			nombreSinEspacios := strings.ToLower(strings.ReplaceAll(nombre, " ", ""))
-			emailLocal := strings.ToLower(strings.Split(email, "@")[0])
-			
-			// Si el email parece ser muy diferente al nombre, dar una advertencia suave
-			if len(nombreSinEspacios) > 3 && len(emailLocal) > 3 {
-				similarity := calculateSimilarity(nombreSinEspacios, emailLocal)
-				if similarity < 0.3 && !strings.Contains(emailLocal, nombreSinEspacios[:3]) {
-					// Esta es más una advertencia que un error crítico
-					// errores["Email"] = append(errores["Email"], "Recomendación: El email no parece corresponder al nombre proporcionado")
-				}
-			}
+// ValidateClienteFields revalida solo los campos modificados (y los que
+// dependen de ellos) conservando los Errores vigentes de los demás, y
+// recalcula los datos derivados de esos campos. Devuelve los campos
+// revalidados.
+func ValidateClienteFields(cliente *models.Cliente, fields ...string) []string {
+	errores, revalidated := DefaultRegistry.RunFields(cliente, cliente.Errores, fields...)
+	for _, field := range revalidated {
+		switch field {
+		case "Nombre":
+			asignarNombre(cliente, errores)
+		case "Celular":
+			asignarTelefono(cliente)
+		case "Email":
+			cliente.EmailBusqueda = search.Fold(cliente.Email)
 		}
 	}
 
-	// Asignar errores en el cliente
 	if len(errores) > 0 {
 		cliente.Errores = errores
+		cliente.Mensajes = errores.Messages()
 	} else {
 		cliente.Errores = nil
+		cliente.Mensajes = nil
 	}
+	return revalidated
 }
 
-// Función auxiliar para calcular similitud básica entre strings
-func calculateSimilarity(s1, s2 string) float64 {
+// CalculateSimilarity calcula una similitud básica entre strings: la fracción
+// de posiciones iguales en el tramo común
+func CalculateSimilarity(s1, s2 string) float64 {
 	if len(s1) == 0 || len(s2) == 0 {
 		return 0.0
 	}
@@ -283,9 +307,51 @@ func calculateSimilarity(s1, s2 string) float64 {
 	return float64(matches) / float64(minLen)
 }
 
+// asignarNombre guarda el nombre normalizado y sus partes; se limpian si el
+// Nombre tiene errores. La forma de búsqueda se guarda siempre, para que los
+// clientes inválidos también se encuentren.
+func asignarNombre(cliente *models.Cliente, errores models.Errores) {
+	cliente.NombreBusqueda = search.Fold(cliente.Nombre)
+	cliente.NombreNormalizado, cliente.NombrePila = "", ""
+	cliente.ApellidoPaterno, cliente.ApellidoMaterno = "", ""
+
+	for _, ve := range errores["Nombre"] {
+		if ve.Severity != models.SeverityWarning {
+			return
+		}
+	}
+	normalized, _ := personname.Normalize(strings.TrimSpace(cliente.Nombre))
+	name := personname.Parse(normalized)
+	cliente.NombreNormalizado = name.Normalized
+	cliente.NombrePila = strings.Join(name.Given, " ")
+	cliente.ApellidoPaterno = name.Paterno
+	cliente.ApellidoMaterno = name.Materno
+}
+
+// asignarTelefono guarda las formas normalizadas del celular y, en México, la
+// región de su lada; se limpian si el número no es válido para su país
+func asignarTelefono(cliente *models.Cliente) {
+	cliente.CelularNormalizado, cliente.CelularE164 = "", ""
+	cliente.CelularNacional, cliente.CelularPais = "", ""
+	cliente.Region = nil
+
+	number := phone.Parse(strings.TrimSpace(cliente.Celular), phone.DefaultRegion())
+	if !number.Valid() {
+		return
+	}
+	cliente.CelularNormalizado = number.National
+	cliente.CelularE164 = number.E164()
+	cliente.CelularNacional = number.Format()
+	cliente.CelularPais = number.Country.Region
+
+	if lada, ok := phone.Lookup(number.National); ok && number.Country.Region == "MX" {
+		cliente.Region = &models.Region{Lada: lada.Codigo, Estado: lada.Estado, Municipio: lada.Municipio}
+	}
+}
+
 // Función adicional para validar múltiples clientes
-func ValidateClientes(clientes []*models.Cliente) map[int]map[string][]string {
-	todosErrores := make(map[int]map[string][]string)
+func ValidateClientes(clientes []*models.Cliente) map[int]models.Errores {
+	todosErrores := make(map[int]models.Errores)
 	
 	for i, cliente := range clientes {
 		ValidateCliente(cliente)